@@ -7,12 +7,7 @@ import (
 	"time"
 
 	httpadapter "github.com/PabloGalante/farum-agent/internal/adapters/http"
-	llmadapter "github.com/PabloGalante/farum-agent/internal/adapters/llm"
-	firestorestore "github.com/PabloGalante/farum-agent/internal/adapters/storage/firestore"
-	memstore "github.com/PabloGalante/farum-agent/internal/adapters/storage/memory"
-	"github.com/PabloGalante/farum-agent/internal/app/conversation"
-	journalapp "github.com/PabloGalante/farum-agent/internal/app/journal"
-	"github.com/PabloGalante/farum-agent/internal/app/tools"
+	"github.com/PabloGalante/farum-agent/internal/bootstrap"
 	"github.com/PabloGalante/farum-agent/internal/config"
 	"github.com/PabloGalante/farum-agent/internal/domain"
 	"github.com/PabloGalante/farum-agent/internal/observability"
@@ -33,78 +28,37 @@ func main() {
 	)
 
 	// 2) Create LLMClient according to config
-	var (
-		llmClient domain.LLMClient
-		err       error
-	)
-
-	if cfg.UseMockLLM {
-		logger.Info("[LLM] Using MOCK LLM client")
-		llmClient = llmadapter.NewMockLLM()
-	} else {
-		logger.Info("[LLM] Using Vertex LLM client",
-			"project", cfg.GCPProjectID,
-			"location", cfg.GCPLocation,
-			"model", cfg.ModelName,
-		)
-
-		llmClient, err = llmadapter.NewVertexClient(ctx, llmadapter.VertexConfig{
-			ProjectID: cfg.GCPProjectID,
-			Location:  cfg.GCPLocation,
-			ModelName: cfg.ModelName,
-		})
-		if err != nil {
-			logger.Error("error initializing Vertex LLM client", "error", err)
-			log.Fatal(err)
-		}
+	llmClient, err := bootstrap.NewLLMClient(ctx, cfg)
+	if err != nil {
+		logger.Error("error initializing LLM client", "error", err)
+		log.Fatal(err)
 	}
 
 	// 3) Storage: Firestore or Memory according to config.StorageBackend
-	var sessionStore domain.SessionStore
-	var messageStore domain.MessageStore
-	var journalStore domain.JournalStore
-
-	switch cfg.StorageBackend {
-	case "firestore":
-		if cfg.GCPProjectID == "" {
-			logger.Error("FARUM_GCP_PROJECT is required for Firestore storage backend", "backend", "firestore")
-			log.Fatal("FARUM_GCP_PROJECT is required for Firestore storage backend")
-		}
-
-		logger.Info("[STORE] Using Firestore storage", "project", cfg.GCPProjectID)
-		fsStore, err := firestorestore.NewStore(ctx, cfg.GCPProjectID)
-		if err != nil {
-			logger.Error("error initializing Firestore store", "error", err)
-			log.Fatal(err)
-		}
-
-		// 1 store, implements 2 interfaces
-		sessionStore = fsStore
-		messageStore = fsStore
-		journalStore = nil // TODO: implement FirestoreJournalStore
-
-	default:
-		logger.Info("[STORE] Using in-memory storage", "backend", "memory")
-		sessionStore = memstore.NewSessionStore()
-		messageStore = memstore.NewMessageStore()
-		journalStore = memstore.NewJournalStore()
+	stores, err := bootstrap.NewStores(ctx, cfg)
+	if err != nil {
+		logger.Error("error initializing storage", "backend", cfg.StorageBackend, "error", err)
+		log.Fatal(err)
 	}
 
-	// 3.1) JournalTool from JournalStore (only if it exists)
-	var journalTool *tools.JournalTool
-	if journalStore != nil {
-		journalTool = tools.NewJournalTool(journalStore)
-		logger.Info("[JOURNAL] JournalTool enabled (in-memory)")
-	} else {
-		logger.Info("[JOURNAL] JournalTool disabled (no JournalStore configured)")
+	// 4) Application services: agents, tools and memories
+	services, err := bootstrap.NewServices(ctx, cfg, llmClient, stores)
+	if err != nil {
+		logger.Error("error initializing services", "error", err)
+		log.Fatal(err)
 	}
 
-	// 4) Application services
-	convSvc := conversation.NewService(llmClient, sessionStore, messageStore, journalTool)
-	journalSvc := journalapp.NewService(journalStore)
-
-	// 5) HTTP server
-	handler := httpadapter.NewServer(convSvc, journalSvc)
+	// 5) HTTP server, authenticated when an auth method is configured
+	var serverOpts []httpadapter.ServerOption
+	auth, err := newAuthenticator(ctx, cfg)
+	if err != nil {
+		logger.Error("error initializing authentication", "error", err)
+		log.Fatal(err)
+	}
+	if auth != nil {
+		serverOpts = append(serverOpts, httpadapter.WithAuth(auth))
+	}
+	handler := httpadapter.NewServer(services.Conversation, services.Journal, serverOpts...)
 
 	server := &http.Server{
 		Addr:         ":" + cfg.Port,
@@ -114,6 +68,21 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// 6) Internal endpoints (metrics) on their own listener, never on the public port
+	if cfg.AdminAddr != "" {
+		admin := &http.Server{
+			Addr:        cfg.AdminAddr,
+			Handler:     httpadapter.NewAdminServer(),
+			ReadTimeout: 15 * time.Second,
+		}
+		go func() {
+			logger.Info("Farum admin listening", "addr", cfg.AdminAddr)
+			if err := admin.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("admin server error", "error", err)
+			}
+		}()
+	}
+
 	logger.Info("Farum API listening", "port", cfg.Port)
 
 	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -121,3 +90,36 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// newAuthenticator creates the authenticator of the HTTP API, or returns nil
+// when no auth method is configured (the API is then open).
+func newAuthenticator(ctx context.Context, cfg *config.Config) (*httpadapter.Authenticator, error) {
+	authCfg := httpadapter.AuthConfig{
+		JWTSecret: cfg.AuthJWTSecret,
+		JWKSURL:   cfg.AuthJWKSURL,
+		JWKSFile:  cfg.AuthJWKSFile,
+		Issuer:    cfg.AuthIssuer,
+		Audience:  cfg.AuthAudience,
+		APIKeys:   map[string]domain.UserID{},
+	}
+	for key, user := range cfg.APIKeys {
+		authCfg.APIKeys[key] = domain.UserID(user)
+	}
+
+	logger := observability.Logger()
+	if !authCfg.Enabled() {
+		logger.Warn("[AUTH] HTTP API without authentication: the user_id of the requests is trusted")
+		return nil, nil
+	}
+
+	auth, err := httpadapter.NewAuthenticator(ctx, authCfg)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("[AUTH] HTTP API authentication enabled",
+		"hs256", authCfg.JWTSecret != "",
+		"rs256", authCfg.JWKSURL != "" || authCfg.JWKSFile != "",
+		"api_keys", len(authCfg.APIKeys),
+	)
+	return auth, nil
+}
@@ -0,0 +1,166 @@
+// farum-eval runs the prompt regression scenarios against the same stack as
+// farum-api (configured through the usual FARUM_* env vars) and compares the
+// result with a stored baseline report.
+//
+// Usage:
+//
+//	FARUM_USE_MOCK_LLM=true go run ./cmd/farum-eval \
+//	  -scenarios eval/scenarios -baseline eval/baseline.json -threshold 0.05
+//
+// Exit codes: 0 = ok, 1 = scores regressed beyond the threshold, 2 = setup error.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/app/eval"
+	"github.com/PabloGalante/farum-agent/internal/bootstrap"
+	"github.com/PabloGalante/farum-agent/internal/config"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+const (
+	exitOK         = 0
+	exitRegression = 1
+	exitSetup      = 2
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	var (
+		scenariosFlag  = flag.String("scenarios", "eval/scenarios", "comma-separated scenario files or directories")
+		concurrency    = flag.Int("concurrency", 4, "max scenarios running at the same time")
+		baselinePath   = flag.String("baseline", "", "baseline report (JSON) to compare against")
+		threshold      = flag.Float64("threshold", 0.05, "max allowed score drop vs. the baseline, per scenario and overall")
+		outPath        = flag.String("out", "", "write the JSON report to this file")
+		markdownPath   = flag.String("markdown", "-", "write the markdown report to this file (\"-\" = stdout, \"\" = none)")
+		useJudge       = flag.Bool("judge", false, "score turns with a rubric using the configured LLM as judge")
+		updateBaseline = flag.Bool("update-baseline", false, "write the report to -baseline instead of comparing")
+		verbose        = flag.Bool("v", false, "log at info level (default: warnings only)")
+	)
+	flag.Parse()
+
+	// stdout is reserved for the report: logs go to stderr
+	level := slog.LevelWarn
+	if *verbose {
+		level = slog.LevelInfo
+	}
+	observability.SetLogger(slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+
+	ctx := context.Background()
+	cfg := config.Load()
+
+	scenarios, err := eval.LoadScenarios(splitList(*scenariosFlag)...)
+	if err != nil {
+		return fail("loading scenarios: %v", err)
+	}
+	if len(scenarios) == 0 {
+		return fail("no scenarios found in %q", *scenariosFlag)
+	}
+
+	llmClient, err := bootstrap.NewLLMClient(ctx, cfg)
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	stores, err := bootstrap.NewStores(ctx, cfg)
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	services, err := bootstrap.NewServices(ctx, cfg, llmClient, stores)
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	opts := []eval.RunnerOption{eval.WithConcurrency(*concurrency)}
+	if *useJudge {
+		opts = append(opts, eval.WithJudge(eval.NewLLMJudge(llmClient)))
+	}
+
+	report := eval.NewRunner(services.Conversation, opts...).Run(ctx, scenarios)
+
+	if *outPath != "" {
+		if err := writeFile(*outPath, report.WriteJSON); err != nil {
+			return fail("writing report: %v", err)
+		}
+	}
+
+	switch *markdownPath {
+	case "":
+	case "-":
+		if err := report.WriteMarkdown(os.Stdout); err != nil {
+			return fail("writing markdown: %v", err)
+		}
+	default:
+		if err := writeFile(*markdownPath, report.WriteMarkdown); err != nil {
+			return fail("writing markdown: %v", err)
+		}
+	}
+
+	if *baselinePath == "" {
+		return exitOK
+	}
+
+	if *updateBaseline {
+		if err := writeFile(*baselinePath, report.WriteJSON); err != nil {
+			return fail("writing baseline: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "baseline updated: %s\n", *baselinePath)
+		return exitOK
+	}
+
+	baseline, err := eval.LoadReport(*baselinePath)
+	if err != nil {
+		return fail("%v", err)
+	}
+
+	regressions := eval.Compare(baseline, report, *threshold)
+	if len(regressions) == 0 {
+		fmt.Fprintf(os.Stderr, "no regressions (score %.2f, baseline %.2f, threshold %.2f)\n",
+			report.Score, baseline.Score, *threshold)
+		return exitOK
+	}
+
+	fmt.Fprintf(os.Stderr, "%d regression(s) beyond threshold %.2f:\n", len(regressions), *threshold)
+	for _, r := range regressions {
+		fmt.Fprintf(os.Stderr, "  - %s: %.2f → %.2f (%+.2f)\n", r.Scenario, r.Baseline, r.Current, r.Delta())
+	}
+	return exitRegression
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func fail(format string, args ...any) int {
+	fmt.Fprintf(os.Stderr, "farum-eval: "+format+"\n", args...)
+	return exitSetup
+}
@@ -4,6 +4,7 @@ go 1.25.4
 
 require (
 	cloud.google.com/go/firestore v1.20.0
+	golang.org/x/sync v0.16.0
 	google.golang.org/api v0.247.0
 	google.golang.org/genai v1.36.0
 	google.golang.org/grpc v1.74.2
@@ -32,7 +33,6 @@ require (
 	golang.org/x/crypto v0.41.0 // indirect
 	golang.org/x/net v0.43.0 // indirect
 	golang.org/x/oauth2 v0.30.0 // indirect
-	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/sys v0.35.0 // indirect
 	golang.org/x/text v0.28.0 // indirect
 	golang.org/x/time v0.12.0 // indirect
@@ -0,0 +1,100 @@
+// Package embedding implements domain.Embedder.
+package embedding
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// DefaultHashingDims is the vector size used when none is given.
+const DefaultHashingDims = 512
+
+// HashingEmbedder is a deterministic, local embedder based on the hashing
+// trick: words and word pairs are hashed into a fixed-size, L2-normalized
+// vector. It has no semantic knowledge (synonyms do not match), but it needs
+// no model or network, so it suits tests and small curated corpora.
+type HashingEmbedder struct {
+	dims int
+}
+
+func NewHashingEmbedder(dims int) *HashingEmbedder {
+	if dims <= 0 {
+		dims = DefaultHashingDims
+	}
+	return &HashingEmbedder{dims: dims}
+}
+
+// Embed implements domain.Embedder.
+func (e *HashingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		out = append(out, e.embed(text))
+	}
+	return out, nil
+}
+
+func (e *HashingEmbedder) embed(text string) []float32 {
+	vec := make([]float32, e.dims)
+
+	words := tokenize(text)
+	for i, w := range words {
+		e.add(vec, w, 1)
+		if i > 0 {
+			e.add(vec, words[i-1]+" "+w, 0.5)
+		}
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return vec
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range vec {
+		vec[i] *= scale
+	}
+	return vec
+}
+
+// add hashes feature into a bucket, with a sign bit so collisions tend to cancel out.
+func (e *HashingEmbedder) add(vec []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+
+	if sum&1 == 1 {
+		weight = -weight
+	}
+	vec[(sum>>1)%uint64(e.dims)] += weight
+}
+
+var accents = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u",
+	"ã", "a", "õ", "o", "â", "a", "ê", "e", "ô", "o", "ç", "c",
+)
+
+// tokenize lowercases, strips accents and keeps words of 3+ characters,
+// so "Respiración" and "respiracion" are the same feature.
+func tokenize(text string) []string {
+	text = accents.Replace(strings.ToLower(text))
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	out := fields[:0]
+	for _, f := range fields {
+		if len([]rune(f)) >= 3 {
+			out = append(out, f)
+		}
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+package embedding_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/embedding"
+)
+
+func dot(a, b []float32) float64 {
+	var s float64
+	for i := range a {
+		s += float64(a[i]) * float64(b[i])
+	}
+	return s
+}
+
+func TestHashingEmbedder(t *testing.T) {
+	e := embedding.NewHashingEmbedder(256)
+
+	vecs, err := e.Embed(context.Background(), []string{
+		"Ejercicio de respiración para la ansiedad",
+		"ejercicio de RESPIRACION para la ansiedad",
+		"Respiración cuadrada: un ejercicio para calmar la ansiedad",
+		"Cómo mejorar la higiene del sueño",
+	})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if len(vecs) != 4 || len(vecs[0]) != 256 {
+		t.Fatalf("unexpected shape: %d vectors of %d", len(vecs), len(vecs[0]))
+	}
+
+	// Case and accents do not matter; vectors are normalized
+	if s := dot(vecs[0], vecs[1]); s < 0.999 {
+		t.Fatalf("expected identical vectors for case/accent variants, got similarity %f", s)
+	}
+	if dot(vecs[0], vecs[2]) <= dot(vecs[0], vecs[3]) {
+		t.Fatalf("expected the related text to be closer than the unrelated one")
+	}
+
+	again, _ := e.Embed(context.Background(), []string{"Ejercicio de respiración para la ansiedad"})
+	for i := range again[0] {
+		if again[0][i] != vecs[0][i] {
+			t.Fatalf("expected deterministic embeddings")
+		}
+	}
+}
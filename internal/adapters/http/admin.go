@@ -0,0 +1,15 @@
+package httpadapter
+
+import (
+	"expvar"
+	"net/http"
+)
+
+// NewAdminServer serves the internal endpoints: expvar metrics (LLM and tool
+// calls, memstats, cmdline) at /debug/vars. They are not part of the API and
+// must only listen on an internal address.
+func NewAdminServer() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	return chainMiddlewares(mux, withLogging)
+}
@@ -0,0 +1,373 @@
+package httpadapter
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+const (
+	// clockSkew is tolerated on the exp and nbf claims.
+	clockSkew = time.Minute
+
+	// jwksRefresh is how often keys fetched from a URL are reloaded; an
+	// unknown key ID reloads them sooner, at most once per jwksMinRefresh.
+	jwksRefresh    = time.Hour
+	jwksMinRefresh = time.Minute
+)
+
+var (
+	errNoCredentials      = errors.New("missing credentials")
+	errInvalidCredentials = errors.New("invalid credentials")
+)
+
+// AuthConfig says how callers authenticate. Each method is enabled by its
+// settings; a request may use any of them.
+type AuthConfig struct {
+	// HS256 bearer tokens signed with this shared secret
+	JWTSecret string
+
+	// RS256 bearer tokens verified with a JWKS, read once from JWKSFile or
+	// fetched (and refreshed) from JWKSURL
+	JWKSFile string
+	JWKSURL  string
+
+	// Expected "iss" and "aud" claims of the tokens; empty skips the check
+	Issuer   string
+	Audience string
+
+	// APIKeys maps static API keys (X-API-Key header, or a bearer token
+	// that is not a JWT) to the user they act as
+	APIKeys map[string]domain.UserID
+}
+
+// Enabled reports whether any authentication method is configured.
+func (c AuthConfig) Enabled() bool {
+	return c.JWTSecret != "" || c.JWKSFile != "" || c.JWKSURL != "" || len(c.APIKeys) > 0
+}
+
+// Authenticator verifies the credentials of a request and returns the user
+// they belong to (the "sub" claim of a JWT, or the user of an API key).
+type Authenticator struct {
+	cfg  AuthConfig
+	keys *jwks // nil without RS256
+	now  func() time.Time
+}
+
+// NewAuthenticator creates an authenticator, loading the JWKS if any so a
+// bad configuration fails at startup.
+func NewAuthenticator(ctx context.Context, cfg AuthConfig) (*Authenticator, error) {
+	if !cfg.Enabled() {
+		return nil, errors.New("auth: no JWT secret, JWKS or API key configured")
+	}
+	if cfg.JWKSFile != "" && cfg.JWKSURL != "" {
+		return nil, errors.New("auth: set either a JWKS file or a JWKS URL, not both")
+	}
+
+	a := &Authenticator{cfg: cfg, now: time.Now}
+	switch {
+	case cfg.JWKSFile != "":
+		path := cfg.JWKSFile
+		a.keys = &jwks{load: func(context.Context) ([]byte, error) { return os.ReadFile(path) }}
+	case cfg.JWKSURL != "":
+		a.keys = &jwks{load: fetchJWKS(cfg.JWKSURL), refresh: jwksRefresh}
+	}
+	if a.keys != nil {
+		if err := a.keys.reload(ctx); err != nil {
+			return nil, fmt.Errorf("auth: %w", err)
+		}
+	}
+	return a, nil
+}
+
+// Authenticate returns the user of the credentials of r.
+func (a *Authenticator) Authenticate(r *http.Request) (domain.UserID, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return a.apiKeyUser(key)
+	}
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", errNoCredentials
+	}
+	token = strings.TrimSpace(token)
+
+	if strings.Count(token, ".") == 2 {
+		return a.verifyJWT(r.Context(), token)
+	}
+	return a.apiKeyUser(token)
+}
+
+func (a *Authenticator) apiKeyUser(key string) (domain.UserID, error) {
+	// Compare every key in constant time, so timing does not tell how close a guess was
+	var user domain.UserID
+	for k, u := range a.cfg.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			user = u
+		}
+	}
+	if user == "" {
+		return "", fmt.Errorf("%w: unknown API key", errInvalidCredentials)
+	}
+	return user, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+}
+
+// audience is the "aud" claim: a string or a list of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New("aud must be a string or a list of strings")
+	}
+	*a = many
+	return nil
+}
+
+// verifyJWT checks the signature and the claims of a compact JWT. Only
+// HS256 and RS256 are accepted (never "none").
+func (a *Authenticator) verifyJWT(ctx context.Context, token string) (domain.UserID, error) {
+	parts := strings.Split(token, ".")
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return "", fmt.Errorf("%w: header: %v", errInvalidCredentials, err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("%w: signature: %v", errInvalidCredentials, err)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+
+	switch {
+	case header.Alg == "HS256" && a.cfg.JWTSecret != "":
+		mac := hmac.New(sha256.New, []byte(a.cfg.JWTSecret))
+		mac.Write(signed)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return "", fmt.Errorf("%w: bad signature", errInvalidCredentials)
+		}
+	case header.Alg == "RS256" && a.keys != nil:
+		key, err := a.keys.key(ctx, header.Kid)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", errInvalidCredentials, err)
+		}
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+			return "", fmt.Errorf("%w: bad signature", errInvalidCredentials)
+		}
+	default:
+		return "", fmt.Errorf("%w: unsupported alg %q", errInvalidCredentials, header.Alg)
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", fmt.Errorf("%w: claims: %v", errInvalidCredentials, err)
+	}
+	if err := a.checkClaims(claims); err != nil {
+		return "", fmt.Errorf("%w: %v", errInvalidCredentials, err)
+	}
+	return domain.UserID(claims.Subject), nil
+}
+
+func (a *Authenticator) checkClaims(c jwtClaims) error {
+	now := a.now()
+	switch {
+	case c.Subject == "":
+		return errors.New("missing sub")
+	case c.ExpiresAt == nil:
+		return errors.New("missing exp")
+	case now.After(unixTime(*c.ExpiresAt).Add(clockSkew)):
+		return errors.New("token expired")
+	case c.NotBefore != nil && now.Add(clockSkew).Before(unixTime(*c.NotBefore)):
+		return errors.New("token not valid yet")
+	case a.cfg.Issuer != "" && c.Issuer != a.cfg.Issuer:
+		return fmt.Errorf("unexpected iss %q", c.Issuer)
+	case a.cfg.Audience != "" && !slices.Contains(c.Audience, a.cfg.Audience):
+		return fmt.Errorf("token is not for audience %q", a.cfg.Audience)
+	}
+	return nil
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// ─────────────────────────────────────────────
+// JWKS
+// ─────────────────────────────────────────────
+
+// jwks holds the RSA keys of a JSON Web Key Set by key ID.
+type jwks struct {
+	load    func(ctx context.Context) ([]byte, error)
+	refresh time.Duration // 0: loaded once
+
+	mu     sync.Mutex
+	keys   map[string]*rsa.PublicKey
+	loaded time.Time // last successful load
+	tried  time.Time // last reload started by key
+}
+
+// key returns the key with kid ("" is accepted when the set has one key).
+// Keys from a URL are reloaded when stale or when kid is unknown (a key
+// rotation), at most once per jwksMinRefresh; a failed reload keeps the
+// current keys. The fetch runs without the lock, so other requests keep
+// verifying with the current keys meanwhile.
+func (k *jwks) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	if k.claimRefresh(kid) {
+		if err := k.reload(ctx); err != nil {
+			observability.LoggerFromContext(ctx).Warn("failed to refresh the JWKS", "error", err)
+		}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if key, ok := k.keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// claimRefresh reports whether the keys must be reloaded before looking up
+// kid. Only one caller per jwksMinRefresh gets true, so concurrent requests
+// do not all fetch the set.
+func (k *jwks) claimRefresh(kid string) bool {
+	if k.refresh <= 0 {
+		return false
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	_, known := k.keys[kid]
+	now := time.Now()
+	if (now.Sub(k.loaded) > k.refresh || !known) && now.Sub(k.tried) > jwksMinRefresh {
+		k.tried = now
+		return true
+	}
+	return false
+}
+
+// reload loads and parses the set, then swaps the keys under the lock.
+func (k *jwks) reload(ctx context.Context) error {
+	data, err := k.load(ctx)
+	if err != nil {
+		return fmt.Errorf("loading JWKS: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = keys
+	k.loaded = time.Now()
+	return nil
+}
+
+// parseJWKS returns the RSA signing keys of a JWKS document by key ID.
+func parseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var doc struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decoding JWKS: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range doc.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("JWKS key %q: invalid modulus or exponent", jwk.Kid)
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS has no RSA signing keys")
+	}
+	return keys, nil
+}
+
+// fetchJWKS loads a JWKS from url.
+func fetchJWKS(url string) func(ctx context.Context) ([]byte, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	}
+}
@@ -0,0 +1,216 @@
+package httpadapter_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	httpadapter "github.com/PabloGalante/farum-agent/internal/adapters/http"
+	"github.com/PabloGalante/farum-agent/internal/adapters/llm"
+	"github.com/PabloGalante/farum-agent/internal/adapters/storage/memory"
+	"github.com/PabloGalante/farum-agent/internal/app/conversation"
+	journalapp "github.com/PabloGalante/farum-agent/internal/app/journal"
+	"github.com/PabloGalante/farum-agent/internal/app/tools"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+const testSecret = "test-secret"
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signJWT returns a compact JWT; key is a []byte secret (HS256) or an
+// *rsa.PrivateKey (RS256).
+func signJWT(t *testing.T, header, claims map[string]any, key any) string {
+	t.Helper()
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	signed := b64(h) + "." + b64(c)
+
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+	}
+	return signed + "." + b64(sig)
+}
+
+func hsToken(t *testing.T, sub string, exp time.Time) string {
+	return signJWT(t, map[string]any{"alg": "HS256", "typ": "JWT"}, map[string]any{"sub": sub, "exp": exp.Unix()}, []byte(testSecret))
+}
+
+func newAuthServer(t *testing.T, cfg httpadapter.AuthConfig) http.Handler {
+	t.Helper()
+
+	auth, err := httpadapter.NewAuthenticator(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewAuthenticator: %v", err)
+	}
+
+	journalStore := memory.NewJournalStore()
+	convSvc := conversation.NewService(llm.NewMockLLM(), memory.NewSessionStore(), memory.NewMessageStore(), tools.NewJournalTool(journalStore))
+	return httpadapter.NewServer(convSvc, journalapp.NewService(journalStore), httpadapter.WithAuth(auth))
+}
+
+func call(srv http.Handler, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	return w
+}
+
+func bearer(token string) http.Header {
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
+
+func TestAuthRejectsMissingAndInvalidCredentials(t *testing.T) {
+	srv := newAuthServer(t, httpadapter.AuthConfig{JWTSecret: testSecret})
+	later := time.Now().Add(time.Hour)
+
+	if w := call(srv, http.MethodGet, "/healthz", "", nil); w.Code != http.StatusOK {
+		t.Fatalf("expected a public health check, got %d", w.Code)
+	}
+
+	w := call(srv, http.MethodGet, "/users/ana/journal", "", nil)
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("expected 401 with a challenge, got %d %v", w.Code, w.Header())
+	}
+
+	for name, token := range map[string]string{
+		"wrong secret": signJWT(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "ana", "exp": later.Unix()}, []byte("other")),
+		"expired":      hsToken(t, "ana", time.Now().Add(-time.Hour)),
+		"no exp":       signJWT(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "ana"}, []byte(testSecret)),
+		"alg none":     b64([]byte(`{"alg":"none"}`)) + "." + b64([]byte(`{"sub":"ana","exp":9999999999}`)) + ".",
+		"garbage":      "a.b.c",
+		"api key":      "not-a-key",
+	} {
+		if w := call(srv, http.MethodGet, "/users/ana/journal", "", bearer(token)); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401, got %d", name, w.Code)
+		}
+	}
+
+	if w := call(srv, http.MethodGet, "/users/ana/journal", "", bearer(hsToken(t, "ana", later))); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with a valid token, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestAuthEnforcesOwnership(t *testing.T) {
+	srv := newAuthServer(t, httpadapter.AuthConfig{
+		JWTSecret: testSecret,
+		APIKeys:   map[string]domain.UserID{"bob-key": "bob"},
+	})
+	ana := bearer(hsToken(t, "ana", time.Now().Add(time.Hour)))
+	bob := http.Header{"X-Api-Key": {"bob-key"}}
+
+	// The user_id of the body defaults to the authenticated user, and cannot be someone else
+	if w := call(srv, http.MethodPost, "/sessions", `{"user_id":"bob"}`, ana); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 creating a session for another user, got %d", w.Code)
+	}
+	w := call(srv, http.MethodPost, "/sessions", `{"preferred_mode":"check_in"}`, ana)
+	var created struct {
+		Session struct {
+			ID     string `json:"id"`
+			UserID string `json:"user_id"`
+		} `json:"session"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || w.Code != http.StatusCreated || created.Session.UserID != "ana" {
+		t.Fatalf("expected a session of ana, got %d, body=%s", w.Code, w.Body.String())
+	}
+	base := "/sessions/" + created.Session.ID
+	message := `{"text":"Hoy estoy cansada"}`
+
+	if w := call(srv, http.MethodPost, base+"/messages", message, ana); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for the owner, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	for _, tc := range []struct{ method, path, body string }{
+		{http.MethodGet, base, ""},
+		{http.MethodPost, base + "/messages", message},
+		{http.MethodPost, base + "/messages:stream", message},
+		{http.MethodPost, base + "/close", ""},
+		{http.MethodGet, "/users/ana/journal", ""},
+		{http.MethodGet, "/users/ana/sessions", ""},
+	} {
+		if w := call(srv, tc.method, tc.path, tc.body, bob); w.Code != http.StatusForbidden {
+			t.Errorf("%s %s as bob: expected 403, got %d, body=%s", tc.method, tc.path, w.Code, w.Body.String())
+		}
+	}
+	if w := call(srv, http.MethodPost, base+"/messages", `{"user_id":"bob","text":"hola"}`, ana); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a foreign user_id in the body, got %d", w.Code)
+	}
+
+	// A bearer API key works too
+	if w := call(srv, http.MethodGet, "/users/bob/sessions", "", bearer("bob-key")); w.Code != http.StatusOK {
+		t.Errorf("expected 200 for bob's own sessions, got %d", w.Code)
+	}
+}
+
+func TestAuthVerifiesRS256WithJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "k1",
+		"use": "sig",
+		"n":   b64(key.N.Bytes()),
+		"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(file, jwks, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	keys := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(jwks)
+	}))
+	defer keys.Close()
+
+	claims := map[string]any{"sub": "ana", "exp": time.Now().Add(time.Hour).Unix(), "iss": "https://id.example", "aud": []string{"farum"}}
+	token := signJWT(t, map[string]any{"alg": "RS256", "kid": "k1"}, claims, key)
+
+	for name, cfg := range map[string]httpadapter.AuthConfig{
+		"file": {JWKSFile: file, Issuer: "https://id.example", Audience: "farum"},
+		"url":  {JWKSURL: keys.URL, Issuer: "https://id.example", Audience: "farum"},
+	} {
+		srv := newAuthServer(t, cfg)
+		if w := call(srv, http.MethodGet, "/users/ana/sessions", "", bearer(token)); w.Code != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d, body=%s", name, w.Code, w.Body.String())
+		}
+
+		other := signJWT(t, map[string]any{"alg": "RS256", "kid": "k1"}, map[string]any{"sub": "ana", "exp": claims["exp"], "iss": "https://id.example", "aud": "other"}, key)
+		if w := call(srv, http.MethodGet, "/users/ana/sessions", "", bearer(other)); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401 for another audience, got %d", name, w.Code)
+		}
+	}
+
+	if _, err := httpadapter.NewAuthenticator(context.Background(), httpadapter.AuthConfig{JWKSFile: filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Fatalf("expected an error for a missing JWKS file")
+	}
+}
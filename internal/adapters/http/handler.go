@@ -1,14 +1,18 @@
 package httpadapter
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/PabloGalante/farum-agent/internal/app/agentflow"
 	"github.com/PabloGalante/farum-agent/internal/app/conversation"
 	"github.com/PabloGalante/farum-agent/internal/app/journal"
 	"github.com/PabloGalante/farum-agent/internal/domain"
@@ -17,13 +21,29 @@ import (
 type Server struct {
 	convSvc    *conversation.Service
 	journalSvc *journal.Service
+	auth       *Authenticator
 }
 
-func NewServer(convSvc *conversation.Service, journalSvc *journal.Service) http.Handler {
+// ServerOption configures optional features of the Server.
+type ServerOption func(*Server)
+
+// WithAuth requires valid credentials on every route but /healthz, and
+// restricts each caller to their own sessions and user data (403 otherwise).
+// Without it the user_id of the requests is trusted.
+func WithAuth(auth *Authenticator) ServerOption {
+	return func(s *Server) {
+		s.auth = auth
+	}
+}
+
+func NewServer(convSvc *conversation.Service, journalSvc *journal.Service, opts ...ServerOption) http.Handler {
 	s := &Server{
 		convSvc:    convSvc,
 		journalSvc: journalSvc,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
 	mux := http.NewServeMux()
 
 	// healthcheck
@@ -32,14 +52,26 @@ func NewServer(convSvc *conversation.Service, journalSvc *journal.Service) http.
 	// /sessions → create session (POST)
 	mux.HandleFunc("/sessions", s.handleSessions)
 
-	// /sessions/{id}         →  GET: get session + messages
-	// /sessions/{id}/messages → POST: send message
+	// /sessions/{id}                →  GET: get session + messages (?view=reply|trace)
+	// /sessions/{id}/messages        → POST: send message
+	// /sessions/{id}/messages:stream → POST: send message, reply as SSE
+	// /sessions/{id}/close           → POST: close (final journal entry + summary)
+	// /sessions/{id}/archive         → POST: archive (closes it first if active)
+	// /sessions/{id}/resume          → POST: make a closed/archived session active
 	mux.HandleFunc("/sessions/", s.handleSessionWithID)
 
-	// /users/{id}/journal → GET: get user's journal entries
+	// /users/{id}/sessions             → GET: list sessions (?limit=&offset=&status=)
+	// /users/{id}/journal              → GET: get user's journal entries
+	// /users/{id}/actions              → GET: list open actions
+	// /users/{id}/actions/stats        → GET: action completion stats
+	// /users/{id}/actions/{action_id}  → POST: update action status/notes
 	mux.HandleFunc("/users/", s.handleUserWithID)
 
-	return chainMiddlewares(mux, withCORS, withLogging)
+	middlewares := []func(http.Handler) http.Handler{withCORS, withLogging}
+	if s.auth != nil {
+		middlewares = append([]func(http.Handler) http.Handler{withAuth(s.auth)}, middlewares...)
+	}
+	return chainMiddlewares(mux, middlewares...)
 }
 
 // ─────────────────────────────────────────────
@@ -58,21 +90,38 @@ type createSessionResponse struct {
 }
 
 type sessionResponse struct {
-	ID            string    `json:"id"`
-	UserID        string    `json:"user_id"`
-	Title         string    `json:"title"`
-	PreferredMode string    `json:"preferred_mode"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            string     `json:"id"`
+	UserID        string     `json:"user_id"`
+	Title         string     `json:"title"`
+	PreferredMode string     `json:"preferred_mode"`
+	Status        string     `json:"status"`
+	Summary       string     `json:"summary,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty"`
+}
+
+type listSessionsResponse struct {
+	Sessions   []sessionResponse `json:"sessions"`
+	NextOffset int               `json:"next_offset,omitempty"` // absent on the last page
+}
+
+type sessionStatusResponse struct {
+	Session        sessionResponse `json:"session"`
+	JournalEntryID string          `json:"journal_entry_id,omitempty"`
 }
 
 type messageResponse struct {
-	ID        string    `json:"id"`
-	SessionID string    `json:"session_id"`
-	Author    string    `json:"author"`
-	Text      string    `json:"text"`
-	Mode      string    `json:"mode"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          string    `json:"id"`
+	SessionID   string    `json:"session_id"`
+	Author      string    `json:"author"`
+	Agent       string    `json:"agent,omitempty"`
+	Text        string    `json:"text"`
+	Mode        string    `json:"mode"`
+	ContentType string    `json:"content_type,omitempty"`
+	ReplyTo     string    `json:"reply_to,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	Tags        []string  `json:"tags,omitempty"`
 }
 
 type sendMessageRequest struct {
@@ -83,6 +132,16 @@ type sendMessageRequest struct {
 type sendMessageResponse struct {
 	UserMessage  messageResponse `json:"user_message"`
 	AgentMessage messageResponse `json:"agent_message"`
+	Routing      routingResponse `json:"routing"`
+}
+
+// routingResponse explains the mode picked for the message.
+type routingResponse struct {
+	Mode         string             `json:"mode"`
+	PreviousMode string             `json:"previous_mode,omitempty"`
+	Changed      bool               `json:"changed"`
+	Reason       string             `json:"reason"`
+	Scores       map[string]float64 `json:"scores,omitempty"`
 }
 
 type getSessionResponse struct {
@@ -114,6 +173,24 @@ type journalEntryResponse struct {
 	MoodAfter      string                  `json:"mood_after"`
 }
 
+// Action tracking DTOs
+
+type openActionResponse struct {
+	EntryID   string                `json:"entry_id"`
+	SessionID string                `json:"session_id"`
+	Action    journalActionResponse `json:"action"`
+}
+
+type updateActionRequest struct {
+	Status string `json:"status"`
+	Notes  string `json:"notes,omitempty"`
+}
+
+type updateActionResponse struct {
+	EntryID string                `json:"entry_id"`
+	Action  journalActionResponse `json:"action"`
+}
+
 // ─────────────────────────────────────────────
 // Basic routing
 // ─────────────────────────────────────────────
@@ -128,11 +205,15 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// /sessions/{id} or /sessions/{id}/messages
+// /sessions/{id}, /sessions/{id}/messages[:stream] or /sessions/{id}/{close,archive,resume}
 func (s *Server) handleSessionWithID(w http.ResponseWriter, r *http.Request) {
 	// expected path:
 	// /sessions/{id}
 	// /sessions/{id}/messages
+	// /sessions/{id}/messages:stream
+	// /sessions/{id}/close
+	// /sessions/{id}/archive
+	// /sessions/{id}/resume
 	path := strings.TrimPrefix(r.URL.Path, "/sessions/")
 	if path == "" {
 		http.NotFound(w, r)
@@ -169,13 +250,50 @@ func (s *Server) handleSessionWithID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(parts) == 2 && parts[1] == "messages:stream" {
+		// /sessions/{id}/messages:stream
+		switch r.Method {
+		case http.MethodPost:
+			s.handleSendMessageStream(w, r, domain.SessionID(id))
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	if len(parts) == 2 {
+		var change func(context.Context, domain.SessionID) (*conversation.SessionStatusOutput, error)
+		switch parts[1] {
+		case "close":
+			change = s.convSvc.CloseSession
+		case "archive":
+			change = s.convSvc.ArchiveSession
+		case "resume":
+			change = s.convSvc.ResumeSession
+		}
+		if change != nil {
+			// /sessions/{id}/{close,archive,resume}
+			switch r.Method {
+			case http.MethodPost:
+				s.handleSessionStatus(w, r, domain.SessionID(id), change)
+			default:
+				methodNotAllowed(w)
+			}
+			return
+		}
+	}
+
 	http.NotFound(w, r)
 }
 
-// /users/{id}/journal
+// /users/{id}/sessions, /users/{id}/journal or /users/{id}/actions[/...]
 func (s *Server) handleUserWithID(w http.ResponseWriter, r *http.Request) {
 	// expected path:
+	// /users/{id}/sessions
 	// /users/{id}/journal
+	// /users/{id}/actions
+	// /users/{id}/actions/stats
+	// /users/{id}/actions/{action_id}
 	path := strings.TrimPrefix(r.URL.Path, "/users/")
 	if path == "" {
 		http.NotFound(w, r)
@@ -190,6 +308,22 @@ func (s *Server) handleUserWithID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Everything under /users/{id} is data of that user
+	if err := domain.CheckOwner(r.Context(), domain.UserID(userID)); err != nil {
+		forbidden(w, err.Error())
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "sessions" {
+		switch r.Method {
+		case http.MethodGet:
+			s.handleListUserSessions(w, r, domain.UserID(userID))
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
 	if len(parts) == 2 && parts[1] == "journal" {
 		switch r.Method {
 		case http.MethodGet:
@@ -200,6 +334,36 @@ func (s *Server) handleUserWithID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(parts) == 2 && parts[1] == "actions" {
+		switch r.Method {
+		case http.MethodGet:
+			s.handleListOpenActions(w, r, domain.UserID(userID))
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	if len(parts) == 3 && parts[1] == "actions" && parts[2] == "stats" {
+		switch r.Method {
+		case http.MethodGet:
+			s.handleGetActionStats(w, r, domain.UserID(userID))
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
+	if len(parts) == 3 && parts[1] == "actions" && parts[2] != "" {
+		switch r.Method {
+		case http.MethodPost:
+			s.handleUpdateAction(w, r, domain.UserID(userID), parts[2])
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	}
+
 	http.NotFound(w, r)
 }
 
@@ -214,28 +378,32 @@ func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.UserID == "" {
-		badRequest(w, "user_id is required")
+	userID, ok := requestUser(w, r, req.UserID)
+	if !ok {
 		return
 	}
 
-	mode := parseInteractionMode(req.PreferredMode)
+	mode, ok := parseInteractionMode(req.PreferredMode)
+	if !ok {
+		badRequest(w, "preferred_mode must be one of: check_in, deep_dive, action_plan")
+		return
+	}
 
 	out, err := s.convSvc.StartSession(
 		r.Context(),
 		conversation.StartSessionInput{
-			UserID:        domain.UserID(req.UserID),
+			UserID:        userID,
 			PreferredMode: mode,
 			Title:         req.Title,
 		},
 	)
 	if err != nil {
-		internalError(w, err)
+		sessionError(w, err)
 		return
 	}
 
 	// To obtain welcome message, request the timeline limited to the 1-2 most recent messages.
-	_, msgs, err := s.convSvc.GetSessionTimeline(r.Context(), out.Session.ID, 5)
+	_, msgs, err := s.convSvc.GetSessionTimeline(r.Context(), out.Session.ID, 5, conversation.ViewReply)
 	if err != nil {
 		internalError(w, err)
 		return
@@ -259,14 +427,18 @@ func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleGetSession(w http.ResponseWriter, r *http.Request, id domain.SessionID) {
-	session, msgs, err := s.convSvc.GetSessionTimeline(r.Context(), id, 0)
+	view := conversation.TimelineView(r.URL.Query().Get("view"))
+	if view == "" {
+		view = conversation.ViewReply
+	}
+	if !view.Valid() {
+		badRequest(w, "view must be one of: reply, trace")
+		return
+	}
+
+	session, msgs, err := s.convSvc.GetSessionTimeline(r.Context(), id, 0, view)
 	if err != nil {
-		// Any error is (for simplicity) → 404 if it is "not found"
-		if errors.Is(err, errors.New("session not found")) {
-			http.NotFound(w, r)
-			return
-		}
-		internalError(w, err)
+		sessionError(w, err)
 		return
 	}
 
@@ -279,37 +451,153 @@ func (s *Server) handleGetSession(w http.ResponseWriter, r *http.Request, id dom
 }
 
 func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request, sessionID domain.SessionID) {
-	var req sendMessageRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		badRequest(w, "invalid JSON body")
+	req, ok := decodeSendMessageRequest(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := requestUser(w, r, req.UserID)
+	if !ok {
 		return
 	}
 
-	if req.UserID == "" {
-		badRequest(w, "user_id is required")
+	out, err := s.convSvc.SendMessage(
+		r.Context(),
+		conversation.SendMessageInput{
+			SessionID: sessionID,
+			UserID:    userID,
+			Text:      req.Text,
+		},
+	)
+	if err != nil {
+		sessionError(w, err)
 		return
 	}
-	if strings.TrimSpace(req.Text) == "" {
-		badRequest(w, "text is required")
+
+	writeJSON(w, http.StatusOK, toSendMessageResponse(out))
+}
+
+// POST /sessions/{id}/messages:stream
+//
+// Emits Server-Sent Events:
+//   - agent_start / agent_end: {"type","agent","elapsed_ms"}
+//   - agent_skipped: {"type","agent"} when a pipeline condition skips the agent
+//   - token: {"type","agent","text"} chunks of the final reply
+//   - done: sendMessageResponse
+//   - error: {"error"}
+func (s *Server) handleSendMessageStream(w http.ResponseWriter, r *http.Request, sessionID domain.SessionID) {
+	req, ok := decodeSendMessageRequest(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := requestUser(w, r, req.UserID)
+	if !ok {
 		return
 	}
 
-	out, err := s.convSvc.SendMessage(
+	// Reject foreign and closed sessions with a status code, before the stream starts
+	session, err := s.convSvc.GetSession(r.Context(), sessionID)
+	if err != nil {
+		sessionError(w, err)
+		return
+	}
+	if session.UserID != userID {
+		forbidden(w, fmt.Sprintf("%s: session %s does not belong to %s", domain.ErrForbidden, sessionID, userID))
+		return
+	}
+	if !session.IsActive() {
+		conflict(w, fmt.Sprintf("%s: session is %s", conversation.ErrSessionNotActive, session.CurrentStatus()))
+		return
+	}
+
+	sse, err := newSSEWriter(w)
+	if err != nil {
+		internalError(w, err)
+		return
+	}
+
+	sink := func(ev agentflow.Event) {
+		// A write error means the client went away; the request context
+		// will be cancelled and the orchestrator will stop.
+		_ = sse.send(string(ev.Type), ev)
+	}
+
+	out, err := s.convSvc.SendMessageStream(
 		r.Context(),
 		conversation.SendMessageInput{
 			SessionID: sessionID,
-			UserID:    domain.UserID(req.UserID),
+			UserID:    userID,
 			Text:      req.Text,
 		},
+		sink,
 	)
+	if err != nil {
+		log.Printf("stream send message error: %v", err)
+		msg := "internal server error"
+		if errors.Is(err, conversation.ErrSessionNotActive) {
+			msg = err.Error()
+		}
+		_ = sse.send("error", map[string]string{"error": msg})
+		return
+	}
+
+	_ = sse.send("done", toSendMessageResponse(out))
+}
+
+// POST /sessions/{id}/close, /sessions/{id}/archive or /sessions/{id}/resume
+func (s *Server) handleSessionStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	id domain.SessionID,
+	change func(context.Context, domain.SessionID) (*conversation.SessionStatusOutput, error),
+) {
+	out, err := change(r.Context(), id)
+	if err != nil {
+		sessionError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sessionStatusResponse{
+		Session:        toSessionResponse(out.Session),
+		JournalEntryID: string(out.JournalEntryID),
+	})
+}
+
+// GET /users/{id}/sessions
+func (s *Server) handleListUserSessions(w http.ResponseWriter, r *http.Request, userID domain.UserID) {
+	q := r.URL.Query()
+
+	in := conversation.ListSessionsInput{UserID: userID}
+	if n, err := strconv.Atoi(q.Get("limit")); err == nil && n > 0 {
+		in.Limit = min(n, 100)
+	}
+	if n, err := strconv.Atoi(q.Get("offset")); err == nil && n > 0 {
+		in.Offset = n
+	}
+
+	status := domain.SessionStatus(strings.ToLower(strings.TrimSpace(q.Get("status"))))
+	switch {
+	case status == "":
+	case status == "all":
+		in.Statuses = domain.SessionStatuses
+	case status.Valid():
+		in.Statuses = []domain.SessionStatus{status}
+	default:
+		badRequest(w, "status must be one of: active, closed, archived, all")
+		return
+	}
+
+	out, err := s.convSvc.ListSessions(r.Context(), in)
 	if err != nil {
 		internalError(w, err)
 		return
 	}
 
-	resp := sendMessageResponse{
-		UserMessage:  toMessageResponse(out.UserMessage),
-		AgentMessage: toMessageResponse(out.AgentMessage),
+	resp := listSessionsResponse{
+		Sessions:   make([]sessionResponse, 0, len(out.Sessions)),
+		NextOffset: out.NextOffset,
+	}
+	for _, sess := range out.Sessions {
+		resp.Sessions = append(resp.Sessions, toSessionResponse(sess))
 	}
 
 	writeJSON(w, http.StatusOK, resp)
@@ -318,7 +606,7 @@ func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request, sessi
 // GET /users/{id}/journal
 func (s *Server) handleGetUserJournal(w http.ResponseWriter, r *http.Request, userID domain.UserID) {
 	if s.journalSvc == nil {
-		// Disabled journal (for now this could happen in GCP mode without FirestoreJournalStore)
+		// Disabled journal (no journal service configured)
 		writeJSON(w, http.StatusOK, []journalEntryResponse{})
 		return
 	}
@@ -344,29 +632,150 @@ func (s *Server) handleGetUserJournal(w http.ResponseWriter, r *http.Request, us
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// GET /users/{id}/actions
+func (s *Server) handleListOpenActions(w http.ResponseWriter, r *http.Request, userID domain.UserID) {
+	if s.journalSvc == nil {
+		writeJSON(w, http.StatusOK, []openActionResponse{})
+		return
+	}
+
+	open, err := s.journalSvc.ListOpenActions(r.Context(), userID)
+	if err != nil {
+		internalError(w, err)
+		return
+	}
+
+	resp := make([]openActionResponse, 0, len(open))
+	for _, o := range open {
+		resp = append(resp, openActionResponse{
+			EntryID:   string(o.EntryID),
+			SessionID: string(o.SessionID),
+			Action:    toJournalActionResponse(o.Action),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GET /users/{id}/actions/stats
+func (s *Server) handleGetActionStats(w http.ResponseWriter, r *http.Request, userID domain.UserID) {
+	if s.journalSvc == nil {
+		writeJSON(w, http.StatusOK, journal.ActionStats{})
+		return
+	}
+
+	stats, err := s.journalSvc.GetActionStats(r.Context(), userID)
+	if err != nil {
+		internalError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// POST /users/{id}/actions/{action_id}
+func (s *Server) handleUpdateAction(w http.ResponseWriter, r *http.Request, userID domain.UserID, actionID string) {
+	if s.journalSvc == nil {
+		notFound(w, "journal is disabled")
+		return
+	}
+
+	var req updateActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequest(w, "invalid JSON body")
+		return
+	}
+
+	if req.Status == "" {
+		badRequest(w, "status is required")
+		return
+	}
+
+	out, err := s.journalSvc.UpdateAction(r.Context(), journal.UpdateActionInput{
+		UserID:   userID,
+		ActionID: actionID,
+		Status:   domain.ActionStatus(strings.ToLower(strings.TrimSpace(req.Status))),
+		Notes:    req.Notes,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, journal.ErrInvalidActionStatus):
+			badRequest(w, "status must be one of: pending, done, skipped")
+		case errors.Is(err, journal.ErrActionNotFound), errors.Is(err, journal.ErrJournalDisabled):
+			notFound(w, err.Error())
+		default:
+			internalError(w, err)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updateActionResponse{
+		EntryID: string(out.EntryID),
+		Action:  toJournalActionResponse(out.Action),
+	})
+}
+
 // ─────────────────────────────────────────────
 // Conversation Helpers
 // ─────────────────────────────────────────────
 
 func toSessionResponse(s *domain.Session) sessionResponse {
-	return sessionResponse{
+	resp := sessionResponse{
 		ID:            string(s.ID),
 		UserID:        string(s.UserID),
 		Title:         s.Title,
 		PreferredMode: string(s.PreferredMode),
+		Status:        string(s.CurrentStatus()),
+		Summary:       s.Summary,
 		CreatedAt:     s.CreatedAt,
 		UpdatedAt:     s.UpdatedAt,
 	}
+	if !s.ClosedAt.IsZero() {
+		closedAt := s.ClosedAt
+		resp.ClosedAt = &closedAt
+	}
+	return resp
 }
 
 func toMessageResponse(m *domain.Message) messageResponse {
-	return messageResponse{
-		ID:        string(m.ID),
-		SessionID: string(m.SessionID),
-		Author:    string(m.Author),
-		Text:      m.Text,
-		Mode:      string(m.Mode),
-		CreatedAt: m.CreatedAt,
+	resp := messageResponse{
+		ID:          string(m.ID),
+		SessionID:   string(m.SessionID),
+		Author:      string(m.Author),
+		Agent:       m.Agent(),
+		Text:        m.Text,
+		Mode:        string(m.Mode),
+		ContentType: m.ContentType,
+		CreatedAt:   m.CreatedAt,
+		Tags:        m.Tags,
+	}
+	if m.ReplyTo != nil {
+		resp.ReplyTo = string(*m.ReplyTo)
+	}
+	return resp
+}
+
+func toSendMessageResponse(out *conversation.SendMessageOutput) sendMessageResponse {
+	routed := out.Routing
+
+	var scores map[string]float64
+	if len(routed.Scores) > 0 {
+		scores = make(map[string]float64, len(routed.Scores))
+		for m, v := range routed.Scores {
+			scores[string(m)] = math.Round(v*1000) / 1000
+		}
+	}
+
+	return sendMessageResponse{
+		UserMessage:  toMessageResponse(out.UserMessage),
+		AgentMessage: toMessageResponse(out.AgentMessage),
+		Routing: routingResponse{
+			Mode:         string(routed.Mode),
+			PreviousMode: string(routed.Previous),
+			Changed:      routed.Changed(),
+			Reason:       routed.Reason,
+			Scores:       scores,
+		},
 	}
 }
 
@@ -378,17 +787,21 @@ func toMessagesResponse(msgs []*domain.Message) []messageResponse {
 	return out
 }
 
+func toJournalActionResponse(a domain.JournalAction) journalActionResponse {
+	return journalActionResponse{
+		ID:          a.ID,
+		Description: a.Description,
+		Status:      string(a.Status),
+		Notes:       a.Notes,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
 func toJournalEntryResponse(e *domain.JournalEntry) journalEntryResponse {
 	actions := make([]journalActionResponse, 0, len(e.ActionPlan))
 	for _, a := range e.ActionPlan {
-		actions = append(actions, journalActionResponse{
-			ID:          a.ID,
-			Description: a.Description,
-			Status:      string(a.Status),
-			Notes:       a.Notes,
-			CreatedAt:   a.CreatedAt,
-			UpdatedAt:   a.UpdatedAt,
-		})
+		actions = append(actions, toJournalActionResponse(a))
 	}
 
 	return journalEntryResponse{
@@ -405,16 +818,56 @@ func toJournalEntryResponse(e *domain.JournalEntry) journalEntryResponse {
 	}
 }
 
-func parseInteractionMode(s string) domain.InteractionMode {
+// decodeSendMessageRequest decodes and validates the body of a send message request.
+// On failure it writes the error response and returns false.
+func decodeSendMessageRequest(w http.ResponseWriter, r *http.Request) (sendMessageRequest, bool) {
+	var req sendMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequest(w, "invalid JSON body")
+		return req, false
+	}
+
+	if strings.TrimSpace(req.Text) == "" {
+		badRequest(w, "text is required")
+		return req, false
+	}
+	return req, true
+}
+
+// requestUser returns the user a request acts as: the authenticated user,
+// or else the user_id of its body. A user_id other than the authenticated
+// user is forbidden. On failure it writes the error response and returns false.
+func requestUser(w http.ResponseWriter, r *http.Request, bodyUserID string) (domain.UserID, bool) {
+	claimed := domain.UserID(bodyUserID)
+	subject, ok := domain.SubjectFromContext(r.Context())
+	switch {
+	case !ok && claimed == "":
+		badRequest(w, "user_id is required")
+		return "", false
+	case !ok:
+		return claimed, true
+	case claimed != "" && claimed != subject:
+		forbidden(w, fmt.Sprintf("%s: authenticated as %s, not %s", domain.ErrForbidden, subject, claimed))
+		return "", false
+	default:
+		return subject, true
+	}
+}
+
+// parseInteractionMode parses a preferred mode. Empty means no preference
+// (the mode router decides); an unknown mode is not ok.
+func parseInteractionMode(s string) (domain.InteractionMode, bool) {
 	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return "", true
 	case "check_in", "checkin":
-		return domain.ModeCheckIn
+		return domain.ModeCheckIn, true
 	case "deep_dive", "deep":
-		return domain.ModeDeepDive
+		return domain.ModeDeepDive, true
 	case "action_plan", "action":
-		return domain.ModeActionPlan
+		return domain.ModeActionPlan, true
 	default:
-		return domain.ModeCheckIn
+		return "", false
 	}
 }
 
@@ -434,6 +887,45 @@ func badRequest(w http.ResponseWriter, msg string) {
 	})
 }
 
+func notFound(w http.ResponseWriter, msg string) {
+	writeJSON(w, http.StatusNotFound, map[string]string{
+		"error": msg,
+	})
+}
+
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="farum"`)
+	writeJSON(w, http.StatusUnauthorized, map[string]string{
+		"error": msg,
+	})
+}
+
+func forbidden(w http.ResponseWriter, msg string) {
+	writeJSON(w, http.StatusForbidden, map[string]string{
+		"error": msg,
+	})
+}
+
+func conflict(w http.ResponseWriter, msg string) {
+	writeJSON(w, http.StatusConflict, map[string]string{
+		"error": msg,
+	})
+}
+
+// sessionError maps the errors of the session operations to a status code.
+func sessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrSessionNotFound):
+		notFound(w, err.Error())
+	case errors.Is(err, domain.ErrForbidden):
+		forbidden(w, err.Error())
+	case errors.Is(err, conversation.ErrSessionNotActive), errors.Is(err, conversation.ErrSessionTransition):
+		conflict(w, err.Error())
+	default:
+		internalError(w, err)
+	}
+}
+
 func internalError(w http.ResponseWriter, err error) {
 	log.Printf("internal server error: %v", err)
 
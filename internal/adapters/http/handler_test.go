@@ -3,8 +3,10 @@ package httpadapter_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	httpadapter "github.com/PabloGalante/farum-agent/internal/adapters/http"
@@ -46,6 +48,22 @@ func TestHealthz(t *testing.T) {
 	}
 }
 
+func TestDebugVarsOnlyOnTheAdminServer(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+
+	w := httptest.NewRecorder()
+	newTestServer(t).ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected no /debug/vars on the API, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	httpadapter.NewAdminServer().ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "memstats") {
+		t.Fatalf("expected the expvar metrics on the admin server, got %d", w.Code)
+	}
+}
+
 func TestCreateSessionAndSendMessage(t *testing.T) {
 	srv := newTestServer(t)
 
@@ -60,3 +78,203 @@ func TestCreateSessionAndSendMessage(t *testing.T) {
 		t.Fatalf("expected 201, got %d, body=%s", w.Code, w.Body.String())
 	}
 }
+
+func TestSendMessageStream(t *testing.T) {
+	srv := newTestServer(t)
+
+	body := []byte(`{"user_id":"test-user","preferred_mode":"check_in","title":"Test"}`)
+	req := httptest.NewRequest(http.MethodPost, "/sessions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	var created struct {
+		Session struct {
+			ID string `json:"id"`
+		} `json:"session"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create response: %v", err)
+	}
+
+	body = []byte(`{"user_id":"test-user","text":"Hola Farum"}`)
+	req = httptest.NewRequest(http.MethodPost, "/sessions/"+created.Session.ID+"/messages:stream", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	stream := w.Body.String()
+	for _, ev := range []string{"event: agent_start", "event: agent_end", "event: token", "event: done"} {
+		if !strings.Contains(stream, ev) {
+			t.Errorf("expected %q in stream, got:\n%s", ev, stream)
+		}
+	}
+	if strings.Contains(stream, "event: error") {
+		t.Errorf("unexpected error event in stream:\n%s", stream)
+	}
+}
+
+func TestCreateSessionRejectsUnknownMode(t *testing.T) {
+	srv := newTestServer(t)
+
+	body := []byte(`{"user_id":"test-user","preferred_mode":"therapy"}`)
+	req := httptest.NewRequest(http.MethodPost, "/sessions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetSessionView(t *testing.T) {
+	srv := newTestServer(t)
+
+	body := []byte(`{"user_id":"test-user","preferred_mode":"action_plan"}`)
+	req := httptest.NewRequest(http.MethodPost, "/sessions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+
+	var created struct {
+		Session struct {
+			ID string `json:"id"`
+		} `json:"session"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create response: %v", err)
+	}
+
+	body = []byte(`{"user_id":"test-user","text":"¿Qué puedo hacer para dormir mejor?"}`)
+	req = httptest.NewRequest(http.MethodPost, "/sessions/"+created.Session.ID+"/messages", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	type message struct {
+		Agent       string `json:"agent"`
+		ContentType string `json:"content_type"`
+		ReplyTo     string `json:"reply_to"`
+	}
+	get := func(query string) (int, []message) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/sessions/"+created.Session.ID+query, nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+
+		var resp struct {
+			Messages []message `json:"messages"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Messages
+	}
+
+	code, msgs := get("")
+	if code != http.StatusOK || len(msgs) != 3 {
+		t.Fatalf("expected the welcome, the user message and the reply, got %d: %+v", code, msgs)
+	}
+
+	code, msgs = get("?view=trace")
+	if code != http.StatusOK || len(msgs) != 6 {
+		t.Fatalf("expected every agent output, got %d: %+v", code, msgs)
+	}
+	if m := msgs[3]; m.Agent != "planner" || m.ContentType != "task_list" || m.ReplyTo == "" {
+		t.Fatalf("expected the planner output linked to the user message, got %+v", m)
+	}
+
+	if code, _ := get("?view=debug"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown view, got %d", code)
+	}
+}
+
+func TestSessionLifecycleRoutes(t *testing.T) {
+	srv := newTestServer(t)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/sessions", `{"user_id":"test-user","preferred_mode":"check_in"}`)
+	var created struct {
+		Session struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		} `json:"session"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || created.Session.Status != "active" {
+		t.Fatalf("expected an active session, got %s (%v)", w.Body.String(), err)
+	}
+	base := "/sessions/" + created.Session.ID
+	message := `{"user_id":"test-user","text":"Hoy estoy cansada"}`
+
+	if w := do(http.MethodPost, base+"/messages", message); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+
+	w = do(http.MethodPost, base+"/close", "")
+	var closed struct {
+		Session struct {
+			Status   string `json:"status"`
+			Summary  string `json:"summary"`
+			ClosedAt string `json:"closed_at"`
+		} `json:"session"`
+		JournalEntryID string `json:"journal_entry_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &closed); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if closed.Session.Status != "closed" || closed.Session.Summary == "" || closed.Session.ClosedAt == "" || closed.JournalEntryID == "" {
+		t.Fatalf("expected a closed, summarized and journaled session, got %s", w.Body.String())
+	}
+
+	// Closed sessions reject messages, also before a stream starts
+	for _, path := range []string{base + "/messages", base + "/messages:stream", base + "/close"} {
+		if w := do(http.MethodPost, path, message); w.Code != http.StatusConflict {
+			t.Errorf("POST %s: expected 409, got %d, body=%s", path, w.Code, w.Body.String())
+		}
+	}
+	if w := do(http.MethodPost, "/sessions/missing/close", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing session, got %d", w.Code)
+	}
+
+	w = do(http.MethodGet, "/users/test-user/sessions?limit=10", "")
+	var list struct {
+		Sessions []struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		} `json:"sessions"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || len(list.Sessions) != 1 || list.Sessions[0].Status != "closed" {
+		t.Fatalf("expected the closed session listed, got %s (%v)", w.Body.String(), err)
+	}
+
+	if w := do(http.MethodPost, base+"/archive", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/users/test-user/sessions", ""); !strings.Contains(w.Body.String(), `"sessions":[]`) {
+		t.Fatalf("expected archived sessions left out, got %s", w.Body.String())
+	}
+	if w := do(http.MethodGet, "/users/test-user/sessions?status=deleted", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown status, got %d", w.Code)
+	}
+
+	if w := do(http.MethodPost, base+"/resume", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"active"`) {
+		t.Fatalf("expected the session resumed, got %d, body=%s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, base+"/messages", message); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 after resume, got %d, body=%s", w.Code, w.Body.String())
+	}
+}
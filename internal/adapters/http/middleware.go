@@ -1,9 +1,12 @@
 package httpadapter
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"time"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
 )
 
 // withLogging wraps a handle and logs every request.
@@ -23,7 +26,7 @@ func withCORS(next http.Handler) http.Handler {
 		// In the MVP we leave everything open.
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
@@ -34,6 +37,33 @@ func withCORS(next http.Handler) http.Handler {
 	})
 }
 
+// withAuth rejects the requests without valid credentials (401) and stores
+// the authenticated user in the context of the others. The health check
+// stays public.
+func withAuth(auth *Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/healthz" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID, err := auth.Authenticate(r)
+			if err != nil {
+				log.Printf("%s %s: authentication failed: %v", r.Method, r.URL.Path, err)
+				msg := errInvalidCredentials.Error()
+				if errors.Is(err, errNoCredentials) {
+					msg = errNoCredentials.Error()
+				}
+				unauthorized(w, msg)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(domain.WithSubject(r.Context(), userID)))
+		})
+	}
+}
+
 // chainMiddlewares applies multiple middlewares in order.
 func chainMiddlewares(h http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
 	for _, m := range middlewares {
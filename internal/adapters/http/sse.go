@@ -0,0 +1,53 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sseWriteTimeout is how long each event has to be written.
+// The deadline is extended on every event, so long streams are not cut
+// by the server's global WriteTimeout.
+const sseWriteTimeout = 30 * time.Second
+
+// sseWriter writes Server-Sent Events to a ResponseWriter.
+type sseWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// newSSEWriter sends the SSE headers. It fails if the writer cannot flush.
+func newSSEWriter(w http.ResponseWriter) (*sseWriter, error) {
+	if _, ok := w.(http.Flusher); !ok {
+		return nil, errors.New("streaming not supported")
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+
+	s := &sseWriter{w: w, rc: http.NewResponseController(w)}
+	_ = s.rc.Flush()
+	return s, nil
+}
+
+// send writes one event with a JSON-encoded payload and flushes it.
+func (s *sseWriter) send(event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_ = s.rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
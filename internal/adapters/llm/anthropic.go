@@ -0,0 +1,348 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+const (
+	defaultAnthropicBaseURL   = "https://api.anthropic.com"
+	defaultAnthropicVersion   = "2023-06-01"
+	defaultAnthropicMaxTokens = 1024
+)
+
+// AnthropicConfig configures a client for the Anthropic Messages API.
+type AnthropicConfig struct {
+	BaseURL     string // defaults to https://api.anthropic.com
+	APIKey      string
+	ModelName   string
+	Temperature float64
+	MaxTokens   int    // required by the API; 0 = 1024
+	Version     string // anthropic-version header; "" = 2023-06-01
+
+	HTTPClient *http.Client // optional, defaults to a client with a 2 minute timeout
+}
+
+// AnthropicClient implements domain.LLMClient, domain.StreamingLLMClient and
+// domain.ToolCallingLLMClient over the Messages API.
+type AnthropicClient struct {
+	cfg  AnthropicConfig
+	http *http.Client
+}
+
+// NewAnthropicClient creates an LLMClient backed by the Anthropic Messages API.
+func NewAnthropicClient(cfg AnthropicConfig) (*AnthropicClient, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("AnthropicConfig.APIKey must be set")
+	}
+	if cfg.ModelName == "" {
+		return nil, fmt.Errorf("AnthropicConfig.ModelName must be set")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultAnthropicBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = defaultAnthropicMaxTokens
+	}
+	if cfg.Version == "" {
+		cfg.Version = defaultAnthropicVersion
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 2 * time.Minute}
+	}
+
+	return &AnthropicClient{cfg: cfg, http: httpClient}, nil
+}
+
+// anthropicBlock is a content block; only the fields of its Type are set.
+type anthropicBlock struct {
+	Type string `json:"type"`
+
+	// text
+	Text string `json:"text,omitempty"`
+
+	// tool_use
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Input any    `json:"input,omitempty"` // object; must be sent even when empty
+
+	// tool_result
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+	IsError   bool   `json:"is_error,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []anthropicBlock `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float64            `json:"temperature"`
+	Stream      bool               `json:"stream,omitempty"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+}
+
+type anthropicResponse struct {
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+}
+
+// GenerateReply implements domain.LLMClient.
+func (c *AnthropicClient) GenerateReply(
+	ctx context.Context,
+	userMessage string,
+	convCtx domain.ConversationContext,
+) (string, error) {
+	turn, err := c.GenerateWithTools(ctx, userMessage, convCtx, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	if turn.Text == "" {
+		return "", fmt.Errorf("anthropic returned empty text")
+	}
+	return turn.Text, nil
+}
+
+// GenerateWithTools implements domain.ToolCallingLLMClient: tool_use blocks
+// become ToolCalls and previous exchanges are sent back as tool_result blocks.
+func (c *AnthropicClient) GenerateWithTools(
+	ctx context.Context,
+	userMessage string,
+	convCtx domain.ConversationContext,
+	tools []domain.ToolSpec,
+	exchanges []domain.ToolExchange,
+) (domain.ToolTurn, error) {
+	req := c.buildRequest(userMessage, convCtx, false)
+	req.Messages = appendToolExchanges(req.Messages, exchanges)
+	for _, t := range tools {
+		schema := t.Parameters
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+		req.Tools = append(req.Tools, anthropicTool{
+			Name:        t.Name,
+			Description: t.Description,
+			InputSchema: schema,
+		})
+	}
+
+	res, err := c.post(ctx, req)
+	if err != nil {
+		return domain.ToolTurn{}, err
+	}
+	defer res.Body.Close()
+
+	var out anthropicResponse
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return domain.ToolTurn{}, fmt.Errorf("anthropic: decoding response: %w", err)
+	}
+
+	var turn domain.ToolTurn
+	var text strings.Builder
+	for _, b := range out.Content {
+		switch b.Type {
+		case "text":
+			text.WriteString(b.Text)
+		case "tool_use":
+			args, _ := b.Input.(map[string]any)
+			turn.Calls = append(turn.Calls, domain.ToolCall{ID: b.ID, Name: b.Name, Args: args})
+		}
+	}
+	turn.Text = text.String()
+
+	return turn, nil
+}
+
+// GenerateReplyStream implements domain.StreamingLLMClient (text_delta events).
+func (c *AnthropicClient) GenerateReplyStream(
+	ctx context.Context,
+	userMessage string,
+	convCtx domain.ConversationContext,
+	onChunk func(chunk string) error,
+) (string, error) {
+	res, err := c.post(ctx, c.buildRequest(userMessage, convCtx, true))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var full strings.Builder
+	err = readSSE(res.Body, func(event, data string) error {
+		var payload struct {
+			Type  string `json:"type"`
+			Delta struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"delta"`
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &payload); err != nil {
+			return fmt.Errorf("anthropic: decoding stream event: %w", err)
+		}
+
+		switch payload.Type {
+		case "message_stop":
+			return errStopSSE
+		case "error":
+			// Errors after the 200 OK come as events (e.g. overloaded_error)
+			return &APIError{
+				Provider:   "anthropic",
+				StatusCode: anthropicStreamErrorStatus(payload.Error.Type),
+				Message:    payload.Error.Message,
+			}
+		case "content_block_delta":
+			if payload.Delta.Type != "text_delta" || payload.Delta.Text == "" {
+				return nil
+			}
+			full.WriteString(payload.Delta.Text)
+			return onChunk(payload.Delta.Text)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if full.Len() == 0 {
+		return "", fmt.Errorf("anthropic returned empty text")
+	}
+	return full.String(), nil
+}
+
+func (c *AnthropicClient) post(ctx context.Context, body anthropicRequest) (*http.Response, error) {
+	headers := map[string]string{
+		"x-api-key":         c.cfg.APIKey,
+		"anthropic-version": c.cfg.Version,
+	}
+	if body.Stream {
+		headers["Accept"] = "text/event-stream"
+	}
+	return postJSON(ctx, c.http, "anthropic", c.cfg.BaseURL+"/v1/messages", headers, body)
+}
+
+// buildRequest puts the system prompt in "system" and maps the history to
+// alternating user/assistant turns. Consecutive messages of the same role are
+// merged, and agent messages before the first user message (e.g. the welcome
+// message) go to the system prompt, since the conversation must start with "user".
+func (c *AnthropicClient) buildRequest(
+	userMessage string,
+	convCtx domain.ConversationContext,
+	stream bool,
+) anthropicRequest {
+	system := systemPromptFor(convCtx)
+
+	var messages []anthropicMessage
+	var opening []string
+	add := func(role, text string) {
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		if len(messages) == 0 && role == "assistant" {
+			opening = append(opening, text)
+			return
+		}
+		if n := len(messages); n > 0 && messages[n-1].Role == role {
+			last := &messages[n-1].Content[0]
+			last.Text += "\n\n" + text
+			return
+		}
+		messages = append(messages, anthropicMessage{
+			Role:    role,
+			Content: []anthropicBlock{{Type: "text", Text: text}},
+		})
+	}
+
+	for _, m := range convCtx.History {
+		role := "user"
+		if m.Author == domain.RoleAgent {
+			role = "assistant"
+		}
+		add(role, m.Text)
+	}
+	add("user", userMessage)
+
+	if len(opening) > 0 {
+		system += "\nYou opened the conversation with:\n" + strings.Join(opening, "\n\n")
+	}
+
+	return anthropicRequest{
+		Model:       c.cfg.ModelName,
+		System:      system,
+		Messages:    messages,
+		MaxTokens:   c.cfg.MaxTokens,
+		Temperature: c.cfg.Temperature,
+		Stream:      stream,
+	}
+}
+
+// appendToolExchanges adds, for each exchange, the assistant tool_use turn
+// and the user turn with the matching tool_result blocks.
+func appendToolExchanges(messages []anthropicMessage, exchanges []domain.ToolExchange) []anthropicMessage {
+	for _, ex := range exchanges {
+		var calls []anthropicBlock
+		if ex.Text != "" {
+			calls = append(calls, anthropicBlock{Type: "text", Text: ex.Text})
+		}
+		for _, call := range ex.Calls {
+			input := call.Args
+			if input == nil {
+				input = map[string]any{}
+			}
+			calls = append(calls, anthropicBlock{Type: "tool_use", ID: call.ID, Name: call.Name, Input: input})
+		}
+
+		var results []anthropicBlock
+		for _, r := range ex.Results {
+			results = append(results, anthropicBlock{
+				Type:      "tool_result",
+				ToolUseID: r.CallID,
+				Content:   r.Content,
+				IsError:   r.IsError,
+			})
+		}
+
+		messages = append(messages,
+			anthropicMessage{Role: "assistant", Content: calls},
+			anthropicMessage{Role: "user", Content: results},
+		)
+	}
+	return messages
+}
+
+// anthropicStreamErrorStatus maps error event types to the HTTP status the
+// API would use, so APIError.Temporary works for streams too.
+func anthropicStreamErrorStatus(errType string) int {
+	switch errType {
+	case "overloaded_error":
+		return 529
+	case "rate_limit_error":
+		return http.StatusTooManyRequests
+	case "api_error":
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,207 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/llm"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+type messagesRequest struct {
+	Model     string `json:"model"`
+	System    string `json:"system"`
+	MaxTokens int    `json:"max_tokens"`
+	Stream    bool   `json:"stream"`
+	Messages  []struct {
+		Role    string           `json:"role"`
+		Content []map[string]any `json:"content"`
+	} `json:"messages"`
+	Tools []struct {
+		Name        string         `json:"name"`
+		InputSchema map[string]any `json:"input_schema"`
+	} `json:"tools"`
+}
+
+// newMessagesServer stands in for the Anthropic Messages API.
+// respond writes the answer for the decoded request.
+func newMessagesServer(t *testing.T, last *messagesRequest, respond func(w http.ResponseWriter, req *messagesRequest)) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("x-api-key") != "secret" || r.Header.Get("anthropic-version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"type": "error", "error": {"type": "authentication_error", "message": "invalid x-api-key"}}`)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(last); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		respond(w, last)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newAnthropicClient(t *testing.T, baseURL string) *llm.AnthropicClient {
+	t.Helper()
+
+	client, err := llm.NewAnthropicClient(llm.AnthropicConfig{
+		BaseURL:   baseURL,
+		APIKey:    "secret",
+		ModelName: "claude-test",
+	})
+	if err != nil {
+		t.Fatalf("NewAnthropicClient: %v", err)
+	}
+	return client
+}
+
+func TestAnthropicClientGenerateReply(t *testing.T) {
+	var req messagesRequest
+	srv := newMessagesServer(t, &req, func(w http.ResponseWriter, _ *messagesRequest) {
+		fmt.Fprint(w, `{"content": [{"type": "text", "text": "Te escucho."}], "stop_reason": "end_turn"}`)
+	})
+	client := newAnthropicClient(t, srv.URL)
+
+	convCtx := domain.ConversationContext{
+		Mode: domain.ModeCheckIn,
+		History: []*domain.Message{
+			{Author: domain.RoleAgent, Text: "Hola, soy Farum"},
+			{Author: domain.RoleUser, Text: "Estoy cansado"},
+			{Author: domain.RoleUser, Text: "y ansioso"},
+			{Author: domain.RoleAgent, Text: "Contame más"},
+		},
+	}
+
+	reply, err := client.GenerateReply(context.Background(), "Dormí mal", convCtx)
+	if err != nil {
+		t.Fatalf("GenerateReply: %v", err)
+	}
+	if reply != "Te escucho." {
+		t.Fatalf("unexpected reply %q", reply)
+	}
+
+	if req.Model != "claude-test" || req.MaxTokens != 1024 || req.Stream {
+		t.Fatalf("unexpected request options: %+v", req)
+	}
+	if !strings.Contains(req.System, `"Farum"`) || !strings.Contains(req.System, "Hola, soy Farum") {
+		t.Fatalf("expected system prompt with the opening agent message, got %q", req.System)
+	}
+
+	// The leading agent message moves to system; the two user messages are merged
+	var turns []string
+	for _, m := range req.Messages {
+		turns = append(turns, m.Role+":"+m.Content[0]["text"].(string))
+	}
+	want := []string{"user:Estoy cansado\n\ny ansioso", "assistant:Contame más", "user:Dormí mal"}
+	if strings.Join(turns, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected turns:\n got %q\nwant %q", turns, want)
+	}
+}
+
+func TestAnthropicClientStream(t *testing.T) {
+	var req messagesRequest
+	srv := newMessagesServer(t, &req, func(w http.ResponseWriter, _ *messagesRequest) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message_start\ndata: {\"type\": \"message_start\"}\n\n")
+		for _, tok := range []string{"Te ", "escucho", "."} {
+			fmt.Fprintf(w, "event: content_block_delta\ndata: {\"type\": \"content_block_delta\", \"delta\": {\"type\": \"text_delta\", \"text\": %q}}\n\n", tok)
+		}
+		fmt.Fprint(w, "event: message_stop\ndata: {\"type\": \"message_stop\"}\n\n")
+	})
+	client := newAnthropicClient(t, srv.URL)
+
+	var chunks []string
+	reply, err := client.GenerateReplyStream(context.Background(), "Hola", domain.ConversationContext{}, func(c string) error {
+		chunks = append(chunks, c)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateReplyStream: %v", err)
+	}
+	if !req.Stream || reply != "Te escucho." || len(chunks) != 3 {
+		t.Fatalf("unexpected stream: stream=%t reply %q chunks %q", req.Stream, reply, chunks)
+	}
+}
+
+func TestAnthropicClientStreamErrorEvent(t *testing.T) {
+	var req messagesRequest
+	srv := newMessagesServer(t, &req, func(w http.ResponseWriter, _ *messagesRequest) {
+		fmt.Fprint(w, "event: error\ndata: {\"type\": \"error\", \"error\": {\"type\": \"overloaded_error\", \"message\": \"Overloaded\"}}\n\n")
+	})
+	client := newAnthropicClient(t, srv.URL)
+
+	_, err := client.GenerateReplyStream(context.Background(), "Hola", domain.ConversationContext{}, func(string) error { return nil })
+
+	var apiErr *llm.APIError
+	if !errors.As(err, &apiErr) || !apiErr.Temporary() {
+		t.Fatalf("expected a temporary *llm.APIError, got %v", err)
+	}
+}
+
+func TestAnthropicClientToolUse(t *testing.T) {
+	var req messagesRequest
+	srv := newMessagesServer(t, &req, func(w http.ResponseWriter, req *messagesRequest) {
+		last := req.Messages[len(req.Messages)-1]
+		if last.Content[0]["type"] == "tool_result" {
+			fmt.Fprint(w, `{"content": [{"type": "text", "text": "Tenés 2 acciones pendientes."}], "stop_reason": "end_turn"}`)
+			return
+		}
+		fmt.Fprint(w, `{"content": [
+			{"type": "text", "text": "Reviso tu plan."},
+			{"type": "tool_use", "id": "toolu_1", "name": "action_tracker", "input": {"operation": "list_open"}}
+		], "stop_reason": "tool_use"}`)
+	})
+	client := newAnthropicClient(t, srv.URL)
+
+	tools := []domain.ToolSpec{{
+		Name:        "action_tracker",
+		Description: "Lists and updates the user's actions",
+		Parameters:  map[string]any{"type": "object", "properties": map[string]any{"operation": map[string]any{"type": "string"}}},
+	}}
+
+	turn, err := client.GenerateWithTools(context.Background(), "¿Qué me quedó pendiente?", domain.ConversationContext{}, tools, nil)
+	if err != nil {
+		t.Fatalf("GenerateWithTools: %v", err)
+	}
+	if len(req.Tools) != 1 || req.Tools[0].Name != "action_tracker" || req.Tools[0].InputSchema["type"] != "object" {
+		t.Fatalf("unexpected tools in request: %+v", req.Tools)
+	}
+	if len(turn.Calls) != 1 || turn.Calls[0].ID != "toolu_1" || turn.Calls[0].Args["operation"] != "list_open" {
+		t.Fatalf("unexpected tool calls: %+v", turn)
+	}
+
+	exchanges := []domain.ToolExchange{{
+		Text:    turn.Text,
+		Calls:   turn.Calls,
+		Results: []domain.ToolResult{{CallID: "toolu_1", Name: "action_tracker", Content: `{"count": 2}`}},
+	}}
+	final, err := client.GenerateWithTools(context.Background(), "¿Qué me quedó pendiente?", domain.ConversationContext{}, tools, exchanges)
+	if err != nil {
+		t.Fatalf("GenerateWithTools with results: %v", err)
+	}
+	if len(final.Calls) != 0 || final.Text != "Tenés 2 acciones pendientes." {
+		t.Fatalf("unexpected final turn: %+v", final)
+	}
+
+	// user, assistant (text + tool_use), user (tool_result)
+	if len(req.Messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(req.Messages))
+	}
+	toolUse := req.Messages[1].Content[1]
+	toolResult := req.Messages[2].Content[0]
+	if toolUse["type"] != "tool_use" || toolResult["tool_use_id"] != "toolu_1" || toolResult["content"] != `{"count": 2}` {
+		t.Fatalf("unexpected tool exchange: %+v / %+v", toolUse, toolResult)
+	}
+}
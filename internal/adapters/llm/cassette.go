@@ -0,0 +1,382 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// CassetteMode selects whether a CassetteLLM records or replays.
+type CassetteMode string
+
+const (
+	CassetteRecord CassetteMode = "record"
+	CassetteReplay CassetteMode = "replay"
+)
+
+// ErrCassetteMiss is returned in replay mode when no recorded response matches the call.
+var ErrCassetteMiss = errors.New("cassette: no recorded response for this call")
+
+// CassetteMessage is the part of a history message that identifies a call.
+type CassetteMessage struct {
+	Author string `json:"author"`
+	Text   string `json:"text"`
+}
+
+// Interaction is one recorded LLM call.
+type Interaction struct {
+	Key      string            `json:"key"`
+	Prompt   string            `json:"prompt"`
+	Mode     string            `json:"mode"`
+	History  []CassetteMessage `json:"history,omitempty"`
+	Response string            `json:"response"`
+
+	// Set on tool-calling calls: the agent that made it, the declared tools
+	// and the model's answer
+	Agent    string           `json:"agent,omitempty"`
+	Tools    []string         `json:"tools,omitempty"`
+	ToolTurn *domain.ToolTurn `json:"tool_turn,omitempty"`
+}
+
+// cassetteFile is the on-disk format.
+type cassetteFile struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// CassetteLLM is a domain.LLMClient decorator that records calls to a JSON
+// "cassette" file, or replays them by a hash of prompt, mode and history.
+// Calls with the same key are replayed in the order they were recorded.
+type CassetteLLM struct {
+	mode  CassetteMode
+	path  string
+	inner domain.LLMClient // only used when recording
+
+	mu           sync.Mutex
+	interactions []Interaction
+	byKey        map[string][]int // key -> indexes into interactions
+	played       map[string]int   // key -> how many times it was replayed
+	toolAgents   map[string]bool  // agents with recorded tool-calling calls
+}
+
+// NewRecordingLLM wraps inner and appends every call to the cassette at path.
+// An existing cassette is kept and extended.
+func NewRecordingLLM(inner domain.LLMClient, path string) (*CassetteLLM, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("cassette: recording needs an inner LLM client")
+	}
+
+	c := newCassetteLLM(CassetteRecord, path)
+	c.inner = inner
+
+	if _, err := os.Stat(path); err == nil {
+		if err := c.load(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// NewReplayLLM serves the calls recorded in the cassette at path, without network access.
+func NewReplayLLM(path string) (*CassetteLLM, error) {
+	c := newCassetteLLM(CassetteReplay, path)
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func newCassetteLLM(mode CassetteMode, path string) *CassetteLLM {
+	return &CassetteLLM{
+		mode:       mode,
+		path:       path,
+		byKey:      make(map[string][]int),
+		played:     make(map[string]int),
+		toolAgents: make(map[string]bool),
+	}
+}
+
+// GenerateReply implements domain.LLMClient.
+func (c *CassetteLLM) GenerateReply(
+	ctx context.Context,
+	prompt string,
+	convCtx domain.ConversationContext,
+) (string, error) {
+	key := CassetteKey(prompt, convCtx)
+
+	if c.mode == CassetteReplay {
+		it, err := c.replay(key, prompt)
+		return it.Response, err
+	}
+
+	reply, err := c.inner.GenerateReply(ctx, prompt, convCtx)
+	if err != nil {
+		return "", err
+	}
+	it := newInteraction(key, prompt, convCtx)
+	it.Response = reply
+	if err := c.record(it); err != nil {
+		return "", err
+	}
+	return reply, nil
+}
+
+// GenerateReplyStream implements domain.StreamingLLMClient.
+// Recording streams from the inner client when it supports it; replay
+// delivers the recorded reply word by word.
+func (c *CassetteLLM) GenerateReplyStream(
+	ctx context.Context,
+	prompt string,
+	convCtx domain.ConversationContext,
+	onChunk func(chunk string) error,
+) (string, error) {
+	key := CassetteKey(prompt, convCtx)
+
+	if c.mode == CassetteRecord {
+		streamer, ok := c.inner.(domain.StreamingLLMClient)
+		if !ok {
+			reply, err := c.GenerateReply(ctx, prompt, convCtx)
+			if err != nil {
+				return "", err
+			}
+			return reply, onChunk(reply)
+		}
+
+		reply, err := streamer.GenerateReplyStream(ctx, prompt, convCtx, onChunk)
+		if err != nil {
+			return "", err
+		}
+		it := newInteraction(key, prompt, convCtx)
+		it.Response = reply
+		return reply, c.record(it)
+	}
+
+	it, err := c.replay(key, prompt)
+	if err != nil {
+		return "", err
+	}
+	for _, chunk := range strings.SplitAfter(it.Response, " ") {
+		if err := onChunk(chunk); err != nil {
+			return "", err
+		}
+	}
+	return it.Response, nil
+}
+
+// GenerateWithTools implements domain.ToolCallingLLMClient. Recording needs
+// an inner client with tool calling; the key also covers the declared tools
+// and the calls made so far in the turn.
+func (c *CassetteLLM) GenerateWithTools(
+	ctx context.Context,
+	prompt string,
+	convCtx domain.ConversationContext,
+	tools []domain.ToolSpec,
+	exchanges []domain.ToolExchange,
+) (domain.ToolTurn, error) {
+	key := cassetteToolKey(prompt, convCtx, tools, exchanges)
+
+	if c.mode == CassetteReplay {
+		it, err := c.replay(key, prompt)
+		if err != nil {
+			return domain.ToolTurn{}, err
+		}
+		if it.ToolTurn == nil {
+			return domain.ToolTurn{Text: it.Response}, nil
+		}
+		return *it.ToolTurn, nil
+	}
+
+	caller, ok := c.inner.(domain.ToolCallingLLMClient)
+	if !ok {
+		return domain.ToolTurn{}, errors.New("cassette: the inner LLM client does not support tool calling")
+	}
+	turn, err := caller.GenerateWithTools(ctx, prompt, convCtx, tools, exchanges)
+	if err != nil {
+		return domain.ToolTurn{}, err
+	}
+
+	it := newInteraction(key, prompt, convCtx)
+	it.Response = turn.Text
+	it.Agent = observability.AgentFromContext(ctx)
+	for _, t := range tools {
+		it.Tools = append(it.Tools, t.Name)
+	}
+	it.ToolTurn = &turn
+	return turn, c.record(it)
+}
+
+// SupportsTools implements domain.ToolSupport. Recording asks the inner
+// client; replay offers tools to the agents that used them while recording,
+// so the other calls keep their recorded plain prompts.
+func (c *CassetteLLM) SupportsTools(ctx context.Context) bool {
+	if c.mode == CassetteRecord {
+		return domain.SupportsTools(ctx, c.inner)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.toolAgents[observability.AgentFromContext(ctx)]
+}
+
+// CassetteKey identifies a call by prompt, mode, history (author + text),
+// summary and recalled memories (kind + text without dates). Volatile data
+// (IDs, timestamps, scores) is left out so replays are deterministic.
+func CassetteKey(prompt string, convCtx domain.ConversationContext) string {
+	h := sha256.New()
+	writeField := func(s string) {
+		fmt.Fprintf(h, "%d:%s|", len(s), s)
+	}
+
+	writeField(prompt)
+	writeField(string(convCtx.Mode))
+	for _, m := range convCtx.History {
+		writeField(string(m.Author))
+		writeField(m.Text)
+	}
+	// Only when set, so keys recorded without summaries or memories still match
+	if convCtx.Summary != "" {
+		writeField(convCtx.Summary)
+	}
+	if len(convCtx.Memories) > 0 {
+		writeField("memories")
+		for _, m := range convCtx.Memories {
+			writeField(string(m.Kind))
+			writeField(memoryDateRe.ReplaceAllString(m.Text, ""))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// memoryDateRe matches the dates memories are rendered with (e.g.
+// "[2025-01-10] Past session: ..."), which depend on when the run happens.
+var memoryDateRe = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+
+// cassetteToolKey extends CassetteKey with the declared tools and the calls
+// of the previous exchanges. Tool results are left out: they hold volatile
+// data too (e.g. the ID of a new journal entry).
+func cassetteToolKey(prompt string, convCtx domain.ConversationContext, tools []domain.ToolSpec, exchanges []domain.ToolExchange) string {
+	h := sha256.New()
+	writeField := func(s string) {
+		fmt.Fprintf(h, "%d:%s|", len(s), s)
+	}
+
+	writeField(CassetteKey(prompt, convCtx))
+	for _, t := range tools {
+		writeField(t.Name)
+	}
+	for _, ex := range exchanges {
+		writeField(ex.Text)
+		for _, call := range ex.Calls {
+			args, _ := json.Marshal(call.Args) // map keys are sorted
+			writeField(call.Name)
+			writeField(string(args))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// --- internal helpers --- //
+
+func newInteraction(key, prompt string, convCtx domain.ConversationContext) Interaction {
+	history := make([]CassetteMessage, 0, len(convCtx.History))
+	for _, m := range convCtx.History {
+		history = append(history, CassetteMessage{Author: string(m.Author), Text: m.Text})
+	}
+	return Interaction{
+		Key:     key,
+		Prompt:  prompt,
+		Mode:    string(convCtx.Mode),
+		History: history,
+	}
+}
+
+func (c *CassetteLLM) replay(key, prompt string) (Interaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	idxs := c.byKey[key]
+	if len(idxs) == 0 {
+		return Interaction{}, fmt.Errorf("%w (key %s, prompt %q)", ErrCassetteMiss, key[:12], truncate(prompt, 80))
+	}
+
+	// Same call recorded several times: replay in order, then repeat the last one
+	n := c.played[key]
+	c.played[key] = n + 1
+	if n >= len(idxs) {
+		n = len(idxs) - 1
+	}
+	return c.interactions[idxs[n]], nil
+}
+
+func (c *CassetteLLM) record(it Interaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.interactions = append(c.interactions, it)
+	c.byKey[it.Key] = append(c.byKey[it.Key], len(c.interactions)-1)
+
+	// Saved after every call so an interrupted run still leaves a usable cassette
+	return c.save()
+}
+
+func (c *CassetteLLM) load() error {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return fmt.Errorf("cassette: reading %s: %w", c.path, err)
+	}
+
+	var f cassetteFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("cassette: decoding %s: %w", c.path, err)
+	}
+
+	c.interactions = f.Interactions
+	for i, it := range c.interactions {
+		c.byKey[it.Key] = append(c.byKey[it.Key], i)
+		if it.ToolTurn != nil {
+			c.toolAgents[it.Agent] = true
+		}
+	}
+	return nil
+}
+
+// save writes the cassette atomically. Callers must hold c.mu.
+func (c *CassetteLLM) save() error {
+	data, err := json.MarshalIndent(cassetteFile{Interactions: c.interactions}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cassette: encoding: %w", err)
+	}
+
+	if dir := filepath.Dir(c.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("cassette: %w", err)
+		}
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("cassette: writing %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	return nil
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
@@ -0,0 +1,185 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/llm"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// countingLLM returns "<prompt> #<n>" so repeated calls can be told apart.
+type countingLLM struct {
+	calls int
+}
+
+func (c *countingLLM) GenerateReply(ctx context.Context, prompt string, convCtx domain.ConversationContext) (string, error) {
+	c.calls++
+	return fmt.Sprintf("%s #%d", prompt, c.calls), nil
+}
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cassettes", "test.json")
+
+	convCtx := domain.ConversationContext{
+		Mode: domain.ModeCheckIn,
+		History: []*domain.Message{
+			{Author: domain.RoleUser, Text: "Hola"},
+		},
+	}
+
+	inner := &countingLLM{}
+	rec, err := llm.NewRecordingLLM(inner, path)
+	if err != nil {
+		t.Fatalf("NewRecordingLLM: %v", err)
+	}
+
+	var recorded []string
+	for _, prompt := range []string{"a", "a", "b"} {
+		reply, err := rec.GenerateReply(ctx, prompt, convCtx)
+		if err != nil {
+			t.Fatalf("record %q: %v", prompt, err)
+		}
+		recorded = append(recorded, reply)
+	}
+
+	replay, err := llm.NewReplayLLM(path)
+	if err != nil {
+		t.Fatalf("NewReplayLLM: %v", err)
+	}
+
+	// Same key twice: replayed in recording order
+	for i, prompt := range []string{"a", "a", "b"} {
+		reply, err := replay.GenerateReply(ctx, prompt, convCtx)
+		if err != nil {
+			t.Fatalf("replay %q: %v", prompt, err)
+		}
+		if reply != recorded[i] {
+			t.Fatalf("replay %d: got %q, want %q", i, reply, recorded[i])
+		}
+	}
+	if inner.calls != 3 {
+		t.Fatalf("expected the inner client to be called only while recording, got %d calls", inner.calls)
+	}
+
+	// Different history → different key → miss
+	other := convCtx
+	other.History = []*domain.Message{{Author: domain.RoleUser, Text: "Chau"}}
+	if _, err := replay.GenerateReply(ctx, "a", other); !errors.Is(err, llm.ErrCassetteMiss) {
+		t.Fatalf("expected ErrCassetteMiss, got %v", err)
+	}
+}
+
+func TestCassetteReplayStream(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "stream.json")
+
+	rec, err := llm.NewRecordingLLM(llm.NewMockLLM(), path)
+	if err != nil {
+		t.Fatalf("NewRecordingLLM: %v", err)
+	}
+	want, err := rec.GenerateReplyStream(ctx, "hola", domain.ConversationContext{}, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("record stream: %v", err)
+	}
+
+	replay, err := llm.NewReplayLLM(path)
+	if err != nil {
+		t.Fatalf("NewReplayLLM: %v", err)
+	}
+
+	var chunks []string
+	got, err := replay.GenerateReplyStream(ctx, "hola", domain.ConversationContext{}, func(c string) error {
+		chunks = append(chunks, c)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("replay stream: %v", err)
+	}
+	if got != want || strings.Join(chunks, "") != want {
+		t.Fatalf("replayed stream mismatch: got %q (chunks %q), want %q", got, chunks, want)
+	}
+}
+
+func TestCassetteKeyIgnoresVolatileFields(t *testing.T) {
+	a := domain.ConversationContext{
+		SessionID: "s1",
+		History:   []*domain.Message{{ID: "m1", Author: domain.RoleUser, Text: "Hola"}},
+	}
+	b := domain.ConversationContext{
+		SessionID: "s2",
+		History:   []*domain.Message{{ID: "m2", Author: domain.RoleUser, Text: "Hola"}},
+	}
+
+	if llm.CassetteKey("p", a) != llm.CassetteKey("p", b) {
+		t.Fatalf("expected IDs to be ignored by the cassette key")
+	}
+	if llm.CassetteKey("p", a) == llm.CassetteKey("q", a) {
+		t.Fatalf("expected different prompts to give different keys")
+	}
+
+	// Memories end up in the system prompt, so they are part of the call
+	withMemory := a
+	withMemory.Memories = []domain.Memory{{Kind: domain.MemoryJournal, Text: "Duerme mal desde marzo", Score: 0.9}}
+	if llm.CassetteKey("p", a) == llm.CassetteKey("p", withMemory) {
+		t.Fatalf("expected memories to change the cassette key")
+	}
+
+	// The dates they are rendered with depend on the day of the run
+	recorded, replayed := a, a
+	recorded.Memories = []domain.Memory{{Kind: domain.MemoryJournal, Text: "[2025-01-10] Past session: duerme mal"}}
+	replayed.Memories = []domain.Memory{{Kind: domain.MemoryJournal, Text: "[2025-01-11] Past session: duerme mal"}}
+	if llm.CassetteKey("p", recorded) != llm.CassetteKey("p", replayed) {
+		t.Fatalf("expected memory dates to be ignored by the cassette key")
+	}
+}
+
+func TestCassetteRecordsToolCalls(t *testing.T) {
+	ctx := observability.WithAgent(context.Background(), "reflector")
+	path := filepath.Join(t.TempDir(), "tools.json")
+	specs := []domain.ToolSpec{{Name: "journal_store"}}
+	call := domain.ToolTurn{Calls: []domain.ToolCall{{ID: "c1", Name: "journal_store", Args: map[string]any{"reflection": "hola"}}}}
+
+	mock := llm.NewMockLLM()
+	mock.ScriptToolTurns(call, domain.ToolTurn{Text: "Guardado."})
+	rec, err := llm.NewRecordingLLM(mock, path)
+	if err != nil {
+		t.Fatalf("NewRecordingLLM: %v", err)
+	}
+	if !domain.SupportsTools(ctx, rec) {
+		t.Fatalf("expected a recorder over a tool-calling client to support tools")
+	}
+
+	first, err := rec.GenerateWithTools(ctx, "p", domain.ConversationContext{}, specs, nil)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	// The result is not part of the key: it may hold a new entry ID
+	exchanges := []domain.ToolExchange{{Calls: first.Calls, Results: []domain.ToolResult{{CallID: "c1", Content: `{"entry_id":"1"}`}}}}
+	if _, err := rec.GenerateWithTools(ctx, "p", domain.ConversationContext{}, specs, exchanges); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	replay, err := llm.NewReplayLLM(path)
+	if err != nil {
+		t.Fatalf("NewReplayLLM: %v", err)
+	}
+	if !domain.SupportsTools(ctx, replay) || domain.SupportsTools(observability.WithAgent(ctx, "planner"), replay) {
+		t.Fatalf("expected tools to be offered only to the agent that used them")
+	}
+
+	turn, err := replay.GenerateWithTools(ctx, "p", domain.ConversationContext{}, specs, nil)
+	if err != nil || len(turn.Calls) != 1 || turn.Calls[0].Args["reflection"] != "hola" {
+		t.Fatalf("expected the recorded tool call, got %+v, %v", turn, err)
+	}
+	exchanges[0].Results[0].Content = `{"entry_id":"2"}`
+	if turn, err := replay.GenerateWithTools(ctx, "p", domain.ConversationContext{}, specs, exchanges); err != nil || turn.Text != "Guardado." {
+		t.Fatalf("expected the recorded final text, got %+v, %v", turn, err)
+	}
+}
@@ -0,0 +1,145 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// APIError is a non-2xx answer from an HTTP LLM provider.
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: HTTP %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// Temporary reports whether retrying the same request may succeed
+// (rate limits, overload and server errors).
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode >= 500
+}
+
+// postJSON sends body as JSON and returns the response when the status is 2xx.
+// The caller must close the body.
+func postJSON(
+	ctx context.Context,
+	client *http.Client,
+	provider string,
+	url string,
+	headers map[string]string,
+	body any,
+) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: encoding request: %w", provider, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", provider, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", provider, err)
+	}
+
+	if res.StatusCode/100 != 2 {
+		defer res.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return nil, &APIError{
+			Provider:   provider,
+			StatusCode: res.StatusCode,
+			Message:    apiErrorMessage(data),
+		}
+	}
+	return res, nil
+}
+
+// apiErrorMessage extracts {"error": {"message": ...}} (OpenAI and Anthropic
+// shape) or {"error": "..."} (Ollama), falling back to the raw body.
+func apiErrorMessage(data []byte) string {
+	var withObject struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &withObject) == nil && withObject.Error.Message != "" {
+		return withObject.Error.Message
+	}
+
+	var withString struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &withString) == nil && withString.Error != "" {
+		return withString.Error
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+// readSSE calls onEvent for each server-sent event in r, with the event name
+// ("" when absent) and its data. It stops at EOF or when onEvent returns
+// errStopSSE (returning nil) or any other error.
+func readSSE(r io.Reader, onEvent func(event, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var event string
+	var data []string
+	flush := func() error {
+		if len(data) == 0 {
+			event = ""
+			return nil
+		}
+		err := onEvent(event, strings.Join(data, "\n"))
+		event, data = "", nil
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := flush(); err != nil {
+				return stopIsNil(err)
+			}
+		case strings.HasPrefix(line, ":"):
+			// comment / keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return stopIsNil(flush())
+}
+
+// errStopSSE ends readSSE without error (e.g. on "data: [DONE]").
+var errStopSSE = errors.New("stop reading SSE stream")
+
+func stopIsNil(err error) error {
+	if errors.Is(err, errStopSSE) {
+		return nil
+	}
+	return err
+}
@@ -2,18 +2,597 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/PabloGalante/farum-agent/internal/domain"
 )
 
-type MockLLM struct{}
+// MockLLM is a free, offline LLMClient for local runs and tests.
+// It recognizes which agent is calling from the prompt and answers with
+// templated, role-appropriate text in the user's language (es/en/pt),
+// varying by ConversationContext.Mode. The template variant is chosen from a
+// hash of the seed and the user message, so the same input always gives the
+// same output.
+type MockLLM struct {
+	seed uint64
+
+	mu     sync.Mutex
+	script []domain.ToolTurn
+}
 
 func NewMockLLM() *MockLLM {
 	return &MockLLM{}
 }
 
+// NewSeededMockLLM creates a mock whose template choices depend on seed.
+func NewSeededMockLLM(seed uint64) *MockLLM {
+	return &MockLLM{seed: seed}
+}
+
 func (m *MockLLM) GenerateReply(ctx context.Context, prompt string, convCtx domain.ConversationContext) (string, error) {
-	// Here we could use minimun rules to give Farum some personality
-	return fmt.Sprintf("Te escucho. Dijiste %q. Contame un poco mas sobre ocmo te hace sentir eso", prompt), nil
+	userText := lastUserText(prompt, convCtx)
+	lang := mockLanguage(userText)
+	tpl := mockTemplates[lang]
+
+	mode := convCtx.Mode
+	if _, ok := tpl.listener[mode]; !ok {
+		mode = domain.ModeCheckIn
+	}
+
+	switch detectMockRole(prompt) {
+	case mockRoleListener:
+		return fmt.Sprintf(m.pick(tpl.listener[mode], "listener", userText), quote(userText)), nil
+
+	case mockRolePlanner:
+		return m.plan(tpl, mode, userText), nil
+
+	case mockRoleComposer:
+		return composedReply(sectionAfter(prompt, "Agent outputs:")), nil
+
+	case mockRoleExplorer:
+		return fmt.Sprintf(m.pick(tpl.explorer, "explorer", userText), quote(userText)), nil
+
+	case mockRoleReflector:
+		steps := numberedSteps(sectionAfter(prompt, "Previous agent output:"))
+		first := tpl.defaultStep
+		if len(steps) > 0 {
+			first = strings.ToLower(strings.TrimSuffix(steps[0], "."))
+		}
+		reflection := fmt.Sprintf(m.pick(tpl.reflector[mode], "reflector", userText), first)
+		return reflection + " " + m.pick(tpl.questions, "question", userText), nil
+
+	case mockRoleJournal:
+		return m.journalJSON(prompt, tpl, userText)
+
+	case mockRoleSafety:
+		return `{"risk": "none", "categories": [], "reason": "mock classifier"}`, nil
+
+	case mockRoleModeClassifier:
+		// Low confidence in the current mode: the heuristics decide
+		return fmt.Sprintf(`{"mode": %q, "confidence": 0.4, "reason": "mock classifier"}`, mode), nil
+
+	case mockRoleJudge:
+		return `{"score": 4, "reason": "mock judge"}`, nil
+
+	case mockRoleSummarizer:
+		return summaryReply(prompt), nil
+
+	default:
+		return fmt.Sprintf(m.pick(tpl.listener[domain.ModeCheckIn], "generic", userText), quote(userText)), nil
+	}
+}
+
+// ScriptToolTurns queues turns that GenerateWithTools returns, in order,
+// before falling back to its default behavior.
+func (m *MockLLM) ScriptToolTurns(turns ...domain.ToolTurn) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.script = append(m.script, turns...)
+}
+
+// GenerateWithTools implements domain.ToolCallingLLMClient. Scripted turns
+// come first. Otherwise the Reflector saves the session with journal_store
+// (when declared) before answering, and every other call answers with text.
+func (m *MockLLM) GenerateWithTools(
+	ctx context.Context,
+	prompt string,
+	convCtx domain.ConversationContext,
+	tools []domain.ToolSpec,
+	exchanges []domain.ToolExchange,
+) (domain.ToolTurn, error) {
+	m.mu.Lock()
+	if len(m.script) > 0 {
+		turn := m.script[0]
+		m.script = m.script[1:]
+		m.mu.Unlock()
+		return turn, nil
+	}
+	m.mu.Unlock()
+
+	reply, err := m.GenerateReply(ctx, prompt, convCtx)
+	if err != nil {
+		return domain.ToolTurn{}, err
+	}
+
+	declared := false
+	for _, t := range tools {
+		declared = declared || t.Name == "journal_store"
+	}
+	if !declared || len(exchanges) > 0 || detectMockRole(prompt) != mockRoleReflector {
+		return domain.ToolTurn{Text: reply}, nil
+	}
+
+	userText := lastUserText(prompt, convCtx)
+	args := m.journalFields(sectionAfter(prompt, "Previous agent output:"), mockTemplates[mockLanguage(userText)], userText)
+	args["reflection"] = reply
+
+	return domain.ToolTurn{
+		Calls: []domain.ToolCall{{ID: "mock-call-1", Name: "journal_store", Args: args}},
+	}, nil
+}
+
+// GenerateReplyStream fakes streaming by delivering the reply word by word.
+func (m *MockLLM) GenerateReplyStream(
+	ctx context.Context,
+	prompt string,
+	convCtx domain.ConversationContext,
+	onChunk func(chunk string) error,
+) (string, error) {
+	reply, err := m.GenerateReply(ctx, prompt, convCtx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, chunk := range strings.SplitAfter(reply, " ") {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if err := onChunk(chunk); err != nil {
+			return "", err
+		}
+	}
+	return reply, nil
+}
+
+// plan returns a numbered plan: 2 steps for check-ins, 3 for deep dives, 4 for action plans.
+func (m *MockLLM) plan(tpl mockLanguageTemplates, mode domain.InteractionMode, userText string) string {
+	n := map[domain.InteractionMode]int{
+		domain.ModeCheckIn:    2,
+		domain.ModeDeepDive:   3,
+		domain.ModeActionPlan: 4,
+	}[mode]
+
+	pool := tpl.steps
+	start := int(m.hash("planner", userText) % uint64(len(pool)))
+
+	var b strings.Builder
+	b.WriteString(tpl.planIntro)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "\n%d. %s", i+1, pool[(start+i)%len(pool)])
+	}
+	return b.String()
+}
+
+// journalJSON answers the journal extraction (and repair) prompts with valid JSON
+// built from the plan steps in the prompt.
+func (m *MockLLM) journalJSON(prompt string, tpl mockLanguageTemplates, userText string) (string, error) {
+	plan := sectionAfter(prompt, "Action plan:")
+	if i := strings.Index(plan, "Final reflection:"); i >= 0 {
+		plan = plan[:i]
+	}
+
+	out, err := json.Marshal(m.journalFields(plan, tpl, userText))
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// journalFields is the journal_store input for the plan, shaped like
+// decoded JSON so it can also be used as tool call arguments.
+func (m *MockLLM) journalFields(plan string, tpl mockLanguageTemplates, userText string) map[string]any {
+	actions := []any{}
+	for _, step := range numberedSteps(plan) {
+		if len(actions) == 4 {
+			break
+		}
+		actions = append(actions, map[string]any{
+			"description": step,
+			"status":      string(domain.ActionStatusPending),
+			"notes":       "",
+		})
+	}
+
+	summary := tpl.defaultSummary
+	if userText != "" {
+		summary = fmt.Sprintf(tpl.summary, truncate(userText, 120))
+	}
+
+	return map[string]any{
+		"problem_summary": summary,
+		"mood_before":     moodFor(tpl, userText),
+		"mood_after":      tpl.moodAfter,
+		"actions":         actions,
+	}
+}
+
+func (m *MockLLM) pick(variants []string, role, userText string) string {
+	return variants[m.hash(role, userText)%uint64(len(variants))]
+}
+
+func (m *MockLLM) hash(role, userText string) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d|%s|%s", m.seed, role, userText)
+	return h.Sum64()
+}
+
+// --- role and language detection --- //
+
+type mockRole int
+
+const (
+	mockRoleGeneric mockRole = iota
+	mockRoleListener
+	mockRolePlanner
+	mockRoleReflector
+	mockRoleExplorer
+	mockRoleComposer
+	mockRoleJournal
+	mockRoleSafety
+	mockRoleModeClassifier
+	mockRoleJudge
+	mockRoleSummarizer
+)
+
+func detectMockRole(prompt string) mockRole {
+	switch {
+	case strings.Contains(prompt, "Listener agent. Your job"):
+		return mockRoleListener
+	case strings.Contains(prompt, "Planner agent."):
+		return mockRolePlanner
+	case strings.Contains(prompt, "Reflector agent."):
+		return mockRoleReflector
+	case strings.Contains(prompt, "Explorer agent."):
+		return mockRoleExplorer
+	case strings.Contains(prompt, "Composer agent."):
+		return mockRoleComposer
+	case strings.Contains(prompt, "Journal extraction step"),
+		strings.Contains(prompt, `"problem_summary"`):
+		return mockRoleJournal
+	case strings.Contains(prompt, "safety classifier"):
+		return mockRoleSafety
+	case strings.Contains(prompt, "conversation summarizer"):
+		return mockRoleSummarizer
+	case strings.Contains(prompt, "mode classifier"):
+		return mockRoleModeClassifier
+	case strings.Contains(prompt, "You are evaluating a reply"):
+		return mockRoleJudge
+	default:
+		return mockRoleGeneric
+	}
+}
+
+// lastUserText returns the latest user message of the history, or the
+// "User message:" section of the prompt when there is no history.
+func lastUserText(prompt string, convCtx domain.ConversationContext) string {
+	for i := len(convCtx.History) - 1; i >= 0; i-- {
+		if m := convCtx.History[i]; m != nil && m.Author == domain.RoleUser {
+			return strings.TrimSpace(m.Text)
+		}
+	}
+	if text := sectionAfter(prompt, "User message:\n"); text != "" {
+		text, _, _ = strings.Cut(text, "\n\nPrevious agent output:")
+		return strings.TrimSpace(text)
+	}
+	return ""
+}
+
+var mockLanguageMarkers = map[string][]string{
+	"es": {"que", "estoy", "quiero", "muy", "pero", "con", "mi", "tengo", "hoy", "siento", "me", "por", "el", "la", "no"},
+	"en": {"i", "the", "and", "my", "i'm", "i've", "feel", "feeling", "want", "to", "is", "with", "today", "since"},
+	"pt": {"não", "nao", "eu", "estou", "minha", "você", "muito", "tenho", "hoje", "sinto", "com"},
+}
+
+// mockLanguage guesses "es", "en" or "pt" from stopwords; Spanish is the default.
+func mockLanguage(text string) string {
+	counts := map[string]int{}
+	for _, w := range strings.Fields(strings.ToLower(text)) {
+		w = strings.Trim(w, ".,;:!?¿¡\"()")
+		for lang, markers := range mockLanguageMarkers {
+			for _, marker := range markers {
+				if w == marker {
+					counts[lang]++
+				}
+			}
+		}
+	}
+
+	best := "es"
+	for _, lang := range []string{"en", "pt"} {
+		if counts[lang] > counts[best] {
+			best = lang
+		}
+	}
+	return best
+}
+
+// --- text helpers --- //
+
+var numberedStepRe = regexp.MustCompile(`(?m)^\s*\d+[.)]\s+(.+)$`)
+
+func numberedSteps(text string) []string {
+	var steps []string
+	for _, m := range numberedStepRe.FindAllStringSubmatch(text, -1) {
+		steps = append(steps, strings.TrimSpace(m[1]))
+	}
+	return steps
+}
+
+// composedReply joins the "### agent" sections of the composer prompt.
+func composedReply(outputs string) string {
+	var parts []string
+	for _, section := range strings.Split(outputs, "\n### ") {
+		if _, body, ok := strings.Cut(strings.TrimPrefix(strings.TrimSpace(section), "### "), "\n"); ok {
+			parts = append(parts, strings.TrimSpace(body))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// summaryReply extends the "Current summary:" of the summarizer prompt with
+// the user lines of its "New messages:".
+func summaryReply(prompt string) string {
+	current, messages, _ := strings.Cut(sectionAfter(prompt, "Current summary:"), "New messages:")
+
+	var said []string
+	for _, line := range strings.Split(messages, "\n") {
+		if text, ok := strings.CutPrefix(strings.TrimSpace(line), "user: "); ok {
+			said = append(said, fmt.Sprintf("%q", quote(text)))
+		}
+	}
+
+	summary := strings.TrimSpace(current)
+	if summary == "(none)" {
+		summary = ""
+	}
+	if len(said) > 0 {
+		summary = strings.TrimSpace(summary + " The user said: " + strings.Join(said, "; ") + ".")
+	}
+	return summary
+}
+
+func sectionAfter(prompt, marker string) string {
+	if i := strings.Index(prompt, marker); i >= 0 {
+		return prompt[i+len(marker):]
+	}
+	return ""
+}
+
+func quote(s string) string {
+	return truncate(strings.TrimRight(s, ".!?"), 120)
+}
+
+func moodFor(tpl mockLanguageTemplates, userText string) string {
+	lower := strings.ToLower(userText)
+	for _, m := range tpl.moods {
+		if strings.Contains(lower, m.keyword) {
+			return m.mood
+		}
+	}
+	return tpl.defaultMood
+}
+
+// --- templates --- //
+
+type mockMood struct {
+	keyword string
+	mood    string
+}
+
+type mockLanguageTemplates struct {
+	listener  map[domain.InteractionMode][]string // %s = user message
+	reflector map[domain.InteractionMode][]string // %s = first plan step
+	explorer  []string                            // %s = user message
+	questions []string
+
+	planIntro   string
+	steps       []string
+	defaultStep string
+
+	summary        string // %s = user message
+	defaultSummary string
+	moods          []mockMood
+	defaultMood    string
+	moodAfter      string
+}
+
+var mockTemplates = map[string]mockLanguageTemplates{
+	// Rioplatense Spanish, always "vos"
+	"es": {
+		listener: map[domain.InteractionMode][]string{
+			domain.ModeCheckIn: {
+				"Te escucho. Me contás que «%s», y tiene sentido que eso te pese.",
+				"Gracias por contármelo. Entiendo que «%s», y no es poca cosa.",
+			},
+			domain.ModeDeepDive: {
+				"Quiero entenderlo bien: «%s». Suena a algo que venís cargando hace un tiempo.",
+				"Lo que contás, «%s», parece tener varias capas. Vamos de a poco.",
+			},
+			domain.ModeActionPlan: {
+				"Entiendo: «%s». Pongamos eso en pasos concretos que puedas manejar.",
+				"Ok, el punto es «%s». Busquemos por dónde empezar.",
+			},
+		},
+		reflector: map[domain.InteractionMode][]string{
+			domain.ModeCheckIn: {
+				"Lo que sentís es válido, y darte un momento para nombrarlo ya es un paso. Después, podés probar con «%s».",
+				"No tenés que resolver todo hoy; alcanza con algo pequeño como «%s».",
+			},
+			domain.ModeDeepDive: {
+				"Mirar esto con calma lleva coraje. Empezar por «%s» puede darte un poco más de claridad.",
+				"Lo que fuiste descubriendo hoy cuenta. Con «%s» vas a poder observar qué cambia.",
+			},
+			domain.ModeActionPlan: {
+				"Ya tenés un plan claro. El primer paso, «%s», es chico a propósito.",
+				"Avanzar de a un paso está bien. Arrancar con «%s» te va a dar impulso.",
+			},
+		},
+		explorer: []string{
+			"Me quedo con «%s». ¿Cuándo lo notaste por primera vez? ¿Y qué pasa en tu cuerpo cuando aparece?",
+			"Exploremos «%s» un poco más. ¿Qué pensamientos vienen con eso? ¿Se parece a algo que ya viviste?",
+		},
+		questions: []string{
+			"¿Qué te gustaría anotar en tu diario sobre cómo te sentís ahora?",
+			"¿Cuál de estos pasos sentís más posible para esta semana?",
+			"¿Qué necesitarías para darte permiso de ir más despacio?",
+		},
+		planIntro: "Te propongo este plan:",
+		steps: []string{
+			"Tomate cinco minutos para respirar lento antes de empezar el día.",
+			"Escribí en una hoja qué te preocupa y qué parte depende de vos.",
+			"Elegí una sola tarea prioritaria y dividila en partes chicas.",
+			"Salí a caminar diez minutos sin el celular.",
+			"Hablá con alguien de confianza sobre lo que estás viviendo.",
+			"Antes de dormir, anotá una cosa que salió bien en el día.",
+		},
+		defaultStep:    "respirar un momento",
+		summary:        "La persona comparte: %s",
+		defaultSummary: "Conversación de registro emocional.",
+		moods: []mockMood{
+			{"ansios", "ansiedad"},
+			{"trist", "tristeza"},
+			{"solo", "soledad"},
+			{"sola", "soledad"},
+			{"enoj", "enojo"},
+			{"cansad", "cansancio"},
+			{"estres", "estrés"},
+		},
+		defaultMood: "inquietud",
+		moodAfter:   "más calma",
+	},
+	"en": {
+		listener: map[domain.InteractionMode][]string{
+			domain.ModeCheckIn: {
+				"I hear you. You're telling me that \"%s\", and it makes sense that it weighs on you.",
+				"Thank you for sharing this. I understand that \"%s\", and that's not a small thing.",
+			},
+			domain.ModeDeepDive: {
+				"I want to understand this well: \"%s\". It sounds like something you've been carrying for a while.",
+				"What you describe, \"%s\", seems to have several layers. Let's take it slowly.",
+			},
+			domain.ModeActionPlan: {
+				"Got it: \"%s\". Let's turn that into concrete steps you can manage.",
+				"Okay, the point is \"%s\". Let's find where to start.",
+			},
+		},
+		reflector: map[domain.InteractionMode][]string{
+			domain.ModeCheckIn: {
+				"What you feel is valid, and taking a moment to name it is already a step. Then you can try to %s.",
+				"You don't have to solve everything today; something small like \"%s\" is enough.",
+			},
+			domain.ModeDeepDive: {
+				"Looking at this calmly takes courage. Starting with \"%s\" can give you a bit more clarity.",
+				"What you discovered today matters. With \"%s\" you'll be able to notice what changes.",
+			},
+			domain.ModeActionPlan: {
+				"You now have a clear plan. The first step, \"%s\", is small on purpose.",
+				"Moving one step at a time is fine. Starting with \"%s\" will give you momentum.",
+			},
+		},
+		explorer: []string{
+			"Let's stay with \"%s\" for a moment. When did you first notice it? What happens in your body when it shows up?",
+			"I'd like to explore \"%s\" a bit more. What thoughts come with it? Does it remind you of something you've lived before?",
+		},
+		questions: []string{
+			"What would you like to write in your journal about how you feel right now?",
+			"Which of these steps feels most doable this week?",
+			"What would you need to give yourself permission to slow down?",
+		},
+		planIntro: "Here is a small plan:",
+		steps: []string{
+			"Take five minutes to breathe slowly before starting your day.",
+			"Write down what worries you and which part is in your control.",
+			"Pick a single priority task and split it into small parts.",
+			"Go for a ten-minute walk without your phone.",
+			"Talk to someone you trust about what you're going through.",
+			"Before going to bed, write down one thing that went well today.",
+		},
+		defaultStep:    "taking a breath",
+		summary:        "The user shares: %s",
+		defaultSummary: "Emotional check-in conversation.",
+		moods: []mockMood{
+			{"anxi", "anxious"},
+			{"sad", "sad"},
+			{"lonely", "lonely"},
+			{"angry", "angry"},
+			{"tired", "tired"},
+			{"stress", "stressed"},
+		},
+		defaultMood: "uneasy",
+		moodAfter:   "calmer",
+	},
+	"pt": {
+		listener: map[domain.InteractionMode][]string{
+			domain.ModeCheckIn: {
+				"Eu te escuto. Você me conta que \"%s\", e faz sentido que isso pese.",
+				"Obrigado por compartilhar. Entendo que \"%s\", e isso não é pouca coisa.",
+			},
+			domain.ModeDeepDive: {
+				"Quero entender bem: \"%s\". Parece algo que você carrega há algum tempo.",
+				"O que você conta, \"%s\", parece ter várias camadas. Vamos com calma.",
+			},
+			domain.ModeActionPlan: {
+				"Entendi: \"%s\". Vamos transformar isso em passos concretos.",
+				"Certo, o ponto é \"%s\". Vamos ver por onde começar.",
+			},
+		},
+		reflector: map[domain.InteractionMode][]string{
+			domain.ModeCheckIn: {
+				"O que você sente é válido, e parar para nomear isso já é um passo. Depois, experimente: \"%s\".",
+				"Você não precisa resolver tudo hoje; algo pequeno como \"%s\" já basta.",
+			},
+			domain.ModeDeepDive: {
+				"Olhar para isso com calma exige coragem. Começar por \"%s\" pode trazer mais clareza.",
+				"O que você descobriu hoje importa. Com \"%s\" você vai poder notar o que muda.",
+			},
+			domain.ModeActionPlan: {
+				"Agora você tem um plano claro. O primeiro passo, \"%s\", é pequeno de propósito.",
+				"Avançar um passo de cada vez está tudo bem. Começar com \"%s\" vai te dar impulso.",
+			},
+		},
+		explorer: []string{
+			"Vamos ficar com \"%s\" por um momento. Quando você percebeu isso pela primeira vez? O que acontece no seu corpo quando aparece?",
+			"Quero explorar \"%s\" um pouco mais. Que pensamentos vêm junto? Lembra algo que você já viveu?",
+		},
+		questions: []string{
+			"O que você gostaria de anotar no seu diário sobre como se sente agora?",
+			"Qual desses passos parece mais possível para esta semana?",
+			"Do que você precisaria para se permitir ir mais devagar?",
+		},
+		planIntro: "Proponho este plano:",
+		steps: []string{
+			"Tire cinco minutos para respirar devagar antes de começar o dia.",
+			"Escreva o que te preocupa e qual parte depende de você.",
+			"Escolha uma única tarefa prioritária e divida em partes pequenas.",
+			"Faça uma caminhada de dez minutos sem o celular.",
+			"Converse com alguém de confiança sobre o que está vivendo.",
+			"Antes de dormir, anote uma coisa que deu certo no dia.",
+		},
+		defaultStep:    "respirar um momento",
+		summary:        "A pessoa compartilha: %s",
+		defaultSummary: "Conversa de registro emocional.",
+		moods: []mockMood{
+			{"ansios", "ansiedade"},
+			{"trist", "tristeza"},
+			{"sozinh", "solidão"},
+			{"cansad", "cansaço"},
+		},
+		defaultMood: "inquietação",
+		moodAfter:   "mais calma",
+	},
 }
@@ -0,0 +1,182 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// OpenAIConfig configures a client for the OpenAI Chat Completions wire format.
+// The same API is served by OpenAI, Ollama (http://localhost:11434/v1),
+// vLLM and llama.cpp server, so BaseURL selects the provider.
+type OpenAIConfig struct {
+	BaseURL     string // e.g. https://api.openai.com/v1
+	APIKey      string // optional for local servers
+	ModelName   string
+	Temperature float64
+	MaxTokens   int // 0 = server default
+
+	HTTPClient *http.Client // optional, defaults to a client with a 2 minute timeout
+}
+
+// OpenAIClient implements domain.LLMClient and domain.StreamingLLMClient
+// over the Chat Completions API.
+type OpenAIClient struct {
+	cfg  OpenAIConfig
+	http *http.Client
+}
+
+// NewOpenAIClient creates an LLMClient for any OpenAI-compatible server.
+func NewOpenAIClient(cfg OpenAIConfig) (*OpenAIClient, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("OpenAIConfig.BaseURL must be set")
+	}
+	if cfg.ModelName == "" {
+		return nil, fmt.Errorf("OpenAIConfig.ModelName must be set")
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 2 * time.Minute}
+	}
+
+	return &OpenAIClient{cfg: cfg, http: httpClient}, nil
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Temperature float64         `json:"temperature"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+	Stream      bool            `json:"stream,omitempty"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+}
+
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+// GenerateReply implements domain.LLMClient.
+func (c *OpenAIClient) GenerateReply(
+	ctx context.Context,
+	userMessage string,
+	convCtx domain.ConversationContext,
+) (string, error) {
+	res, err := c.post(ctx, c.buildRequest(userMessage, convCtx, false))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var out openAIResponse
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("openai: decoding response: %w", err)
+	}
+	if len(out.Choices) == 0 || out.Choices[0].Message.Content == "" {
+		return "", fmt.Errorf("openai returned empty text")
+	}
+
+	return out.Choices[0].Message.Content, nil
+}
+
+// GenerateReplyStream implements domain.StreamingLLMClient ("stream": true, SSE deltas).
+func (c *OpenAIClient) GenerateReplyStream(
+	ctx context.Context,
+	userMessage string,
+	convCtx domain.ConversationContext,
+	onChunk func(chunk string) error,
+) (string, error) {
+	res, err := c.post(ctx, c.buildRequest(userMessage, convCtx, true))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var full strings.Builder
+	err = readSSE(res.Body, func(_, data string) error {
+		if data == "[DONE]" {
+			return errStopSSE
+		}
+
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("openai: decoding stream chunk: %w", err)
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			return nil
+		}
+
+		text := chunk.Choices[0].Delta.Content
+		full.WriteString(text)
+		return onChunk(text)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if full.Len() == 0 {
+		return "", fmt.Errorf("openai returned empty text")
+	}
+	return full.String(), nil
+}
+
+func (c *OpenAIClient) post(ctx context.Context, body openAIRequest) (*http.Response, error) {
+	headers := map[string]string{}
+	if c.cfg.APIKey != "" {
+		headers["Authorization"] = "Bearer " + c.cfg.APIKey
+	}
+	if body.Stream {
+		headers["Accept"] = "text/event-stream"
+	}
+	return postJSON(ctx, c.http, "openai", c.cfg.BaseURL+"/chat/completions", headers, body)
+}
+
+// buildRequest maps the conversation into chat messages:
+// system prompt, history (user / assistant) and the current message.
+func (c *OpenAIClient) buildRequest(
+	userMessage string,
+	convCtx domain.ConversationContext,
+	stream bool,
+) openAIRequest {
+	messages := []openAIMessage{
+		{Role: "system", Content: systemPromptFor(convCtx)},
+	}
+
+	for _, m := range convCtx.History {
+		role := "user"
+		if m.Author == domain.RoleAgent {
+			role = "assistant"
+		}
+		messages = append(messages, openAIMessage{Role: role, Content: m.Text})
+	}
+
+	messages = append(messages, openAIMessage{Role: "user", Content: userMessage})
+
+	return openAIRequest{
+		Model:       c.cfg.ModelName,
+		Messages:    messages,
+		Temperature: c.cfg.Temperature,
+		MaxTokens:   c.cfg.MaxTokens,
+		Stream:      stream,
+	}
+}
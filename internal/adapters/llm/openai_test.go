@@ -0,0 +1,149 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/llm"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+type chatRequest struct {
+	Model       string  `json:"model"`
+	Temperature float64 `json:"temperature"`
+	MaxTokens   int     `json:"max_tokens"`
+	Stream      bool    `json:"stream"`
+	Messages    []struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	} `json:"messages"`
+}
+
+// newChatServer stands in for an OpenAI-compatible server and records the last request.
+func newChatServer(t *testing.T, last *chatRequest) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": {"message": "invalid api key"}}`)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(last); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+
+		if !last.Stream {
+			fmt.Fprint(w, `{"choices": [{"message": {"role": "assistant", "content": "Te escucho."}}]}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, tok := range []string{"Te ", "escucho", "."} {
+			fmt.Fprintf(w, "data: {\"choices\": [{\"delta\": {\"content\": %q}}]}\n\n", tok)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newOpenAIClient(t *testing.T, baseURL, apiKey string) *llm.OpenAIClient {
+	t.Helper()
+
+	client, err := llm.NewOpenAIClient(llm.OpenAIConfig{
+		BaseURL:     baseURL + "/v1",
+		APIKey:      apiKey,
+		ModelName:   "llama3.1",
+		Temperature: 0.3,
+		MaxTokens:   256,
+	})
+	if err != nil {
+		t.Fatalf("NewOpenAIClient: %v", err)
+	}
+	return client
+}
+
+func TestOpenAIClientGenerateReply(t *testing.T) {
+	var req chatRequest
+	srv := newChatServer(t, &req)
+	client := newOpenAIClient(t, srv.URL, "secret")
+
+	convCtx := domain.ConversationContext{
+		Mode: domain.ModeDeepDive,
+		History: []*domain.Message{
+			{Author: domain.RoleAgent, Text: "Hola, soy Farum"},
+			{Author: domain.RoleUser, Text: "Estoy cansado"},
+		},
+	}
+
+	reply, err := client.GenerateReply(context.Background(), "Contame más", convCtx)
+	if err != nil {
+		t.Fatalf("GenerateReply: %v", err)
+	}
+	if reply != "Te escucho." {
+		t.Fatalf("unexpected reply %q", reply)
+	}
+
+	if req.Model != "llama3.1" || req.Temperature != 0.3 || req.MaxTokens != 256 || req.Stream {
+		t.Fatalf("unexpected request options: %+v", req)
+	}
+
+	var roles []string
+	for _, m := range req.Messages {
+		roles = append(roles, m.Role)
+	}
+	if got := strings.Join(roles, ","); got != "system,assistant,user,user" {
+		t.Fatalf("unexpected roles %s", got)
+	}
+	if !strings.Contains(req.Messages[0].Content, `emphasis on the "deep_dive" lens`) || req.Messages[3].Content != "Contame más" {
+		t.Fatalf("unexpected messages: %+v", req.Messages)
+	}
+}
+
+func TestOpenAIClientStream(t *testing.T) {
+	var req chatRequest
+	srv := newChatServer(t, &req)
+	client := newOpenAIClient(t, srv.URL, "secret")
+
+	var chunks []string
+	reply, err := client.GenerateReplyStream(context.Background(), "Hola", domain.ConversationContext{}, func(c string) error {
+		chunks = append(chunks, c)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateReplyStream: %v", err)
+	}
+	if !req.Stream {
+		t.Fatalf("expected a streaming request")
+	}
+	if reply != "Te escucho." || len(chunks) != 3 {
+		t.Fatalf("unexpected stream: reply %q, chunks %q", reply, chunks)
+	}
+}
+
+func TestOpenAIClientAPIError(t *testing.T) {
+	var req chatRequest
+	srv := newChatServer(t, &req)
+	client := newOpenAIClient(t, srv.URL, "wrong")
+
+	_, err := client.GenerateReply(context.Background(), "Hola", domain.ConversationContext{})
+
+	var apiErr *llm.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *llm.APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "invalid api key" || apiErr.Temporary() {
+		t.Fatalf("unexpected API error: %+v", apiErr)
+	}
+}
@@ -72,10 +72,16 @@ type Prompt struct {
 	User   string
 }
 
+const memoriesHeader = "\nWhat you remember about this user from previous sessions " +
+	"(use it naturally when relevant, do not recite it):\n"
+
+const summaryHeader = "\nSummary of the earlier conversation of this session " +
+	"(the older messages are not shown):\n"
+
 // BuildPrompt builds the system prompt and the user content
 // (history + new message) from the conversation context.
 func BuildPrompt(userMessage string, ctx domain.ConversationContext) Prompt {
-	system := baseSystemPrompt + "\n" + modeInstructions(ctx.Mode)
+	system := systemPromptFor(ctx)
 
 	var historyParts []string
 	for _, m := range ctx.History {
@@ -109,6 +115,30 @@ func BuildSystemPrompt(mode domain.InteractionMode) string {
 }
 
 
+// systemPromptFor is BuildSystemPrompt plus the memories of convCtx and the
+// summary of the earlier conversation.
+func systemPromptFor(convCtx domain.ConversationContext) string {
+	system := BuildSystemPrompt(convCtx.Mode) + RenderMemories(convCtx.Memories)
+	if convCtx.Summary != "" {
+		system += summaryHeader + convCtx.Summary + "\n"
+	}
+	return system
+}
+
+// RenderMemories renders memories as a prompt section, in order. It returns
+// "" without memories.
+func RenderMemories(memories []domain.Memory) string {
+	if len(memories) == 0 {
+		return ""
+	}
+
+	var lines []string
+	for _, m := range memories {
+		lines = append(lines, "- "+m.Text+"\n")
+	}
+	return memoriesHeader + strings.Join(lines, "")
+}
+
 func modeInstructions(mode domain.InteractionMode) string {
 	switch mode {
 	case domain.ModeDeepDive:
@@ -0,0 +1,39 @@
+package llm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/llm"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+func TestBuildPromptRendersMemories(t *testing.T) {
+	memories := []domain.Memory{
+		{Kind: domain.MemoryMoodTrend, Text: "Mood in recent sessions: ansiedad → tranquilo"},
+		{Kind: domain.MemoryJournal, Text: "[2025-10-01] Past session: ansiedad por el trabajo"},
+	}
+
+	prompt := llm.BuildPrompt("hola", domain.ConversationContext{Memories: memories})
+	if !strings.Contains(prompt.System, "previous sessions") ||
+		!strings.Contains(prompt.System, "- Mood in recent sessions") ||
+		!strings.Contains(prompt.System, "- [2025-10-01] Past session") {
+		t.Fatalf("expected the memories in the system prompt:\n%s", prompt.System)
+	}
+
+	if got := llm.BuildPrompt("hola", domain.ConversationContext{}); strings.Contains(got.System, "previous sessions") {
+		t.Fatalf("expected no memories section without memories")
+	}
+}
+
+func TestBuildPromptRendersSummary(t *testing.T) {
+	prompt := llm.BuildPrompt("hola", domain.ConversationContext{Summary: "La usuaria duerme poco por una entrega."})
+	if !strings.Contains(prompt.System, "Summary of the earlier conversation") ||
+		!strings.Contains(prompt.System, "duerme poco por una entrega") {
+		t.Fatalf("expected the summary in the system prompt:\n%s", prompt.System)
+	}
+
+	if got := llm.BuildPrompt("hola", domain.ConversationContext{}); strings.Contains(got.System, "Summary of the earlier") {
+		t.Fatalf("expected no summary section without summary")
+	}
+}
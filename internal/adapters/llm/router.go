@@ -0,0 +1,360 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+	"google.golang.org/genai"
+)
+
+// ErrNoProvider is returned when no provider can serve a call
+// (every circuit is open, or none supports the capability).
+var ErrNoProvider = errors.New("llm router: no provider available")
+
+// RouterProvider is a named LLM client behind a Router.
+type RouterProvider struct {
+	Name   string // e.g. "vertex" or "openai:gpt-4o-mini"
+	Client domain.LLMClient
+}
+
+// RouterConfig tunes retries, circuit breaking and per-agent routing.
+type RouterConfig struct {
+	// MaxRetries is the number of retries of a transient error on the same
+	// provider before failing over (0 = default 2, negative = no retries).
+	MaxRetries int
+
+	// BaseBackoff doubles on every retry, up to MaxBackoff, with jitter
+	// (defaults 200ms and 2s).
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+
+	// BreakerThreshold consecutive failures open the provider's circuit for
+	// BreakerCooldown (defaults 5 and 30s). After the cooldown calls go
+	// through again; one more failure reopens it, one success closes it.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// Routes maps an agent name (see observability.WithAgent) to the ordered
+	// provider names it should use. Agents without a route use every provider
+	// in registration order.
+	Routes map[string][]string
+}
+
+// Router is a composite domain.LLMClient: it retries transient errors with
+// exponential backoff and jitter, fails over to the next provider, and skips
+// providers whose circuit breaker is open.
+type Router struct {
+	cfg       RouterConfig
+	providers []*routedProvider
+	byName    map[string]*routedProvider
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+type routedProvider struct {
+	name   string
+	client domain.LLMClient
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// NewRouter creates a router over providers, tried in the given order.
+func NewRouter(cfg RouterConfig, providers ...RouterProvider) (*Router, error) {
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("llm router: at least one provider is required")
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 2
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = 200 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 2 * time.Second
+	}
+	if cfg.BreakerThreshold <= 0 {
+		cfg.BreakerThreshold = 5
+	}
+	if cfg.BreakerCooldown <= 0 {
+		cfg.BreakerCooldown = 30 * time.Second
+	}
+
+	r := &Router{
+		cfg:    cfg,
+		byName: make(map[string]*routedProvider),
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+	for _, p := range providers {
+		if p.Client == nil {
+			return nil, fmt.Errorf("llm router: provider %q has no client", p.Name)
+		}
+		if _, dup := r.byName[p.Name]; dup {
+			return nil, fmt.Errorf("llm router: duplicate provider %q", p.Name)
+		}
+		rp := &routedProvider{name: p.Name, client: p.Client}
+		r.providers = append(r.providers, rp)
+		r.byName[p.Name] = rp
+	}
+
+	for agent, names := range cfg.Routes {
+		for _, name := range names {
+			if _, ok := r.byName[name]; !ok {
+				return nil, fmt.Errorf("llm router: route for agent %q uses unknown provider %q", agent, name)
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// GenerateReply implements domain.LLMClient.
+func (r *Router) GenerateReply(
+	ctx context.Context,
+	prompt string,
+	convCtx domain.ConversationContext,
+) (string, error) {
+	var reply string
+	err := r.call(ctx, nil, func(client domain.LLMClient) error {
+		var err error
+		reply, err = client.GenerateReply(ctx, prompt, convCtx)
+		return err
+	})
+	return reply, err
+}
+
+// GenerateReplyStream implements domain.StreamingLLMClient. Once a chunk has
+// reached onChunk the call cannot move to another provider, so a later
+// error is returned as is.
+func (r *Router) GenerateReplyStream(
+	ctx context.Context,
+	prompt string,
+	convCtx domain.ConversationContext,
+	onChunk func(chunk string) error,
+) (string, error) {
+	var reply string
+	err := r.call(ctx, nil, func(client domain.LLMClient) error {
+		delivered := false
+		track := func(chunk string) error {
+			delivered = true
+			return onChunk(chunk)
+		}
+
+		var err error
+		if streamer, ok := client.(domain.StreamingLLMClient); ok {
+			reply, err = streamer.GenerateReplyStream(ctx, prompt, convCtx, track)
+		} else if reply, err = client.GenerateReply(ctx, prompt, convCtx); err == nil {
+			err = track(reply)
+		}
+
+		if err != nil && delivered {
+			return &permanentError{err}
+		}
+		return err
+	})
+	return reply, err
+}
+
+// GenerateWithTools implements domain.ToolCallingLLMClient, using only the
+// providers that support tool calling.
+func (r *Router) GenerateWithTools(
+	ctx context.Context,
+	prompt string,
+	convCtx domain.ConversationContext,
+	tools []domain.ToolSpec,
+	exchanges []domain.ToolExchange,
+) (domain.ToolTurn, error) {
+	supportsTools := func(c domain.LLMClient) bool {
+		return domain.SupportsTools(ctx, c)
+	}
+
+	var turn domain.ToolTurn
+	err := r.call(ctx, supportsTools, func(client domain.LLMClient) error {
+		var err error
+		turn, err = client.(domain.ToolCallingLLMClient).GenerateWithTools(ctx, prompt, convCtx, tools, exchanges)
+		return err
+	})
+	return turn, err
+}
+
+// SupportsTools implements domain.ToolSupport: tools are offered only when a
+// provider routed to the calling agent supports them.
+func (r *Router) SupportsTools(ctx context.Context) bool {
+	for _, p := range r.candidates(observability.AgentFromContext(ctx)) {
+		if domain.SupportsTools(ctx, p.client) {
+			return true
+		}
+	}
+	return false
+}
+
+// call runs fn on the providers for the calling agent until one succeeds.
+func (r *Router) call(
+	ctx context.Context,
+	supports func(domain.LLMClient) bool,
+	fn func(client domain.LLMClient) error,
+) error {
+	agent := observability.AgentFromContext(ctx)
+	log := observability.LoggerFromContext(ctx).With("component", "llm_router", "agent", agent)
+
+	var errs []error
+	for _, p := range r.candidates(agent) {
+		if supports != nil && !supports(p.client) {
+			continue
+		}
+
+		if !p.allow(r.now()) {
+			observability.RecordLLMCall(p.name, agent, observability.LLMOutcomeCircuitOpen, 0)
+			log.Warn("llm provider skipped, circuit open", "provider", p.name)
+			errs = append(errs, fmt.Errorf("%s: circuit open", p.name))
+			continue
+		}
+
+		for attempt := 0; ; attempt++ {
+			start := r.now()
+			err := fn(p.client)
+			latency := r.now().Sub(start)
+
+			if err == nil {
+				p.success()
+				observability.RecordLLMCall(p.name, agent, observability.LLMOutcomeOK, latency)
+				log.Info("llm call served", "provider", p.name, "attempt", attempt+1, "latency_ms", latency.Milliseconds())
+				return nil
+			}
+
+			var perm *permanentError
+			if errors.As(err, &perm) {
+				observability.RecordLLMCall(p.name, agent, observability.LLMOutcomeError, latency)
+				return perm.err
+			}
+
+			// The caller gave up: nothing to retry and not the provider's fault
+			if ctx.Err() != nil {
+				return err
+			}
+
+			opened := p.failure(r.now(), r.cfg.BreakerThreshold, r.cfg.BreakerCooldown)
+			if opened {
+				log.Warn("llm provider circuit opened", "provider", p.name, "cooldown", r.cfg.BreakerCooldown.String())
+			}
+
+			if !IsTransient(err) || attempt >= r.cfg.MaxRetries || opened {
+				observability.RecordLLMCall(p.name, agent, observability.LLMOutcomeError, latency)
+				log.Warn("llm provider failed", "provider", p.name, "attempt", attempt+1, "error", err)
+				errs = append(errs, fmt.Errorf("%s: %w", p.name, err))
+				break
+			}
+
+			observability.RecordLLMCall(p.name, agent, observability.LLMOutcomeRetry, latency)
+			delay := r.backoff(attempt)
+			log.Info("llm call retry", "provider", p.name, "attempt", attempt+1, "delay_ms", delay.Milliseconds(), "error", err)
+			if err := r.sleep(ctx, delay); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return ErrNoProvider
+	}
+	return fmt.Errorf("%w: %w", ErrNoProvider, errors.Join(errs...))
+}
+
+func (r *Router) candidates(agent string) []*routedProvider {
+	names, ok := r.cfg.Routes[agent]
+	if !ok || agent == "" {
+		return r.providers
+	}
+
+	out := make([]*routedProvider, 0, len(names))
+	for _, name := range names {
+		out = append(out, r.byName[name])
+	}
+	return out
+}
+
+// backoff returns BaseBackoff * 2^attempt (capped at MaxBackoff) with
+// "equal jitter": half fixed, half random.
+func (r *Router) backoff(attempt int) time.Duration {
+	d := r.cfg.BaseBackoff << attempt
+	if d <= 0 || d > r.cfg.MaxBackoff {
+		d = r.cfg.MaxBackoff
+	}
+	return d/2 + time.Duration(rand.Int64N(int64(d/2)+1))
+}
+
+func (p *routedProvider) allow(now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !now.Before(p.openUntil)
+}
+
+func (p *routedProvider) success() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failures = 0
+	p.openUntil = time.Time{}
+}
+
+// failure records a failed call and reports whether it opened the circuit.
+func (p *routedProvider) failure(now time.Time, threshold int, cooldown time.Duration) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failures++
+	if p.failures < threshold {
+		return false
+	}
+	p.openUntil = now.Add(cooldown)
+	return true
+}
+
+// permanentError marks an error that must not move to another provider.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// IsTransient reports whether err is worth retrying on the same provider:
+// rate limits, overload, server errors and timeouts.
+func IsTransient(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+
+	var vertexErr genai.APIError
+	if errors.As(err, &vertexErr) {
+		return vertexErr.Code == 429 || vertexErr.Code == 408 || vertexErr.Code >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,161 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/llm"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// flakyLLM fails the first `failures` calls with err, then answers reply.
+type flakyLLM struct {
+	failures int
+	err      error
+	reply    string
+	calls    int
+}
+
+func (f *flakyLLM) GenerateReply(ctx context.Context, prompt string, convCtx domain.ConversationContext) (string, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return "", f.err
+	}
+	return f.reply, nil
+}
+
+var errOverloaded = &llm.APIError{Provider: "test", StatusCode: http.StatusServiceUnavailable, Message: "overloaded"}
+
+func newRouter(t *testing.T, cfg llm.RouterConfig, providers ...llm.RouterProvider) *llm.Router {
+	t.Helper()
+
+	cfg.BaseBackoff = time.Millisecond
+	cfg.MaxBackoff = 2 * time.Millisecond
+	r, err := llm.NewRouter(cfg, providers...)
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	return r
+}
+
+func TestRouterRetriesTransientErrors(t *testing.T) {
+	primary := &flakyLLM{failures: 2, err: errOverloaded, reply: "primary"}
+	r := newRouter(t, llm.RouterConfig{MaxRetries: 2}, llm.RouterProvider{Name: "retry-primary", Client: primary})
+
+	reply, err := r.GenerateReply(context.Background(), "hola", domain.ConversationContext{})
+	if err != nil || reply != "primary" {
+		t.Fatalf("expected primary reply after retries, got %q, %v", reply, err)
+	}
+	if primary.calls != 3 {
+		t.Fatalf("expected 3 calls (1 + 2 retries), got %d", primary.calls)
+	}
+	if observability.LLMCallCount("retry-primary", observability.LLMOutcomeRetry) != 2 {
+		t.Fatalf("expected 2 retries recorded in metrics")
+	}
+}
+
+func TestRouterFailsOverOnPermanentError(t *testing.T) {
+	primary := &flakyLLM{failures: 1, err: errors.New("bad request"), reply: "primary"}
+	secondary := &flakyLLM{reply: "secondary"}
+	r := newRouter(t, llm.RouterConfig{},
+		llm.RouterProvider{Name: "failover-primary", Client: primary},
+		llm.RouterProvider{Name: "failover-secondary", Client: secondary},
+	)
+
+	reply, err := r.GenerateReply(context.Background(), "hola", domain.ConversationContext{})
+	if err != nil || reply != "secondary" {
+		t.Fatalf("expected secondary reply, got %q, %v", reply, err)
+	}
+	if primary.calls != 1 {
+		t.Fatalf("expected no retries for a permanent error, got %d calls", primary.calls)
+	}
+	if observability.LLMCallCount("failover-secondary", observability.LLMOutcomeOK) != 1 {
+		t.Fatalf("expected the secondary to be recorded as serving the call")
+	}
+}
+
+func TestRouterCircuitBreaker(t *testing.T) {
+	primary := &flakyLLM{failures: 1000, err: errOverloaded}
+	secondary := &flakyLLM{reply: "secondary"}
+	r := newRouter(t,
+		llm.RouterConfig{MaxRetries: -1, BreakerThreshold: 2, BreakerCooldown: 50 * time.Millisecond},
+		llm.RouterProvider{Name: "breaker-primary", Client: primary},
+		llm.RouterProvider{Name: "breaker-secondary", Client: secondary},
+	)
+
+	for i := 0; i < 4; i++ {
+		if _, err := r.GenerateReply(context.Background(), "hola", domain.ConversationContext{}); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	// 2 failures open the circuit; the next 2 calls skip the primary
+	if primary.calls != 2 {
+		t.Fatalf("expected the open circuit to skip the primary, got %d calls", primary.calls)
+	}
+
+	// After the cooldown the primary gets one more try (and reopens)
+	time.Sleep(60 * time.Millisecond)
+	if _, err := r.GenerateReply(context.Background(), "hola", domain.ConversationContext{}); err != nil {
+		t.Fatalf("call after cooldown: %v", err)
+	}
+	if primary.calls != 3 {
+		t.Fatalf("expected a trial call after the cooldown, got %d calls", primary.calls)
+	}
+}
+
+func TestRouterPerAgentRoutes(t *testing.T) {
+	cheap := &flakyLLM{reply: "cheap"}
+	strong := &flakyLLM{reply: "strong"}
+	r := newRouter(t,
+		llm.RouterConfig{Routes: map[string][]string{"listener": {"cheap"}, "planner": {"strong", "cheap"}}},
+		llm.RouterProvider{Name: "strong", Client: strong},
+		llm.RouterProvider{Name: "cheap", Client: cheap},
+	)
+
+	for agent, want := range map[string]string{"listener": "cheap", "planner": "strong", "reflector": "strong"} {
+		ctx := observability.WithAgent(context.Background(), agent)
+		reply, err := r.GenerateReply(ctx, "hola", domain.ConversationContext{})
+		if err != nil || reply != want {
+			t.Fatalf("agent %s: expected %q, got %q, %v", agent, want, reply, err)
+		}
+	}
+
+	if _, err := llm.NewRouter(llm.RouterConfig{Routes: map[string][]string{"listener": {"missing"}}},
+		llm.RouterProvider{Name: "cheap", Client: cheap}); err == nil {
+		t.Fatalf("expected an error for a route to an unknown provider")
+	}
+}
+
+func TestRouterAllProvidersFail(t *testing.T) {
+	r := newRouter(t, llm.RouterConfig{MaxRetries: -1},
+		llm.RouterProvider{Name: "down-a", Client: &flakyLLM{failures: 1, err: errOverloaded}},
+		llm.RouterProvider{Name: "down-b", Client: &flakyLLM{failures: 1, err: errOverloaded}},
+	)
+
+	_, err := r.GenerateReply(context.Background(), "hola", domain.ConversationContext{})
+	if !errors.Is(err, llm.ErrNoProvider) || !errors.Is(err, errOverloaded) {
+		t.Fatalf("expected ErrNoProvider wrapping the provider errors, got %v", err)
+	}
+}
+
+func TestRouterSupportsToolsOnlyWhenARouteDoes(t *testing.T) {
+	plain := newRouter(t, llm.RouterConfig{}, llm.RouterProvider{Name: "openai", Client: &flakyLLM{reply: "plain"}})
+	if domain.SupportsTools(context.Background(), plain) {
+		t.Fatalf("expected no tool support without a tool-calling provider")
+	}
+
+	r := newRouter(t,
+		llm.RouterConfig{Routes: map[string][]string{"listener": {"openai"}}},
+		llm.RouterProvider{Name: "openai", Client: &flakyLLM{reply: "plain"}},
+		llm.RouterProvider{Name: "vertex", Client: llm.NewMockLLM()},
+	)
+	for agent, want := range map[string]bool{"listener": false, "planner": true, "": true} {
+		if got := domain.SupportsTools(observability.WithAgent(context.Background(), agent), r); got != want {
+			t.Errorf("agent %q: expected tool support %v, got %v", agent, want, got)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/PabloGalante/farum-agent/internal/domain"
 	"google.golang.org/genai"
@@ -32,9 +34,9 @@ func NewVertexClient(ctx context.Context, cfg VertexConfig) (*VertexClient, erro
 	}
 
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		Project: cfg.ProjectID,
+		Project:  cfg.ProjectID,
 		Location: cfg.Location,
-		Backend: genai.BackendVertexAI,
+		Backend:  genai.BackendVertexAI,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("creating Vertex AI client: %w", err)
@@ -52,8 +54,166 @@ func (v *VertexClient) GenerateReply(
 	userMessage string,
 	convCtx domain.ConversationContext,
 ) (string, error) {
+	contents, cfg := buildVertexRequest(userMessage, convCtx)
+
+	// Call to Vertex
+	res, err := v.client.Models.GenerateContent(ctx, v.modelName, contents, cfg)
+	if err != nil {
+		return "", fmt.Errorf("vertex generate content: %w", err)
+	}
+
+	// Extract only text
+	text := res.Text()
+	if text == "" {
+		return "", fmt.Errorf("vertex returned empty text")
+	}
+
+	return text, nil
+}
+
+// GenerateReplyStream implements domain.StreamingLLMClient using GenerateContentStream.
+func (v *VertexClient) GenerateReplyStream(
+	ctx context.Context,
+	userMessage string,
+	convCtx domain.ConversationContext,
+	onChunk func(chunk string) error,
+) (string, error) {
+	contents, cfg := buildVertexRequest(userMessage, convCtx)
+
+	var full strings.Builder
+	for res, err := range v.client.Models.GenerateContentStream(ctx, v.modelName, contents, cfg) {
+		if err != nil {
+			return "", fmt.Errorf("vertex generate content stream: %w", err)
+		}
+
+		chunk := res.Text()
+		if chunk == "" {
+			continue
+		}
+
+		full.WriteString(chunk)
+		if err := onChunk(chunk); err != nil {
+			return "", err
+		}
+	}
+
+	if full.Len() == 0 {
+		return "", fmt.Errorf("vertex returned empty text")
+	}
+
+	return full.String(), nil
+}
+
+// GenerateWithTools implements domain.ToolCallingLLMClient with Gemini
+// function calling: tools become FunctionDeclarations and every exchange a
+// model turn (function calls) plus a user turn (function responses).
+func (v *VertexClient) GenerateWithTools(
+	ctx context.Context,
+	userMessage string,
+	convCtx domain.ConversationContext,
+	tools []domain.ToolSpec,
+	exchanges []domain.ToolExchange,
+) (domain.ToolTurn, error) {
+	contents, cfg := buildVertexRequest(userMessage, convCtx)
+	contents = append(contents, vertexToolContents(exchanges)...)
+
+	if len(tools) > 0 {
+		decls := make([]*genai.FunctionDeclaration, 0, len(tools))
+		for _, t := range tools {
+			decls = append(decls, &genai.FunctionDeclaration{
+				Name:                 t.Name,
+				Description:          t.Description,
+				ParametersJsonSchema: t.Parameters,
+			})
+		}
+		cfg.Tools = []*genai.Tool{{FunctionDeclarations: decls}}
+	}
+
+	res, err := v.client.Models.GenerateContent(ctx, v.modelName, contents, cfg)
+	if err != nil {
+		return domain.ToolTurn{}, fmt.Errorf("vertex generate content: %w", err)
+	}
+
+	turn := domain.ToolTurn{Text: vertexTextParts(res)}
+	for i, fc := range res.FunctionCalls() {
+		id := fc.ID
+		if id == "" {
+			// Gemini matches responses by name; we still need IDs for the exchange
+			id = fmt.Sprintf("%s-%d", fc.Name, i)
+		}
+		turn.Calls = append(turn.Calls, domain.ToolCall{ID: id, Name: fc.Name, Args: fc.Args})
+	}
+
+	if turn.Text == "" && len(turn.Calls) == 0 {
+		return domain.ToolTurn{}, fmt.Errorf("vertex returned empty text")
+	}
+	return turn, nil
+}
+
+// vertexTextParts is res.Text() without its log warning about the
+// function call parts, which are expected here.
+func vertexTextParts(res *genai.GenerateContentResponse) string {
+	if len(res.Candidates) == 0 || res.Candidates[0].Content == nil {
+		return ""
+	}
+
+	var text strings.Builder
+	for _, part := range res.Candidates[0].Content.Parts {
+		if part.Text != "" && !part.Thought {
+			text.WriteString(part.Text)
+		}
+	}
+	return text.String()
+}
+
+// vertexToolContents maps the previous tool exchanges into Vertex contents.
+func vertexToolContents(exchanges []domain.ToolExchange) []*genai.Content {
+	var contents []*genai.Content
+	for _, ex := range exchanges {
+		var modelParts []*genai.Part
+		if ex.Text != "" {
+			modelParts = append(modelParts, genai.NewPartFromText(ex.Text))
+		}
+		for _, c := range ex.Calls {
+			part := genai.NewPartFromFunctionCall(c.Name, c.Args)
+			part.FunctionCall.ID = c.ID
+			modelParts = append(modelParts, part)
+		}
+		contents = append(contents, genai.NewContentFromParts(modelParts, genai.RoleModel))
+
+		var userParts []*genai.Part
+		for _, r := range ex.Results {
+			part := genai.NewPartFromFunctionResponse(r.Name, vertexFunctionResponse(r))
+			part.FunctionResponse.ID = r.CallID
+			userParts = append(userParts, part)
+		}
+		contents = append(contents, genai.NewContentFromParts(userParts, genai.RoleUser))
+	}
+	return contents
+}
+
+// vertexFunctionResponse turns a ToolResult into the response object Gemini
+// expects: JSON objects as is, anything else under "output" (or "error").
+func vertexFunctionResponse(r domain.ToolResult) map[string]any {
+	key := "output"
+	if r.IsError {
+		key = "error"
+	} else {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(r.Content), &obj); err == nil {
+			return obj
+		}
+	}
+	return map[string]any{key: r.Content}
+}
+
+// buildVertexRequest maps the conversation into Vertex contents + config.
+func buildVertexRequest(
+	userMessage string,
+	convCtx domain.ConversationContext,
+) ([]*genai.Content, *genai.GenerateContentConfig) {
 	// 1) System's Prompt (identity + mode)
-	system := BuildSystemPrompt(convCtx.Mode)
+	system := systemPromptFor(convCtx)
 
 	// 2) History (user / agent) as conversation
 	var contents []*genai.Content
@@ -86,17 +246,5 @@ func (v *VertexClient) GenerateReply(
 		MaxOutputTokens:   outputTokens,
 	}
 
-	// 5) Call to Vertex
-	res, err := v.client.Models.GenerateContent(ctx, v.modelName, contents, cfg)
-	if err != nil {
-		return "", fmt.Errorf("vertex generate content: %w", err)
-	}
-
-	// 6) Extract only text
-	text := res.Text()
-	if text == "" {
-		return "", fmt.Errorf("vertex returned empty text")
-	}
-
-	return text, nil
+	return contents, cfg
 }
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+const defaultBraveBaseURL = "https://api.search.brave.com"
+
+// BraveConfig configures the Brave Search API (or a compatible one).
+type BraveConfig struct {
+	BaseURL    string // defaults to https://api.search.brave.com
+	APIKey     string
+	Country    string // optional, e.g. "AR"
+	HTTPClient *http.Client
+}
+
+// BraveBackend queries GET {BaseURL}/res/v1/web/search.
+type BraveBackend struct {
+	baseURL string
+	apiKey  string
+	country string
+	http    *http.Client
+}
+
+func NewBraveBackend(cfg BraveConfig) (*BraveBackend, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("BraveConfig.APIKey must be set")
+	}
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBraveBaseURL
+	}
+	return &BraveBackend{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  cfg.APIKey,
+		country: cfg.Country,
+		http:    defaultHTTPClient(cfg.HTTPClient),
+	}, nil
+}
+
+func (b *BraveBackend) Name() string {
+	return "brave"
+}
+
+// Search implements domain.SearchBackend.
+func (b *BraveBackend) Search(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	params := url.Values{"q": {query}}
+	if limit > 0 {
+		// The API caps count at 20
+		params.Set("count", strconv.Itoa(min(limit, 20)))
+	}
+	if b.country != "" {
+		params.Set("country", b.country)
+	}
+
+	var res struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	headers := map[string]string{"X-Subscription-Token": b.apiKey}
+	if err := getJSON(ctx, b.http, b.Name(), b.baseURL+"/res/v1/web/search?"+params.Encode(), headers, &res); err != nil {
+		return nil, err
+	}
+
+	out := make([]domain.SearchResult, 0, len(res.Web.Results))
+	for _, r := range res.Web.Results {
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		out = append(out, domain.SearchResult{Title: r.Title, URL: r.URL, Snippet: r.Description})
+	}
+	return out, nil
+}
@@ -0,0 +1,65 @@
+// Package search implements domain.SearchBackend: SearXNG and Brave-style
+// JSON search APIs, and a static corpus for offline runs and tests.
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// APIError is a non-2xx answer from a search API.
+type APIError struct {
+	Backend    string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: HTTP %d: %s", e.Backend, e.StatusCode, e.Message)
+}
+
+func defaultHTTPClient(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+// getJSON sends a GET request and decodes the 2xx JSON answer into out.
+func getJSON(
+	ctx context.Context,
+	client *http.Client,
+	backend string,
+	url string,
+	headers map[string]string,
+	out any,
+) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", backend, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", backend, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		data, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return &APIError{Backend: backend, StatusCode: res.StatusCode, Message: string(data)}
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s: decoding response: %w", backend, err)
+	}
+	return nil
+}
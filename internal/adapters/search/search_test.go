@@ -0,0 +1,92 @@
+package search_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/search"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+func TestSearXNGBackend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" || r.URL.Query().Get("format") != "json" || r.URL.Query().Get("language") != "es" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `{"query": %q, "results": [
+			{"title": "Salud mental", "url": "https://www.who.int/es/salud-mental", "content": "Datos y cifras"},
+			{"title": "Otro", "url": "https://example.com", "content": "..."}
+		]}`, r.URL.Query().Get("q"))
+	}))
+	defer srv.Close()
+
+	backend, err := search.NewSearXNGBackend(search.SearXNGConfig{BaseURL: srv.URL + "/", Language: "es"})
+	if err != nil {
+		t.Fatalf("NewSearXNGBackend: %v", err)
+	}
+
+	results, err := backend.Search(context.Background(), "ansiedad", 1)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	want := domain.SearchResult{Title: "Salud mental", URL: "https://www.who.int/es/salud-mental", Snippet: "Datos y cifras"}
+	if len(results) != 1 || results[0] != want {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
+
+func TestBraveBackend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Subscription-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"type": "ErrorResponse"}`)
+			return
+		}
+		if r.URL.Path != "/res/v1/web/search" || r.URL.Query().Get("count") != "2" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"web": {"results": [
+			{"title": "NIMH", "url": "https://www.nimh.nih.gov/health", "description": "Mental health information"}
+		]}}`)
+	}))
+	defer srv.Close()
+
+	backend, err := search.NewBraveBackend(search.BraveConfig{BaseURL: srv.URL, APIKey: "secret"})
+	if err != nil {
+		t.Fatalf("NewBraveBackend: %v", err)
+	}
+	results, err := backend.Search(context.Background(), "anxiety", 2)
+	if err != nil || len(results) != 1 || results[0].Snippet != "Mental health information" {
+		t.Fatalf("unexpected results: %+v, %v", results, err)
+	}
+
+	unauthorized, _ := search.NewBraveBackend(search.BraveConfig{BaseURL: srv.URL, APIKey: "wrong"})
+	_, err = unauthorized.Search(context.Background(), "anxiety", 2)
+
+	var apiErr *search.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a 401 *search.APIError, got %v", err)
+	}
+}
+
+func TestStaticBackendRanksByOverlap(t *testing.T) {
+	backend := search.NewStaticBackend([]domain.SearchResult{
+		{Title: "Dormir mejor", URL: "https://a.example/sueno", Snippet: "Higiene del sueño"},
+		{Title: "Ansiedad: qué es", URL: "https://b.example/ansiedad", Snippet: "Técnicas para la ansiedad y respiración"},
+		{Title: "Respiración", URL: "https://c.example/respirar", Snippet: "Ejercicios de respiración"},
+	})
+
+	results, err := backend.Search(context.Background(), "ejercicios de respiración para la ansiedad", 0)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 2 || results[0].URL != "https://b.example/ansiedad" {
+		t.Fatalf("unexpected ranking: %+v", results)
+	}
+}
@@ -0,0 +1,69 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// SearXNGConfig configures a SearXNG instance (the JSON format must be
+// enabled in its settings.yml: search.formats includes "json").
+type SearXNGConfig struct {
+	BaseURL    string // e.g. http://localhost:8888
+	Language   string // optional, e.g. "es"
+	HTTPClient *http.Client
+}
+
+// SearXNGBackend queries GET {BaseURL}/search?q=...&format=json.
+type SearXNGBackend struct {
+	baseURL  string
+	language string
+	http     *http.Client
+}
+
+func NewSearXNGBackend(cfg SearXNGConfig) (*SearXNGBackend, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("SearXNGConfig.BaseURL must be set")
+	}
+	return &SearXNGBackend{
+		baseURL:  strings.TrimRight(cfg.BaseURL, "/"),
+		language: cfg.Language,
+		http:     defaultHTTPClient(cfg.HTTPClient),
+	}, nil
+}
+
+func (b *SearXNGBackend) Name() string {
+	return "searxng"
+}
+
+// Search implements domain.SearchBackend.
+func (b *SearXNGBackend) Search(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	params := url.Values{"q": {query}, "format": {"json"}}
+	if b.language != "" {
+		params.Set("language", b.language)
+	}
+
+	var res struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := getJSON(ctx, b.http, b.Name(), b.baseURL+"/search?"+params.Encode(), nil, &res); err != nil {
+		return nil, err
+	}
+
+	out := make([]domain.SearchResult, 0, len(res.Results))
+	for _, r := range res.Results {
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		out = append(out, domain.SearchResult{Title: r.Title, URL: r.URL, Snippet: r.Content})
+	}
+	return out, nil
+}
@@ -0,0 +1,96 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// StaticBackend searches a fixed list of documents by keyword overlap.
+// It needs no network: useful offline, in tests, or to serve a curated list
+// of resources (helplines, psychoeducation pages) instead of the open web.
+type StaticBackend struct {
+	docs []domain.SearchResult
+}
+
+func NewStaticBackend(docs []domain.SearchResult) *StaticBackend {
+	return &StaticBackend{docs: docs}
+}
+
+// LoadStaticBackend reads a JSON array of {"title", "url", "snippet"}.
+func LoadStaticBackend(path string) (*StaticBackend, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading search corpus: %w", err)
+	}
+
+	var docs []domain.SearchResult
+	if err := json.Unmarshal(data, &docs); err != nil {
+		return nil, fmt.Errorf("parsing search corpus %s: %w", path, err)
+	}
+	return NewStaticBackend(docs), nil
+}
+
+func (b *StaticBackend) Name() string {
+	return "static"
+}
+
+// Search implements domain.SearchBackend: documents sharing more query
+// words (in title or snippet) rank first; documents sharing none are left out.
+func (b *StaticBackend) Search(ctx context.Context, query string, limit int) ([]domain.SearchResult, error) {
+	terms := searchTerms(query)
+
+	type scored struct {
+		doc   domain.SearchResult
+		score int
+	}
+	var hits []scored
+	for _, doc := range b.docs {
+		words := map[string]bool{}
+		for _, w := range searchTerms(doc.Title + " " + doc.Snippet) {
+			words[w] = true
+		}
+
+		score := 0
+		for _, t := range terms {
+			if words[t] {
+				score++
+			}
+		}
+		if score > 0 {
+			hits = append(hits, scored{doc: doc, score: score})
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+
+	out := make([]domain.SearchResult, 0, len(hits))
+	for _, h := range hits {
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		out = append(out, h.doc)
+	}
+	return out, nil
+}
+
+// searchTerms lowercases text and splits it into words of 3+ letters.
+func searchTerms(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	out := fields[:0]
+	for _, f := range fields {
+		if len([]rune(f)) >= 3 {
+			out = append(out, f)
+		}
+	}
+	return out
+}
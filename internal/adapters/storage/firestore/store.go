@@ -3,6 +3,7 @@ package firestore
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"cloud.google.com/go/firestore"
@@ -62,6 +63,27 @@ type sessionDoc struct {
 	PreferredMode string    `firestore:"preferred_mode"`
 	CreatedAt     time.Time `firestore:"created_at"`
 	UpdatedAt     time.Time `firestore:"updated_at"`
+
+	Summary        string `firestore:"summary"`
+	SummaryThrough string `firestore:"summary_through"`
+
+	Status   string    `firestore:"status"`
+	ClosedAt time.Time `firestore:"closed_at"`
+}
+
+func (d sessionDoc) toSession(id domain.SessionID) *domain.Session {
+	return &domain.Session{
+		ID:             id,
+		UserID:         domain.UserID(d.UserID),
+		Title:          d.Title,
+		PreferredMode:  domain.InteractionMode(d.PreferredMode),
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+		Summary:        d.Summary,
+		SummaryThrough: domain.MessageID(d.SummaryThrough),
+		Status:         domain.SessionStatus(d.Status),
+		ClosedAt:       d.ClosedAt,
+	}
 }
 
 type messageDoc struct {
@@ -88,6 +110,7 @@ func (s *Store) CreateSession(session *domain.Session) error {
 		PreferredMode: string(session.PreferredMode),
 		CreatedAt:     session.CreatedAt,
 		UpdatedAt:     session.UpdatedAt,
+		Status:        string(session.Status),
 	}
 
 	_, err := s.sessionDoc(session.ID).Create(ctx, doc)
@@ -101,11 +124,15 @@ func (s *Store) UpdateSession(session *domain.Session) error {
 	ctx := context.Background()
 
 	doc := map[string]interface{}{
-		"user_id":        string(session.UserID),
-		"title":          session.Title,
-		"preferred_mode": string(session.PreferredMode),
-		"created_at":     session.CreatedAt,
-		"updated_at":     session.UpdatedAt,
+		"user_id":         string(session.UserID),
+		"title":           session.Title,
+		"preferred_mode":  string(session.PreferredMode),
+		"created_at":      session.CreatedAt,
+		"updated_at":      session.UpdatedAt,
+		"summary":         session.Summary,
+		"summary_through": string(session.SummaryThrough),
+		"status":          string(session.Status),
+		"closed_at":       session.ClosedAt,
 	}
 
 	_, err := s.sessionDoc(session.ID).Set(ctx, doc, firestore.MergeAll)
@@ -115,13 +142,30 @@ func (s *Store) UpdateSession(session *domain.Session) error {
 	return nil
 }
 
+func (s *Store) TouchSession(session *domain.Session) error {
+	ctx := context.Background()
+
+	_, err := s.sessionDoc(session.ID).Update(ctx, []firestore.Update{
+		{Path: "updated_at", Value: session.UpdatedAt},
+		{Path: "summary", Value: session.Summary},
+		{Path: "summary_through", Value: string(session.SummaryThrough)},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return domain.ErrSessionNotFound
+		}
+		return fmt.Errorf("firestore TouchSession: %w", err)
+	}
+	return nil
+}
+
 func (s *Store) GetSession(id domain.SessionID) (*domain.Session, error) {
 	ctx := context.Background()
 
 	snap, err := s.sessionDoc(id).Get(ctx)
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
-			return nil, fmt.Errorf("session not found")
+			return nil, domain.ErrSessionNotFound
 		}
 		return nil, fmt.Errorf("firestore GetSession: %w", err)
 	}
@@ -131,29 +175,48 @@ func (s *Store) GetSession(id domain.SessionID) (*domain.Session, error) {
 		return nil, fmt.Errorf("firestore GetSession decode: %w", err)
 	}
 
-	return &domain.Session{
-		ID:            id,
-		UserID:        domain.UserID(doc.UserID),
-		Title:         doc.Title,
-		PreferredMode: domain.InteractionMode(doc.PreferredMode),
-		CreatedAt:     doc.CreatedAt,
-		UpdatedAt:     doc.UpdatedAt,
-	}, nil
+	return doc.toSession(id), nil
 }
 
-func (s *Store) ListSessionsByUser(userID domain.UserID, limit int) ([]*domain.Session, error) {
+// ListSessionsByUser returns the sessions of a user selected by sq, newest
+// first. Offset is applied by Firestore (skipped documents are still read),
+// except when active sessions are asked for: sessions stored before statuses
+// existed have no status field, which Firestore never matches, so the
+// statuses are filtered, and the page cut, here.
+//
+// Note: in production this query needs composite indexes on
+// (user_id ASC, created_at DESC) and (user_id ASC, status ASC, created_at DESC)
+// in the sessions collection.
+func (s *Store) ListSessionsByUser(userID domain.UserID, sq domain.SessionQuery) ([]*domain.Session, error) {
 	ctx := context.Background()
 
-	q := s.sessionsCol().Where("user_id", "==", string(userID)).OrderBy("created_at", firestore.Desc)
-	if limit > 0 {
-		q = q.Limit(limit)
+	q := s.sessionsCol().Where("user_id", "==", string(userID))
+	inCode := slices.Contains(sq.Statuses, domain.SessionActive)
+	if len(sq.Statuses) > 0 && !inCode {
+		statuses := make([]string, 0, len(sq.Statuses))
+		for _, st := range sq.Statuses {
+			statuses = append(statuses, string(st))
+		}
+		q = q.Where("status", "in", statuses)
+	}
+	q = q.OrderBy("created_at", firestore.Desc)
+
+	skip := sq.Offset
+	if !inCode {
+		if sq.Offset > 0 {
+			q = q.Offset(sq.Offset)
+		}
+		if sq.Limit > 0 {
+			q = q.Limit(sq.Limit)
+		}
+		skip = 0
 	}
 
 	iter := q.Documents(ctx)
 	defer iter.Stop()
 
 	var out []*domain.Session
-	for {
+	for sq.Limit <= 0 || len(out) < sq.Limit {
 		snap, err := iter.Next()
 		if err != nil {
 			if err == iterator.Done {
@@ -167,14 +230,15 @@ func (s *Store) ListSessionsByUser(userID domain.UserID, limit int) ([]*domain.S
 			return nil, fmt.Errorf("decode sessionDoc: %w", err)
 		}
 
-		out = append(out, &domain.Session{
-			ID:            domain.SessionID(snap.Ref.ID),
-			UserID:        domain.UserID(doc.UserID),
-			Title:         doc.Title,
-			PreferredMode: domain.InteractionMode(doc.PreferredMode),
-			CreatedAt:     doc.CreatedAt,
-			UpdatedAt:     doc.UpdatedAt,
-		})
+		session := doc.toSession(domain.SessionID(snap.Ref.ID))
+		if inCode && !slices.Contains(sq.Statuses, session.CurrentStatus()) {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		out = append(out, session)
 	}
 	return out, nil
 }
@@ -218,6 +282,37 @@ func (s *Store) GetMessagesBySession(sessionID domain.SessionID, limit int) ([]*
 		q = q.Limit(limit)
 	}
 
+	out, err := s.queryMessages(ctx, sessionID, q)
+	if err != nil {
+		return nil, fmt.Errorf("firestore GetMessagesBySession: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) GetMessagesAfter(sessionID domain.SessionID, after domain.MessageID) ([]*domain.Message, error) {
+	ctx := context.Background()
+
+	q := s.messagesCol(sessionID).OrderBy("created_at", firestore.Asc)
+	if after != "" {
+		snap, err := s.messageDoc(sessionID, after).Get(ctx)
+		switch {
+		case status.Code(err) == codes.NotFound:
+			// Unknown message: every message is returned
+		case err != nil:
+			return nil, fmt.Errorf("firestore GetMessagesAfter: %w", err)
+		default:
+			q = q.StartAfter(snap)
+		}
+	}
+
+	out, err := s.queryMessages(ctx, sessionID, q)
+	if err != nil {
+		return nil, fmt.Errorf("firestore GetMessagesAfter: %w", err)
+	}
+	return out, nil
+}
+
+func (s *Store) queryMessages(ctx context.Context, sessionID domain.SessionID, q firestore.Query) ([]*domain.Message, error) {
 	iter := q.Documents(ctx)
 	defer iter.Stop()
 
@@ -228,7 +323,7 @@ func (s *Store) GetMessagesBySession(sessionID domain.SessionID, limit int) ([]*
 			if err == iterator.Done {
 				break
 			}
-			return nil, fmt.Errorf("firestore GetMessagesBySession: %w", err)
+			return nil, err
 		}
 
 		var doc messageDoc
@@ -256,3 +351,293 @@ func (s *Store) GetMessagesBySession(sessionID domain.SessionID, limit int) ([]*
 	}
 	return out, nil
 }
+
+// ─────────────────────────────────────────
+// JournalStore implementation
+// ─────────────────────────────────────────
+
+func (s *Store) journalCol() *firestore.CollectionRef {
+	return s.client.Collection("journal_entries")
+}
+
+type journalActionDoc struct {
+	ID          string    `firestore:"id"`
+	Description string    `firestore:"description"`
+	Status      string    `firestore:"status"`
+	Notes       string    `firestore:"notes"`
+	CreatedAt   time.Time `firestore:"created_at"`
+	UpdatedAt   time.Time `firestore:"updated_at"`
+}
+
+type journalEntryDoc struct {
+	SessionID      string             `firestore:"session_id"`
+	UserID         string             `firestore:"user_id"`
+	CreatedAt      time.Time          `firestore:"created_at"`
+	UpdatedAt      time.Time          `firestore:"updated_at"`
+	ProblemSummary string             `firestore:"problem_summary"`
+	ActionPlan     []journalActionDoc `firestore:"action_plan"`
+	Reflection     string             `firestore:"reflection"`
+	MoodBefore     string             `firestore:"mood_before"`
+	MoodAfter      string             `firestore:"mood_after"`
+}
+
+func toJournalEntryDoc(e *domain.JournalEntry) journalEntryDoc {
+	actions := make([]journalActionDoc, 0, len(e.ActionPlan))
+	for _, a := range e.ActionPlan {
+		actions = append(actions, journalActionDoc{
+			ID:          a.ID,
+			Description: a.Description,
+			Status:      string(a.Status),
+			Notes:       a.Notes,
+			CreatedAt:   a.CreatedAt,
+			UpdatedAt:   a.UpdatedAt,
+		})
+	}
+
+	return journalEntryDoc{
+		SessionID:      string(e.SessionID),
+		UserID:         string(e.UserID),
+		CreatedAt:      e.CreatedAt,
+		UpdatedAt:      e.UpdatedAt,
+		ProblemSummary: e.ProblemSummary,
+		ActionPlan:     actions,
+		Reflection:     e.Reflection,
+		MoodBefore:     e.MoodBefore,
+		MoodAfter:      e.MoodAfter,
+	}
+}
+
+func fromJournalEntryDoc(id string, doc journalEntryDoc) *domain.JournalEntry {
+	actions := make([]domain.JournalAction, 0, len(doc.ActionPlan))
+	for _, a := range doc.ActionPlan {
+		actions = append(actions, domain.JournalAction{
+			ID:          a.ID,
+			Description: a.Description,
+			Status:      domain.ActionStatus(a.Status),
+			Notes:       a.Notes,
+			CreatedAt:   a.CreatedAt,
+			UpdatedAt:   a.UpdatedAt,
+		})
+	}
+
+	return &domain.JournalEntry{
+		ID:             domain.JournalEntryID(id),
+		SessionID:      domain.SessionID(doc.SessionID),
+		UserID:         domain.UserID(doc.UserID),
+		CreatedAt:      doc.CreatedAt,
+		UpdatedAt:      doc.UpdatedAt,
+		ProblemSummary: doc.ProblemSummary,
+		ActionPlan:     actions,
+		Reflection:     doc.Reflection,
+		MoodBefore:     doc.MoodBefore,
+		MoodAfter:      doc.MoodAfter,
+	}
+}
+
+func (s *Store) AppendJournalEntry(entry *domain.JournalEntry) error {
+	if entry == nil {
+		return nil
+	}
+
+	ctx := context.Background()
+
+	// Let Firestore assign an ID if the caller did not provide one.
+	ref := s.journalCol().NewDoc()
+	if entry.ID != "" {
+		ref = s.journalCol().Doc(string(entry.ID))
+	}
+
+	if _, err := ref.Create(ctx, toJournalEntryDoc(entry)); err != nil {
+		return fmt.Errorf("firestore AppendJournalEntry: %w", err)
+	}
+
+	entry.ID = domain.JournalEntryID(ref.ID)
+	return nil
+}
+
+func (s *Store) GetJournalEntry(id domain.JournalEntryID) (*domain.JournalEntry, error) {
+	ctx := context.Background()
+
+	snap, err := s.journalCol().Doc(string(id)).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("journal entry not found")
+		}
+		return nil, fmt.Errorf("firestore GetJournalEntry: %w", err)
+	}
+
+	var doc journalEntryDoc
+	if err := snap.DataTo(&doc); err != nil {
+		return nil, fmt.Errorf("firestore GetJournalEntry decode: %w", err)
+	}
+
+	return fromJournalEntryDoc(snap.Ref.ID, doc), nil
+}
+
+// UpdateJournalEntry overwrites the mutable fields of an existing entry.
+// Ownership (user_id, session_id) and created_at are never changed.
+func (s *Store) UpdateJournalEntry(entry *domain.JournalEntry) error {
+	if entry == nil {
+		return nil
+	}
+
+	ctx := context.Background()
+	doc := toJournalEntryDoc(entry)
+
+	_, err := s.journalCol().Doc(string(entry.ID)).Update(ctx, []firestore.Update{
+		{Path: "problem_summary", Value: doc.ProblemSummary},
+		{Path: "action_plan", Value: doc.ActionPlan},
+		{Path: "reflection", Value: doc.Reflection},
+		{Path: "mood_before", Value: doc.MoodBefore},
+		{Path: "mood_after", Value: doc.MoodAfter},
+		{Path: "updated_at", Value: doc.UpdatedAt},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("journal entry not found")
+		}
+		return fmt.Errorf("firestore UpdateJournalEntry: %w", err)
+	}
+	return nil
+}
+
+// ListJournalEntriesByUser returns the last `limit` entries for a user,
+// oldest first (same ordering as the in-memory store).
+// If limit <= 0, returns all.
+//
+// Note: in production this query needs a composite index on
+// (user_id ASC, created_at DESC) in the journal_entries collection.
+func (s *Store) ListJournalEntriesByUser(userID domain.UserID, limit int) ([]*domain.JournalEntry, error) {
+	ctx := context.Background()
+
+	q := s.journalCol().Where("user_id", "==", string(userID)).OrderBy("created_at", firestore.Desc)
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	iter := q.Documents(ctx)
+	defer iter.Stop()
+
+	out := []*domain.JournalEntry{}
+	for {
+		snap, err := iter.Next()
+		if err != nil {
+			if err == iterator.Done {
+				break
+			}
+			return nil, fmt.Errorf("firestore ListJournalEntriesByUser: %w", err)
+		}
+
+		var doc journalEntryDoc
+		if err := snap.DataTo(&doc); err != nil {
+			return nil, fmt.Errorf("decode journalEntryDoc: %w", err)
+		}
+
+		out = append(out, fromJournalEntryDoc(snap.Ref.ID, doc))
+	}
+
+	// We queried newest first to apply the limit; flip back to chronological order.
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+
+	return out, nil
+}
+
+// ─────────────────────────────────────────
+// SafetyEventStore implementation
+// ─────────────────────────────────────────
+
+func (s *Store) safetyEventsCol() *firestore.CollectionRef {
+	return s.client.Collection("safety_events")
+}
+
+type safetyEventDoc struct {
+	SessionID      string    `firestore:"session_id"`
+	UserID         string    `firestore:"user_id"`
+	MessageID      string    `firestore:"message_id"`
+	CreatedAt      time.Time `firestore:"created_at"`
+	Level          string    `firestore:"level"`
+	Categories     []string  `firestore:"categories"`
+	Matches        []string  `firestore:"matches"`
+	Language       string    `firestore:"language"`
+	Source         string    `firestore:"source"`
+	ShortCircuited bool      `firestore:"short_circuited"`
+}
+
+func (s *Store) AppendSafetyEvent(ev *domain.SafetyEvent) error {
+	if ev == nil {
+		return nil
+	}
+
+	ctx := context.Background()
+
+	ref := s.safetyEventsCol().NewDoc()
+	if ev.ID != "" {
+		ref = s.safetyEventsCol().Doc(string(ev.ID))
+	}
+
+	doc := safetyEventDoc{
+		SessionID:      string(ev.SessionID),
+		UserID:         string(ev.UserID),
+		MessageID:      string(ev.MessageID),
+		CreatedAt:      ev.CreatedAt,
+		Level:          string(ev.Level),
+		Categories:     ev.Categories,
+		Matches:        ev.Matches,
+		Language:       ev.Language,
+		Source:         ev.Source,
+		ShortCircuited: ev.ShortCircuited,
+	}
+
+	if _, err := ref.Create(ctx, doc); err != nil {
+		return fmt.Errorf("firestore AppendSafetyEvent: %w", err)
+	}
+
+	ev.ID = domain.SafetyEventID(ref.ID)
+	return nil
+}
+
+// ListSafetyEvents returns the last `limit` events, newest first.
+func (s *Store) ListSafetyEvents(limit int) ([]*domain.SafetyEvent, error) {
+	ctx := context.Background()
+
+	q := s.safetyEventsCol().OrderBy("created_at", firestore.Desc)
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	iter := q.Documents(ctx)
+	defer iter.Stop()
+
+	out := []*domain.SafetyEvent{}
+	for {
+		snap, err := iter.Next()
+		if err != nil {
+			if err == iterator.Done {
+				break
+			}
+			return nil, fmt.Errorf("firestore ListSafetyEvents: %w", err)
+		}
+
+		var doc safetyEventDoc
+		if err := snap.DataTo(&doc); err != nil {
+			return nil, fmt.Errorf("decode safetyEventDoc: %w", err)
+		}
+
+		out = append(out, &domain.SafetyEvent{
+			ID:             domain.SafetyEventID(snap.Ref.ID),
+			SessionID:      domain.SessionID(doc.SessionID),
+			UserID:         domain.UserID(doc.UserID),
+			MessageID:      domain.MessageID(doc.MessageID),
+			CreatedAt:      doc.CreatedAt,
+			Level:          domain.RiskLevel(doc.Level),
+			Categories:     doc.Categories,
+			Matches:        doc.Matches,
+			Language:       doc.Language,
+			Source:         doc.Source,
+			ShortCircuited: doc.ShortCircuited,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,109 @@
+package firestore_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+
+	firestorestore "github.com/PabloGalante/farum-agent/internal/adapters/storage/firestore"
+	"github.com/PabloGalante/farum-agent/internal/adapters/storage/storagetest"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// These tests run against the Firestore emulator:
+//
+//	gcloud emulators firestore start --host-port=localhost:8681
+//	FIRESTORE_EMULATOR_HOST=localhost:8681 go test ./internal/adapters/storage/firestore/...
+//
+// They are skipped when FIRESTORE_EMULATOR_HOST is not set.
+func newEmulatorStore(t *testing.T) *firestorestore.Store {
+	t.Helper()
+
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST not set; skipping Firestore integration tests")
+	}
+
+	store, err := firestorestore.NewStore(context.Background(), emulatorProject())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	return store
+}
+
+func TestFirestoreJournalStore(t *testing.T) {
+	storagetest.RunJournalStoreTests(t, func(t *testing.T) domain.JournalStore {
+		return newEmulatorStore(t)
+	})
+}
+
+func emulatorProject() string {
+	if projectID := os.Getenv("FARUM_GCP_PROJECT"); projectID != "" {
+		return projectID
+	}
+	return "farum-test"
+}
+
+func TestFirestoreListsSessionsWithoutAStatusAsActive(t *testing.T) {
+	store := newEmulatorStore(t)
+	ctx := context.Background()
+
+	client, err := firestore.NewClient(ctx, emulatorProject())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer client.Close()
+
+	// A session written before statuses existed has no status field at all
+	userID := domain.UserID(fmt.Sprintf("%s-%d", t.Name(), time.Now().UnixNano()))
+	base := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	legacy := domain.SessionID(fmt.Sprintf("%s-legacy", userID))
+	if _, err := client.Collection("sessions").Doc(string(legacy)).Create(ctx, map[string]interface{}{
+		"user_id":    string(userID),
+		"title":      "legacy",
+		"created_at": base,
+		"updated_at": base,
+	}); err != nil {
+		t.Fatalf("creating the legacy session: %v", err)
+	}
+
+	for i, st := range []domain.SessionStatus{domain.SessionActive, domain.SessionClosed} {
+		session := &domain.Session{
+			ID:        domain.SessionID(fmt.Sprintf("%s-%d", userID, i)),
+			UserID:    userID,
+			CreatedAt: base.Add(time.Duration(i+1) * time.Hour),
+			Status:    st,
+		}
+		if err := store.CreateSession(session); err != nil {
+			t.Fatalf("CreateSession: %v", err)
+		}
+	}
+
+	active, err := store.ListSessionsByUser(userID, domain.SessionQuery{Statuses: []domain.SessionStatus{domain.SessionActive}})
+	if err != nil {
+		t.Fatalf("ListSessionsByUser: %v", err)
+	}
+	if len(active) != 2 || active[1].ID != legacy || !active[1].IsActive() {
+		t.Fatalf("expected the new and the legacy session, got %+v", active)
+	}
+
+	// Paged in code, newest first
+	page, err := store.ListSessionsByUser(userID, domain.SessionQuery{Statuses: []domain.SessionStatus{domain.SessionActive}, Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("ListSessionsByUser: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != legacy {
+		t.Fatalf("expected the legacy session on the second page, got %+v", page)
+	}
+
+	closed, err := store.ListSessionsByUser(userID, domain.SessionQuery{Statuses: []domain.SessionStatus{domain.SessionClosed}})
+	if err != nil {
+		t.Fatalf("ListSessionsByUser: %v", err)
+	}
+	if len(closed) != 1 || closed[0].CurrentStatus() != domain.SessionClosed {
+		t.Fatalf("expected the closed session, got %+v", closed)
+	}
+}
@@ -1,6 +1,8 @@
 package memory
 
 import (
+	"errors"
+	"slices"
 	"sync"
 	"time"
 
@@ -37,12 +39,47 @@ func (s *MemoryJournalStore) AppendJournalEntry(entry *domain.JournalEntry) erro
 		entry.ID = domain.JournalEntryID(generateID(time.Now()))
 	}
 
-	s.entries[entry.ID] = entry
+	s.entries[entry.ID] = copyJournalEntry(entry)
 	s.byUserID[entry.UserID] = append(s.byUserID[entry.UserID], entry.ID)
 
 	return nil
 }
 
+// GetJournalEntry returns a single entry by ID.
+func (s *MemoryJournalStore) GetJournalEntry(id domain.JournalEntryID) (*domain.JournalEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.entries[id]
+	if !ok {
+		return nil, errors.New("journal entry not found")
+	}
+	return copyJournalEntry(e), nil
+}
+
+// UpdateJournalEntry replaces the mutable fields of an existing entry.
+// Ownership (user, session) and the creation time are never changed.
+func (s *MemoryJournalStore) UpdateJournalEntry(entry *domain.JournalEntry) error {
+	if entry == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.entries[entry.ID]
+	if !ok {
+		return errors.New("journal entry not found")
+	}
+
+	updated := copyJournalEntry(entry)
+	updated.UserID = stored.UserID
+	updated.SessionID = stored.SessionID
+	updated.CreatedAt = stored.CreatedAt
+	s.entries[entry.ID] = updated
+	return nil
+}
+
 // ListJournalEntriesByUser returns the last `limit` entries for a user.
 // If limit <= 0, returns all.
 func (s *MemoryJournalStore) ListJournalEntriesByUser(
@@ -70,13 +107,21 @@ func (s *MemoryJournalStore) ListJournalEntriesByUser(
 	out := make([]*domain.JournalEntry, 0, len(selected))
 	for _, id := range selected {
 		if e, ok := s.entries[id]; ok {
-			out = append(out, e)
+			out = append(out, copyJournalEntry(e))
 		}
 	}
 
 	return out, nil
 }
 
+// copyJournalEntry keeps callers from changing the stored entries without
+// UpdateJournalEntry.
+func copyJournalEntry(e *domain.JournalEntry) *domain.JournalEntry {
+	c := *e
+	c.ActionPlan = slices.Clone(e.ActionPlan)
+	return &c
+}
+
 // generateID reuses the same simple format used by conversation.Service.
 func generateID(t time.Time) string {
 	return t.Format("20060102150405.000000000")
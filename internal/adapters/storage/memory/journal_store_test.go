@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/storage/memory"
+	"github.com/PabloGalante/farum-agent/internal/adapters/storage/storagetest"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+func TestMemoryJournalStore(t *testing.T) {
+	storagetest.RunJournalStoreTests(t, func(t *testing.T) domain.JournalStore {
+		return memory.NewJournalStore()
+	})
+}
@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"slices"
 	"sync"
 
 	"github.com/PabloGalante/farum-agent/internal/domain"
@@ -35,3 +36,14 @@ func (s *MessageStore) GetMessagesBySession(sessionID domain.SessionID, limit in
 	}
 	return msgs, nil
 }
+
+func (s *MessageStore) GetMessagesAfter(sessionID domain.SessionID, after domain.MessageID) ([]*domain.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	msgs := s.messages[sessionID]
+	if i := slices.IndexFunc(msgs, func(m *domain.Message) bool { return m.ID == after }); i >= 0 {
+		return msgs[i+1:], nil
+	}
+	return msgs, nil
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// SafetyEventStore is an in-memory implementation of domain.SafetyEventStore.
+type SafetyEventStore struct {
+	mu     sync.RWMutex
+	events []*domain.SafetyEvent
+}
+
+func NewSafetyEventStore() *SafetyEventStore {
+	return &SafetyEventStore{}
+}
+
+func (s *SafetyEventStore) AppendSafetyEvent(ev *domain.SafetyEvent) error {
+	if ev == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ev.ID == "" {
+		ev.ID = domain.SafetyEventID(generateID(time.Now()))
+	}
+
+	s.events = append(s.events, ev)
+	return nil
+}
+
+// ListSafetyEvents returns the last `limit` events, newest first.
+// If limit <= 0, returns all.
+func (s *SafetyEventStore) ListSafetyEvents(limit int) ([]*domain.SafetyEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]*domain.SafetyEvent, 0, len(s.events))
+	for i := len(s.events) - 1; i >= 0; i-- {
+		out = append(out, s.events[i])
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out, nil
+}
@@ -2,6 +2,8 @@ package memory
 
 import (
 	"errors"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/PabloGalante/farum-agent/internal/domain"
@@ -26,7 +28,9 @@ func (s *SessionStore) CreateSession(session *domain.Session) error {
 		return errors.New("session already exists")
 	}
 
-	s.sessions[session.ID] = session
+	// Copies, like a database: callers never share a stored session
+	cp := *session
+	s.sessions[session.ID] = &cp
 	return nil
 }
 
@@ -35,10 +39,26 @@ func (s *SessionStore) UpdateSession(session *domain.Session) error {
 	defer s.mu.Unlock()
 
 	if _, exists := s.sessions[session.ID]; !exists {
-		return errors.New("session not found")
+		return domain.ErrSessionNotFound
 	}
 
-	s.sessions[session.ID] = session
+	cp := *session
+	s.sessions[session.ID] = &cp
+	return nil
+}
+
+func (s *SessionStore) TouchSession(session *domain.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, exists := s.sessions[session.ID]
+	if !exists {
+		return domain.ErrSessionNotFound
+	}
+
+	stored.UpdatedAt = session.UpdatedAt
+	stored.Summary = session.Summary
+	stored.SummaryThrough = session.SummaryThrough
 	return nil
 }
 
@@ -48,24 +68,38 @@ func (s *SessionStore) GetSession(id domain.SessionID) (*domain.Session, error)
 
 	sess, ok := s.sessions[id]
 	if !ok {
-		return nil, errors.New("session not found")
+		return nil, domain.ErrSessionNotFound
 	}
 
-	return sess, nil
+	cp := *sess
+	return &cp, nil
 }
 
-func (S *SessionStore) ListSessionsByUser(userID domain.UserID, limit int) ([]*domain.Session, error) {
+func (S *SessionStore) ListSessionsByUser(userID domain.UserID, q domain.SessionQuery) ([]*domain.Session, error) {
 	S.mu.RLock()
 	defer S.mu.RUnlock()
 
 	var result []*domain.Session
 	for _, sess := range S.sessions {
-		if sess.UserID == userID {
-			result = append(result, sess)
-			if limit > 0 && len(result) >= limit {
-				break
-			}
+		if sess.UserID != userID {
+			continue
+		}
+		if len(q.Statuses) == 0 || slices.Contains(q.Statuses, sess.CurrentStatus()) {
+			cp := *sess
+			result = append(result, &cp)
+		}
+	}
+
+	// Newest first, like Firestore; the ID breaks ties
+	slices.SortFunc(result, func(a, b *domain.Session) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
 		}
+		return strings.Compare(string(b.ID), string(a.ID))
+	})
+	result = result[min(max(q.Offset, 0), len(result)):]
+	if q.Limit > 0 && len(result) > q.Limit {
+		result = result[:q.Limit]
 	}
 
 	return result, nil
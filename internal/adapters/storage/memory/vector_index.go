@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// VectorIndex is an in-memory domain.VectorIndex with exact (brute force)
+// cosine similarity search, fine for a few thousand chunks.
+type VectorIndex struct {
+	mu      sync.RWMutex
+	chunks  []domain.KnowledgeChunk
+	vectors [][]float32
+	byID    map[string]int
+}
+
+func NewVectorIndex() *VectorIndex {
+	return &VectorIndex{byID: make(map[string]int)}
+}
+
+// Upsert adds chunks, replacing the ones with the same ID.
+func (idx *VectorIndex) Upsert(chunks []domain.KnowledgeChunk, vectors [][]float32) error {
+	if len(chunks) != len(vectors) {
+		return fmt.Errorf("vector index: %d chunks but %d vectors", len(chunks), len(vectors))
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for i, c := range chunks {
+		if j, ok := idx.byID[c.ID]; ok {
+			idx.chunks[j] = c
+			idx.vectors[j] = vectors[i]
+			continue
+		}
+		idx.byID[c.ID] = len(idx.chunks)
+		idx.chunks = append(idx.chunks, c)
+		idx.vectors = append(idx.vectors, vectors[i])
+	}
+	return nil
+}
+
+// Search returns the k chunks most similar to vector, best first.
+func (idx *VectorIndex) Search(vector []float32, k int) ([]domain.KnowledgeHit, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	hits := make([]domain.KnowledgeHit, 0, len(idx.chunks))
+	for i, v := range idx.vectors {
+		if len(v) != len(vector) {
+			return nil, fmt.Errorf("vector index: query has %d dimensions, index has %d", len(vector), len(v))
+		}
+		hits = append(hits, domain.KnowledgeHit{Chunk: idx.chunks[i], Score: cosine(vector, v)})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if k > 0 && len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits, nil
+}
+
+func (idx *VectorIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.chunks)
+}
+
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,288 @@
+// Package storagetest holds behavioural test suites shared by every storage
+// backend, so memory and Firestore are checked against the same contract.
+package storagetest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// JournalStoreFactory returns a ready-to-use JournalStore for a single test.
+type JournalStoreFactory func(t *testing.T) domain.JournalStore
+
+// RunJournalStoreTests runs the JournalStore contract against the store built by newStore.
+// User IDs are made unique per run so backends with shared state (e.g. the
+// Firestore emulator) do not leak entries between runs.
+func RunJournalStoreTests(t *testing.T, newStore JournalStoreFactory) {
+	t.Helper()
+
+	t.Run("AppendAndList", func(t *testing.T) {
+		store := newStore(t)
+		userID := uniqueUserID(t)
+		base := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+
+		entry := &domain.JournalEntry{
+			ID:             domain.JournalEntryID(fmt.Sprintf("%s-entry", userID)),
+			SessionID:      "session-1",
+			UserID:         userID,
+			CreatedAt:      base,
+			UpdatedAt:      base,
+			ProblemSummary: "Ansiedad por el trabajo",
+			Reflection:     "Hoy pude nombrar lo que sentía.",
+			MoodBefore:     "ansioso",
+			MoodAfter:      "más tranquilo",
+			ActionPlan: []domain.JournalAction{
+				{
+					ID:          "a-1",
+					Description: "Salir a caminar 10 minutos",
+					Status:      domain.ActionStatusPending,
+					Notes:       "Después de cenar",
+					CreatedAt:   base,
+					UpdatedAt:   base,
+				},
+				{
+					ID:          "a-2",
+					Description: "Escribir tres cosas que salieron bien",
+					Status:      domain.ActionStatusDone,
+					CreatedAt:   base,
+					UpdatedAt:   base,
+				},
+			},
+		}
+
+		if err := store.AppendJournalEntry(entry); err != nil {
+			t.Fatalf("AppendJournalEntry failed: %v", err)
+		}
+
+		got, err := store.ListJournalEntriesByUser(userID, 10)
+		if err != nil {
+			t.Fatalf("ListJournalEntriesByUser failed: %v", err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("expected 1 entry, got %d", len(got))
+		}
+
+		e := got[0]
+		if e.ID != entry.ID {
+			t.Errorf("ID: expected %q, got %q", entry.ID, e.ID)
+		}
+		if e.SessionID != entry.SessionID || e.UserID != entry.UserID {
+			t.Errorf("unexpected ids: session=%q user=%q", e.SessionID, e.UserID)
+		}
+		if e.ProblemSummary != entry.ProblemSummary || e.Reflection != entry.Reflection {
+			t.Errorf("unexpected text fields: %+v", e)
+		}
+		if e.MoodBefore != entry.MoodBefore || e.MoodAfter != entry.MoodAfter {
+			t.Errorf("unexpected moods: before=%q after=%q", e.MoodBefore, e.MoodAfter)
+		}
+		if !e.CreatedAt.Equal(base) {
+			t.Errorf("CreatedAt: expected %v, got %v", base, e.CreatedAt)
+		}
+
+		if len(e.ActionPlan) != 2 {
+			t.Fatalf("expected 2 actions, got %d", len(e.ActionPlan))
+		}
+		for i, want := range entry.ActionPlan {
+			a := e.ActionPlan[i]
+			if a.ID != want.ID || a.Description != want.Description ||
+				a.Status != want.Status || a.Notes != want.Notes {
+				t.Errorf("action %d: expected %+v, got %+v", i, want, a)
+			}
+			if !a.CreatedAt.Equal(want.CreatedAt) {
+				t.Errorf("action %d CreatedAt: expected %v, got %v", i, want.CreatedAt, a.CreatedAt)
+			}
+		}
+	})
+
+	t.Run("OrderAndLimit", func(t *testing.T) {
+		store := newStore(t)
+		userID := uniqueUserID(t)
+		base := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+
+		for i := 0; i < 5; i++ {
+			ts := base.Add(time.Duration(i) * time.Hour)
+			entry := &domain.JournalEntry{
+				ID:             domain.JournalEntryID(fmt.Sprintf("%s-%d", userID, i)),
+				SessionID:      "session-1",
+				UserID:         userID,
+				CreatedAt:      ts,
+				UpdatedAt:      ts,
+				ProblemSummary: fmt.Sprintf("entry %d", i),
+			}
+			if err := store.AppendJournalEntry(entry); err != nil {
+				t.Fatalf("AppendJournalEntry %d failed: %v", i, err)
+			}
+		}
+
+		all, err := store.ListJournalEntriesByUser(userID, 0)
+		if err != nil {
+			t.Fatalf("ListJournalEntriesByUser failed: %v", err)
+		}
+		if len(all) != 5 {
+			t.Fatalf("expected 5 entries with limit 0, got %d", len(all))
+		}
+		for i := 1; i < len(all); i++ {
+			if all[i].CreatedAt.Before(all[i-1].CreatedAt) {
+				t.Fatalf("entries not ordered by created_at: %v before %v", all[i].CreatedAt, all[i-1].CreatedAt)
+			}
+		}
+
+		last, err := store.ListJournalEntriesByUser(userID, 2)
+		if err != nil {
+			t.Fatalf("ListJournalEntriesByUser with limit failed: %v", err)
+		}
+		if len(last) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(last))
+		}
+		if last[0].ProblemSummary != "entry 3" || last[1].ProblemSummary != "entry 4" {
+			t.Fatalf("expected the 2 most recent entries, got %q and %q",
+				last[0].ProblemSummary, last[1].ProblemSummary)
+		}
+	})
+
+	t.Run("IsolatedByUser", func(t *testing.T) {
+		store := newStore(t)
+		userA := uniqueUserID(t) + "-a"
+		userB := uniqueUserID(t) + "-b"
+		now := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+
+		for _, u := range []domain.UserID{userA, userB} {
+			entry := &domain.JournalEntry{
+				ID:        domain.JournalEntryID(string(u) + "-entry"),
+				SessionID: "session-1",
+				UserID:    u,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			if err := store.AppendJournalEntry(entry); err != nil {
+				t.Fatalf("AppendJournalEntry failed: %v", err)
+			}
+		}
+
+		got, err := store.ListJournalEntriesByUser(userA, 10)
+		if err != nil {
+			t.Fatalf("ListJournalEntriesByUser failed: %v", err)
+		}
+		if len(got) != 1 || got[0].UserID != userA {
+			t.Fatalf("expected only user A entries, got %+v", got)
+		}
+	})
+
+	t.Run("GetAndUpdate", func(t *testing.T) {
+		store := newStore(t)
+		userID := uniqueUserID(t)
+		base := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+
+		entry := &domain.JournalEntry{
+			ID:        domain.JournalEntryID(fmt.Sprintf("%s-entry", userID)),
+			SessionID: "session-1",
+			UserID:    userID,
+			CreatedAt: base,
+			UpdatedAt: base,
+			ActionPlan: []domain.JournalAction{
+				{ID: "a-1", Description: "Caminar", Status: domain.ActionStatusPending, CreatedAt: base, UpdatedAt: base},
+			},
+		}
+		if err := store.AppendJournalEntry(entry); err != nil {
+			t.Fatalf("AppendJournalEntry failed: %v", err)
+		}
+
+		later := base.Add(24 * time.Hour)
+		updated := *entry
+		updated.UpdatedAt = later
+		updated.ActionPlan = []domain.JournalAction{
+			{ID: "a-1", Description: "Caminar", Status: domain.ActionStatusDone, Notes: "Fui al parque",
+				CreatedAt: base, UpdatedAt: later},
+		}
+		if err := store.UpdateJournalEntry(&updated); err != nil {
+			t.Fatalf("UpdateJournalEntry failed: %v", err)
+		}
+
+		got, err := store.GetJournalEntry(entry.ID)
+		if err != nil {
+			t.Fatalf("GetJournalEntry failed: %v", err)
+		}
+		if !got.UpdatedAt.Equal(later) {
+			t.Errorf("UpdatedAt: expected %v, got %v", later, got.UpdatedAt)
+		}
+		if len(got.ActionPlan) != 1 || got.ActionPlan[0].Status != domain.ActionStatusDone ||
+			got.ActionPlan[0].Notes != "Fui al parque" {
+			t.Errorf("unexpected action plan after update: %+v", got.ActionPlan)
+		}
+		if got.UserID != userID || !got.CreatedAt.Equal(base) {
+			t.Errorf("owner or created_at changed: %+v", got)
+		}
+
+		missing := updated
+		missing.ID = domain.JournalEntryID(fmt.Sprintf("%s-missing", userID))
+		if err := store.UpdateJournalEntry(&missing); err == nil {
+			t.Errorf("expected error updating a missing entry")
+		}
+		if _, err := store.GetJournalEntry(missing.ID); err == nil {
+			t.Errorf("expected error getting a missing entry")
+		}
+	})
+
+	t.Run("UpdateKeepsTheOwner", func(t *testing.T) {
+		store := newStore(t)
+		userID, other := uniqueUserID(t), uniqueUserID(t)+"-other"
+		base := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+
+		entry := &domain.JournalEntry{
+			ID:        domain.JournalEntryID(fmt.Sprintf("%s-entry", userID)),
+			SessionID: "session-1",
+			UserID:    userID,
+			CreatedAt: base,
+			UpdatedAt: base,
+		}
+		if err := store.AppendJournalEntry(entry); err != nil {
+			t.Fatalf("AppendJournalEntry failed: %v", err)
+		}
+
+		moved := *entry
+		moved.UserID = other
+		moved.SessionID = "session-2"
+		moved.CreatedAt = base.Add(time.Hour)
+		moved.Reflection = "Me sirvió caminar"
+		if err := store.UpdateJournalEntry(&moved); err != nil {
+			t.Fatalf("UpdateJournalEntry failed: %v", err)
+		}
+
+		got, err := store.GetJournalEntry(entry.ID)
+		if err != nil {
+			t.Fatalf("GetJournalEntry failed: %v", err)
+		}
+		if got.UserID != userID || got.SessionID != "session-1" || !got.CreatedAt.Equal(base) {
+			t.Errorf("owner or created_at changed: %+v", got)
+		}
+		if got.Reflection != "Me sirvió caminar" {
+			t.Errorf("Reflection: expected the update, got %q", got.Reflection)
+		}
+
+		if list, err := store.ListJournalEntriesByUser(other, 0); err != nil || len(list) != 0 {
+			t.Errorf("expected no entries for the other user, got %+v (%v)", list, err)
+		}
+		if list, err := store.ListJournalEntriesByUser(userID, 0); err != nil || len(list) != 1 || list[0].UserID != userID {
+			t.Errorf("expected the entry for its owner, got %+v (%v)", list, err)
+		}
+	})
+
+	t.Run("EmptyUser", func(t *testing.T) {
+		store := newStore(t)
+
+		got, err := store.ListJournalEntriesByUser(uniqueUserID(t), 10)
+		if err != nil {
+			t.Fatalf("ListJournalEntriesByUser failed: %v", err)
+		}
+		if got == nil || len(got) != 0 {
+			t.Fatalf("expected empty non-nil slice, got %#v", got)
+		}
+	})
+}
+
+func uniqueUserID(t *testing.T) domain.UserID {
+	return domain.UserID(fmt.Sprintf("%s-%d", t.Name(), time.Now().UnixNano()))
+}
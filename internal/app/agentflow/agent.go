@@ -8,11 +8,23 @@ import (
 
 // AgentInput represent whats every agent receives
 type AgentInput struct {
-	// Original User Message (for the first agent)
+	// Text to work on: the output of the last upstream agent, or the
+	// original user message when the agent has no upstream agents
 	UserMessage string
 
+	// Original user message of the turn, whatever the position of the agent
+	OriginalMessage string
+
+	// Outputs of the upstream agents this agent takes as inputs, in the
+	// order of the pipeline step (skipped agents are left out)
+	Upstream []AgentResult
+
 	// Current conversational context (history, mode, metadata)
 	ConvCtx domain.ConversationContext
+
+	// OnChunk, when set, receives the reply in chunks as it is generated.
+	// The orchestrator only sets it for the agent producing the final reply.
+	OnChunk func(chunk string) error
 }
 
 // AgentOutput represents what every agent returns.
@@ -22,6 +34,48 @@ type AgentOutput struct {
 
 	// Updated context after this agent
 	UpdatedContext domain.ConversationContext
+
+	// Kind of reply (e.g. ContentTypeTaskList), stored on its message
+	ContentType string
+
+	// Knowledge base chunks this agent drew from
+	Citations []domain.Citation
+
+	// Flags raised for the next steps of the pipeline (e.g. FlagJustVenting)
+	Flags []string
+}
+
+// Content types of the agent outputs.
+const (
+	ContentTypeText        = "text"
+	ContentTypeParaphrase  = "paraphrase"
+	ContentTypeExploration = "exploration"
+	ContentTypeTaskList    = "task_list"
+	ContentTypeReflection  = "reflection"
+
+	// ContentTypeCrisisResources is the reply of the safety gate, which
+	// short-circuits the agents.
+	ContentTypeCrisisResources = "crisis_resources"
+)
+
+// AgentResult is the output of an upstream agent.
+type AgentResult struct {
+	Agent  string
+	Output AgentOutput
+}
+
+// inputSection returns the text the agent works on as the last prompt
+// section: the output of its upstream agent, after the user message, or the
+// user message itself when it has no upstream agents.
+func inputSection(in AgentInput) string {
+	if len(in.Upstream) == 0 && in.UserMessage == in.OriginalMessage {
+		return "User message:\n" + in.UserMessage
+	}
+	section := "Previous agent output:\n" + in.UserMessage
+	if in.OriginalMessage != "" && in.OriginalMessage != in.UserMessage {
+		section = "User message:\n" + in.OriginalMessage + "\n\n" + section
+	}
+	return section
 }
 
 // Agent is the common interface implemented by all agents.
@@ -0,0 +1,265 @@
+package agentflow
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// Composer strategies.
+const (
+	// ComposeTemplate joins the agent outputs verbatim, in order.
+	ComposeTemplate = "template"
+	// ComposeLLM asks the LLM to merge the agent outputs into one reply.
+	ComposeLLM = "llm"
+)
+
+// ComposeRule says how the reply of a mode is put together.
+type ComposeRule struct {
+	// Order of the agent outputs in the reply. Agents not listed go after
+	// the listed ones, in pipeline order.
+	Order []string `json:"order,omitempty"`
+
+	// MaxWords bounds the reply (0 means no limit). The template composer
+	// shortens or drops the last outputs of Order to fit; the LLM composer
+	// is asked to stay within it.
+	MaxWords int `json:"max_words,omitempty"`
+}
+
+// ComposerConfig configures the Composer agent.
+type ComposerConfig struct {
+	Strategy string                                 `json:"strategy,omitempty"` // ComposeTemplate (default) or ComposeLLM
+	Rules    map[domain.InteractionMode]ComposeRule `json:"rules,omitempty"`
+}
+
+// DefaultComposerConfig keeps the words of every agent: the Listener first,
+// then what the mode is about (the exploration or the plan), then the closing.
+func DefaultComposerConfig() ComposerConfig {
+	return ComposerConfig{
+		Strategy: ComposeTemplate,
+		Rules: map[domain.InteractionMode]ComposeRule{
+			domain.ModeCheckIn: {
+				Order:    []string{"listener"},
+				MaxWords: 120,
+			},
+			domain.ModeDeepDive: {
+				Order:    []string{"listener", "explorer"},
+				MaxWords: 180,
+			},
+			domain.ModeActionPlan: {
+				Order:    []string{"listener", "planner", "reflector"},
+				MaxWords: 250,
+			},
+		},
+	}
+}
+
+// validate returns the problems of c, in the format of PipelineConfig.Validate.
+func (c ComposerConfig) validate() []string {
+	var problems []string
+
+	if c.Strategy != "" && c.Strategy != ComposeTemplate && c.Strategy != ComposeLLM {
+		problems = append(problems, fmt.Sprintf("composer: unknown strategy %q (want %s or %s)", c.Strategy, ComposeTemplate, ComposeLLM))
+	}
+
+	for _, mode := range sortedModes(c.Rules) {
+		rule := c.Rules[mode]
+		where := fmt.Sprintf("composer.%s", mode)
+		if !mode.Valid() {
+			problems = append(problems, where+": unknown mode")
+			continue
+		}
+		if rule.MaxWords < 0 {
+			problems = append(problems, where+": negative max_words")
+		}
+		for i, name := range rule.Order {
+			if !slices.Contains(AgentNames, name) || name == "composer" {
+				problems = append(problems, fmt.Sprintf("%s: unknown agent %q in order", where, name))
+			}
+			if slices.Contains(rule.Order[:i], name) {
+				problems = append(problems, fmt.Sprintf("%s: agent %q is twice in order", where, name))
+			}
+		}
+	}
+	return problems
+}
+
+// ComposerAgent: merges the outputs of its upstream agents into the single
+// reply the user gets.
+type ComposerAgent struct {
+	llm domain.LLMClient
+	cfg ComposerConfig
+}
+
+func NewComposerAgent(llm domain.LLMClient, cfg ComposerConfig) *ComposerAgent {
+	return &ComposerAgent{llm: llm, cfg: cfg}
+}
+
+func (a *ComposerAgent) Name() string {
+	return "composer"
+}
+
+func (a *ComposerAgent) Run(ctx context.Context, in AgentInput) (AgentOutput, error) {
+	log := observability.LoggerFromContext(ctx).With("agent", a.Name())
+	log.Info("composer agent running", "inputs", len(in.Upstream), "strategy", a.cfg.Strategy)
+
+	if len(in.Upstream) == 0 {
+		return AgentOutput{}, fmt.Errorf("composer: no upstream outputs")
+	}
+
+	rule := a.cfg.Rules[in.ConvCtx.Mode]
+	outputs := orderOutputs(in.Upstream, rule.Order)
+
+	// One output has nothing to merge: the LLM is not needed
+	if a.cfg.Strategy != ComposeLLM || len(outputs) == 1 {
+		return a.template(in, outputs, rule)
+	}
+
+	// Only fall back to the template if nothing was streamed yet
+	streamed := false
+	onChunk := in.OnChunk
+	if onChunk != nil {
+		onChunk = func(chunk string) error {
+			streamed = true
+			return in.OnChunk(chunk)
+		}
+	}
+
+	reply, err := generateReply(ctx, a.llm, composePrompt(in, outputs, rule), in.ConvCtx, onChunk)
+	if err != nil {
+		if streamed || ctx.Err() != nil {
+			log.Error("composer agent error", "error", err)
+			return AgentOutput{}, err
+		}
+		log.Warn("llm composer failed, using the template", "error", err)
+		return a.template(in, outputs, rule)
+	}
+
+	log.Info("composer agent success")
+	return AgentOutput{
+		Reply:          reply,
+		UpdatedContext: in.ConvCtx,
+		ContentType:    ContentTypeText,
+	}, nil
+}
+
+// template joins the outputs in order, within rule.MaxWords.
+func (a *ComposerAgent) template(in AgentInput, outputs []AgentResult, rule ComposeRule) (AgentOutput, error) {
+	var (
+		parts  []string
+		budget = rule.MaxWords
+	)
+	for _, out := range outputs {
+		text := strings.TrimSpace(out.Output.Reply)
+		if text == "" {
+			continue
+		}
+
+		if rule.MaxWords > 0 {
+			fitted := fitWords(text, budget)
+			// The first non-empty output is kept even when it does not fit,
+			// truncated to the budget
+			if fitted == "" && len(parts) == 0 {
+				fitted = strings.Join(strings.Fields(text)[:max(budget, 1)], " ") + "…"
+			}
+			if fitted == "" {
+				break
+			}
+			budget -= len(strings.Fields(fitted))
+			text = fitted
+		}
+		parts = append(parts, text)
+	}
+	reply := strings.Join(parts, "\n\n")
+
+	if in.OnChunk != nil {
+		if err := in.OnChunk(reply); err != nil {
+			return AgentOutput{}, err
+		}
+	}
+
+	contentType := ContentTypeText
+	if len(outputs) == 1 {
+		contentType = outputs[0].Output.ContentType
+	}
+	return AgentOutput{
+		Reply:          reply,
+		UpdatedContext: in.ConvCtx,
+		ContentType:    contentType,
+	}, nil
+}
+
+func composePrompt(in AgentInput, outputs []AgentResult, rule ComposeRule) string {
+	var sections []string
+	for _, out := range outputs {
+		sections = append(sections, fmt.Sprintf("### %s\n%s", out.Agent, strings.TrimSpace(out.Output.Reply)))
+	}
+
+	length := ""
+	if rule.MaxWords > 0 {
+		length = fmt.Sprintf("Keep the reply under %d words.\n", rule.MaxWords)
+	}
+
+	return fmt.Sprintf(
+		"You are Farum's Composer agent. Other Farum agents worked on the user's message.\n"+
+			"Merge their outputs into one warm, coherent reply in the user's language, in the order given.\n"+
+			"Keep any plan as a numbered list. Do not add new advice, and do not repeat yourself.\n%s\n"+
+			"User message:\n%s\n\nAgent outputs:\n%s",
+		length,
+		in.OriginalMessage,
+		strings.Join(sections, "\n\n"),
+	)
+}
+
+// orderOutputs puts the outputs of order first, then the rest as they came.
+func orderOutputs(upstream []AgentResult, order []string) []AgentResult {
+	out := make([]AgentResult, 0, len(upstream))
+	for _, name := range order {
+		if i := slices.IndexFunc(upstream, func(r AgentResult) bool { return r.Agent == name }); i >= 0 {
+			out = append(out, upstream[i])
+		}
+	}
+	for _, r := range upstream {
+		if !slices.Contains(order, r.Agent) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// fitWords returns text if it has at most budget words, or else the longest
+// prefix of whole sentences or lines that does; "" when not even the first
+// one fits.
+func fitWords(text string, budget int) string {
+	if len(strings.Fields(text)) <= budget {
+		return text
+	}
+
+	runes := []rune(text)
+	cut, words, inWord := 0, 0, false
+	for i, r := range runes {
+		if unicode.IsSpace(r) {
+			inWord = false
+		} else if !inWord {
+			inWord = true
+			words++
+			if words > budget {
+				break
+			}
+		}
+
+		// A line break, or the end of a sentence (not "1." in a list)
+		end := r == '\n' ||
+			strings.ContainsRune(".!?…", r) && i > 0 && !unicode.IsDigit(runes[i-1]) &&
+				(i+1 == len(runes) || unicode.IsSpace(runes[i+1]))
+		if end {
+			cut = i + 1
+		}
+	}
+	return strings.TrimSpace(string(runes[:cut]))
+}
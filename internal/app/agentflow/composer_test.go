@@ -0,0 +1,130 @@
+package agentflow_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/llm"
+	"github.com/PabloGalante/farum-agent/internal/app/agentflow"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+type failingLLM struct{}
+
+func (failingLLM) GenerateReply(ctx context.Context, prompt string, convCtx domain.ConversationContext) (string, error) {
+	return "", errors.New("llm down")
+}
+
+func composerInput(mode domain.InteractionMode) agentflow.AgentInput {
+	return agentflow.AgentInput{
+		OriginalMessage: "¿Qué puedo hacer para dormir mejor?",
+		ConvCtx:         domain.ConversationContext{Mode: mode},
+		Upstream: []agentflow.AgentResult{
+			{Agent: "reflector", Output: agentflow.AgentOutput{Reply: "Fue un buen paso escribirlo. ¿Cómo te sentís ahora?"}},
+			{Agent: "listener", Output: agentflow.AgentOutput{Reply: "Entiendo que te cuesta dormir."}},
+			{Agent: "planner", Output: agentflow.AgentOutput{Reply: "1. Apagá las pantallas a las 22.\n2. Acostate a la misma hora.\n3. Anotá lo que te preocupa."}},
+		},
+	}
+}
+
+func TestComposerTemplateFollowsTheRulesOfTheMode(t *testing.T) {
+	cfg := agentflow.ComposerConfig{
+		Strategy: agentflow.ComposeTemplate,
+		Rules: map[domain.InteractionMode]agentflow.ComposeRule{
+			domain.ModeActionPlan: {Order: []string{"listener", "planner", "reflector"}, MaxWords: 29},
+		},
+	}
+	composer := agentflow.NewComposerAgent(failingLLM{}, cfg)
+
+	out, err := composer.Run(context.Background(), composerInput(domain.ModeActionPlan))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// The plan is kept whole and the closing is cut to its first sentence
+	want := "Entiendo que te cuesta dormir.\n\n" +
+		"1. Apagá las pantallas a las 22.\n2. Acostate a la misma hora.\n3. Anotá lo que te preocupa.\n\n" +
+		"Fue un buen paso escribirlo."
+	if out.Reply != want {
+		t.Fatalf("unexpected reply:\n%s", out.Reply)
+	}
+
+	// Without a rule the outputs keep the pipeline order, whole
+	out, err = composer.Run(context.Background(), composerInput(domain.ModeDeepDive))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.HasPrefix(out.Reply, "Fue un buen paso") || !strings.HasSuffix(out.Reply, "preocupa.") {
+		t.Fatalf("unexpected reply:\n%s", out.Reply)
+	}
+
+	// An empty first output does not take the place of the one kept whole
+	cfg.Rules[domain.ModeActionPlan] = agentflow.ComposeRule{Order: []string{"listener", "planner"}, MaxWords: 3}
+	in := composerInput(domain.ModeActionPlan)
+	in.Upstream[1].Output.Reply = " "
+	out, err = agentflow.NewComposerAgent(failingLLM{}, cfg).Run(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if out.Reply != "1. Apagá las…" {
+		t.Fatalf("unexpected reply:\n%s", out.Reply)
+	}
+}
+
+func TestComposerLLM(t *testing.T) {
+	cfg := agentflow.DefaultComposerConfig()
+	cfg.Strategy = agentflow.ComposeLLM
+
+	var streamed strings.Builder
+	in := composerInput(domain.ModeActionPlan)
+	in.OnChunk = func(chunk string) error {
+		streamed.WriteString(chunk)
+		return nil
+	}
+
+	out, err := agentflow.NewComposerAgent(llm.NewMockLLM(), cfg).Run(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.HasPrefix(out.Reply, "Entiendo") || !strings.Contains(out.Reply, "1. Apagá") || streamed.String() != out.Reply {
+		t.Fatalf("unexpected reply:\n%s", out.Reply)
+	}
+
+	// A failing LLM falls back to the template
+	out, err = agentflow.NewComposerAgent(failingLLM{}, cfg).Run(context.Background(), composerInput(domain.ModeActionPlan))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.HasPrefix(out.Reply, "Entiendo que te cuesta dormir.\n\n1.") {
+		t.Fatalf("expected the template reply, got:\n%s", out.Reply)
+	}
+}
+
+func TestComposerConfigValidation(t *testing.T) {
+	cfg := agentflow.DefaultPipelineConfig()
+	cfg.Composer = agentflow.ComposerConfig{
+		Strategy: "poetry",
+		Rules: map[domain.InteractionMode]agentflow.ComposeRule{
+			domain.ModeDeepDive: {Order: []string{"listener", "therapist", "listener"}, MaxWords: -1},
+			"small_talk":        {},
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatalf("expected an invalid config error")
+	}
+	for _, want := range []string{
+		`composer: unknown strategy "poetry"`,
+		"composer.deep_dive: negative max_words",
+		`composer.deep_dive: unknown agent "therapist" in order`,
+		`composer.deep_dive: agent "listener" is twice in order`,
+		"composer.small_talk: unknown mode",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package agentflow
+
+import (
+	"context"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// EventType identifies a progress event published by the orchestrator.
+type EventType string
+
+const (
+	EventAgentStart   EventType = "agent_start"
+	EventAgentEnd     EventType = "agent_end"
+	EventAgentSkipped EventType = "agent_skipped" // a skip condition of the pipeline step held
+	EventToken        EventType = "token"         // chunk of the final reply
+)
+
+// Event is a progress notification emitted while the agents run.
+type Event struct {
+	Type      EventType `json:"type"`
+	Agent     string    `json:"agent"`
+	Text      string    `json:"text,omitempty"`
+	ElapsedMs int64     `json:"elapsed_ms,omitempty"`
+}
+
+// EventSink receives orchestrator events. Agents may run concurrently, but
+// the orchestrator never calls the sink from two goroutines at once.
+type EventSink func(Event)
+
+// generateReply calls the LLM, streaming the reply through onChunk when
+// onChunk is set and the client supports streaming.
+func generateReply(
+	ctx context.Context,
+	llm domain.LLMClient,
+	prompt string,
+	convCtx domain.ConversationContext,
+	onChunk func(chunk string) error,
+) (string, error) {
+	if onChunk != nil {
+		if streamer, ok := llm.(domain.StreamingLLMClient); ok {
+			return streamer.GenerateReplyStream(ctx, prompt, convCtx, onChunk)
+		}
+	}
+
+	reply, err := llm.GenerateReply(ctx, prompt, convCtx)
+	if err != nil {
+		return "", err
+	}
+
+	// Non-streaming client: deliver the whole reply as a single chunk
+	if onChunk != nil {
+		if err := onChunk(reply); err != nil {
+			return "", err
+		}
+	}
+	return reply, nil
+}
@@ -0,0 +1,49 @@
+package agentflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// ExplorerAgent: helps the user look deeper into a feeling or a pattern,
+// with open questions instead of advice.
+type ExplorerAgent struct {
+	llm domain.LLMClient
+}
+
+func NewExplorerAgent(llm domain.LLMClient) *ExplorerAgent {
+	return &ExplorerAgent{llm: llm}
+}
+
+func (a *ExplorerAgent) Name() string {
+	return "explorer"
+}
+
+func (a *ExplorerAgent) Run(ctx context.Context, in AgentInput) (AgentOutput, error) {
+	log := observability.LoggerFromContext(ctx).With("agent", a.Name())
+	log.Info("explorer agent running")
+
+	prompt := fmt.Sprintf(
+		"You are Farum's Explorer agent. Help the user explore their concern in more depth: "+
+			"reflect what you notice (feelings, thoughts, body sensations, repeating patterns) "+
+			"and ask one or two open, gentle questions.\n"+
+			"Do not give advice or a plan, and do not interpret or diagnose.\n\n%s",
+		inputSection(in),
+	)
+
+	reply, err := generateReply(ctx, a.llm, prompt, in.ConvCtx, in.OnChunk)
+	if err != nil {
+		log.Error("explorer agent error", "error", err)
+		return AgentOutput{}, err
+	}
+
+	log.Info("explorer agent success")
+	return AgentOutput{
+		Reply:          reply,
+		UpdatedContext: in.ConvCtx,
+		ContentType:    ContentTypeExploration,
+	}, nil
+}
@@ -0,0 +1,248 @@
+package agentflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// maxJournalActions mirrors the Planner contract (2-4 steps).
+const maxJournalActions = 4
+
+// JournalExtraction is the structured data we ask the LLM for.
+// It matches the input schema of JournalTool.Call (minus the reflection,
+// which the Reflector already has).
+type JournalExtraction struct {
+	ProblemSummary string            `json:"problem_summary"`
+	MoodBefore     string            `json:"mood_before"`
+	MoodAfter      string            `json:"mood_after"`
+	Actions        []ExtractedAction `json:"actions"`
+}
+
+// ExtractedAction is a single step of the action plan as returned by the LLM.
+type ExtractedAction struct {
+	Description string `json:"description"`
+	Status      string `json:"status,omitempty"`
+	Notes       string `json:"notes,omitempty"`
+}
+
+// JournalExtractor asks the LLM for a JSON summary of the conversation
+// and repairs/retries when the output is not valid.
+type JournalExtractor struct {
+	llm         domain.LLMClient
+	maxAttempts int
+}
+
+// NewJournalExtractor creates an extractor that makes 1 attempt + 1 repair.
+func NewJournalExtractor(llm domain.LLMClient) *JournalExtractor {
+	return &JournalExtractor{
+		llm:         llm,
+		maxAttempts: 2,
+	}
+}
+
+const journalExtractionPrompt = `You are Farum's Journal extraction step. You do NOT talk to the user.
+Read the conversation, the action plan and the final reflection, and return ONLY a JSON object
+(no markdown, no comments, no extra text) with exactly this shape:
+
+{
+  "problem_summary": "one or two sentences describing what the user is dealing with",
+  "mood_before": "one or two words describing the user's mood at the start",
+  "mood_after": "one or two words describing the user's mood after the conversation",
+  "actions": [
+    {"description": "a concrete step taken from the action plan", "status": "pending", "notes": ""}
+  ]
+}
+
+Rules:
+- Write the values in the SAME LANGUAGE as the user.
+- "actions" must contain between 0 and 4 items, taken from the action plan steps.
+- "status" must be "pending", "done" or "skipped".
+
+Action plan:
+%s
+
+Final reflection:
+%s`
+
+const journalRepairPrompt = `Your previous answer was not valid for the required JSON schema.
+Error: %s
+
+Previous answer:
+%s
+
+Return ONLY the corrected JSON object, with the keys "problem_summary", "mood_before",
+"mood_after" and "actions" (array of objects with "description", "status" and "notes").`
+
+// Extract asks the LLM for a JournalExtraction, validating the result.
+// On malformed output it sends a repair prompt, up to maxAttempts calls in total.
+func (e *JournalExtractor) Extract(
+	ctx context.Context,
+	plan string,
+	reflection string,
+	convCtx domain.ConversationContext,
+) (*JournalExtraction, error) {
+	log := observability.LoggerFromContext(ctx).With("step", "journal_extraction")
+
+	prompt := fmt.Sprintf(journalExtractionPrompt, plan, reflection)
+
+	var lastErr error
+	for attempt := 1; attempt <= e.maxAttempts; attempt++ {
+		raw, err := e.llm.GenerateReply(ctx, prompt, convCtx)
+		if err != nil {
+			return nil, fmt.Errorf("journal extraction: %w", err)
+		}
+
+		extraction, err := parseJournalExtraction(raw)
+		if err == nil {
+			log.Info("journal extraction success", "attempt", attempt)
+			return extraction, nil
+		}
+
+		log.Warn("journal extraction invalid", "attempt", attempt, "error", err)
+		lastErr = err
+		prompt = fmt.Sprintf(journalRepairPrompt, err, raw)
+	}
+
+	return nil, fmt.Errorf("journal extraction: %w", lastErr)
+}
+
+// BuildToolInput returns the input for JournalTool.Call.
+// If extraction fails, it falls back to the reflection plus the numbered
+// steps found in the plan, so we never lose the action plan.
+func (e *JournalExtractor) BuildToolInput(
+	ctx context.Context,
+	plan string,
+	reflection string,
+	convCtx domain.ConversationContext,
+) map[string]any {
+	extraction, err := e.Extract(ctx, plan, reflection, convCtx)
+	if err != nil {
+		observability.LoggerFromContext(ctx).Warn("using fallback journal entry", "error", err)
+		extraction = &JournalExtraction{}
+	}
+
+	if len(extraction.Actions) == 0 {
+		for _, step := range parsePlanSteps(plan) {
+			extraction.Actions = append(extraction.Actions, ExtractedAction{Description: step})
+		}
+	}
+
+	return extraction.toToolInput(reflection)
+}
+
+func (x *JournalExtraction) toToolInput(reflection string) map[string]any {
+	actions := make([]any, 0, len(x.Actions))
+	for _, a := range x.Actions {
+		action := map[string]any{
+			"description": a.Description,
+			"notes":       a.Notes,
+		}
+		// No status means pending for the journal tool, but "" fails its schema
+		if a.Status != "" {
+			action["status"] = a.Status
+		}
+		actions = append(actions, action)
+	}
+
+	return map[string]any{
+		"problem_summary": x.ProblemSummary,
+		"reflection":      reflection,
+		"mood_before":     x.MoodBefore,
+		"mood_after":      x.MoodAfter,
+		"actions":         actions,
+	}
+}
+
+// --- internal helpers --- //
+
+var errNoJSONObject = errors.New("no JSON object found in the answer")
+
+func parseJournalExtraction(raw string) (*JournalExtraction, error) {
+	obj, ok := extractJSONObject(raw)
+	if !ok {
+		return nil, errNoJSONObject
+	}
+
+	var x JournalExtraction
+	if err := json.Unmarshal([]byte(obj), &x); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if err := x.validate(); err != nil {
+		return nil, err
+	}
+	return &x, nil
+}
+
+func (x *JournalExtraction) validate() error {
+	x.ProblemSummary = strings.TrimSpace(x.ProblemSummary)
+	x.MoodBefore = strings.TrimSpace(x.MoodBefore)
+	x.MoodAfter = strings.TrimSpace(x.MoodAfter)
+
+	if x.ProblemSummary == "" {
+		return errors.New("problem_summary is required")
+	}
+	if len(x.Actions) > maxJournalActions {
+		return fmt.Errorf("actions must have at most %d items, got %d", maxJournalActions, len(x.Actions))
+	}
+
+	for i := range x.Actions {
+		a := &x.Actions[i]
+		a.Description = strings.TrimSpace(a.Description)
+		if a.Description == "" {
+			return fmt.Errorf("actions[%d].description is required", i)
+		}
+
+		switch domain.ActionStatus(a.Status) {
+		case "":
+			a.Status = string(domain.ActionStatusPending)
+		case domain.ActionStatusPending, domain.ActionStatusDone, domain.ActionStatusSkipped:
+		default:
+			return fmt.Errorf("actions[%d].status must be %q, %q or %q", i,
+				domain.ActionStatusPending, domain.ActionStatusDone, domain.ActionStatusSkipped)
+		}
+	}
+	return nil
+}
+
+// extractJSONObject returns the outermost {...} in s, tolerating
+// markdown fences or chatter around it.
+func extractJSONObject(s string) (string, bool) {
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start < 0 || end <= start {
+		return "", false
+	}
+	return s[start : end+1], true
+}
+
+var planStepRe = regexp.MustCompile(`^\s*(?:\d+[.)]|[-*•])\s+(.+)$`)
+
+// parsePlanSteps returns the numbered or bulleted lines of a plan, at most maxJournalActions.
+func parsePlanSteps(plan string) []string {
+	var steps []string
+	for _, line := range strings.Split(plan, "\n") {
+		m := planStepRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		step := strings.TrimSpace(strings.Trim(m[1], "*_ "))
+		if step == "" {
+			continue
+		}
+
+		steps = append(steps, step)
+		if len(steps) == maxJournalActions {
+			break
+		}
+	}
+	return steps
+}
@@ -0,0 +1,81 @@
+package agentflow_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/app/agentflow"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// scriptedLLM returns the given replies in order (the last one is repeated).
+type scriptedLLM struct {
+	replies []string
+	calls   int
+}
+
+func (s *scriptedLLM) GenerateReply(ctx context.Context, prompt string, convCtx domain.ConversationContext) (string, error) {
+	i := s.calls
+	if i >= len(s.replies) {
+		i = len(s.replies) - 1
+	}
+	s.calls++
+	return s.replies[i], nil
+}
+
+func TestJournalExtractorRepairsMalformedJSON(t *testing.T) {
+	llm := &scriptedLLM{replies: []string{
+		"Claro! Acá va el resumen: {problem_summary: ansiedad",
+		"```json\n" + `{"problem_summary":"Ansiedad por el trabajo","mood_before":"ansioso","mood_after":"aliviado",` +
+			`"actions":[{"description":"Caminar 10 minutos","status":"pending"}]}` + "\n```",
+	}}
+
+	ex := agentflow.NewJournalExtractor(llm)
+	got, err := ex.Extract(context.Background(), "1. Caminar 10 minutos", "Buen cierre", domain.ConversationContext{})
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if llm.calls != 2 {
+		t.Fatalf("expected 2 LLM calls (1 repair), got %d", llm.calls)
+	}
+	if got.ProblemSummary != "Ansiedad por el trabajo" || got.MoodBefore != "ansioso" || got.MoodAfter != "aliviado" {
+		t.Fatalf("unexpected extraction: %+v", got)
+	}
+	if len(got.Actions) != 1 || got.Actions[0].Description != "Caminar 10 minutos" {
+		t.Fatalf("unexpected actions: %+v", got.Actions)
+	}
+}
+
+func TestJournalExtractorRejectsInvalidStatus(t *testing.T) {
+	llm := &scriptedLLM{replies: []string{
+		`{"problem_summary":"x","actions":[{"description":"y","status":"maybe"}]}`,
+	}}
+
+	ex := agentflow.NewJournalExtractor(llm)
+	if _, err := ex.Extract(context.Background(), "", "", domain.ConversationContext{}); err == nil {
+		t.Fatalf("expected validation error for invalid status")
+	}
+}
+
+func TestJournalExtractorFallsBackToPlanSteps(t *testing.T) {
+	llm := &scriptedLLM{replies: []string{"no JSON here"}}
+	plan := "Te propongo:\n1. Salir a caminar 10 minutos\n2) Escribir lo que sentís\n- Hablar con alguien de confianza\n"
+
+	ex := agentflow.NewJournalExtractor(llm)
+	input := ex.BuildToolInput(context.Background(), plan, "Reflexión final", domain.ConversationContext{})
+
+	if input["reflection"] != "Reflexión final" {
+		t.Fatalf("expected reflection to be kept, got %v", input["reflection"])
+	}
+
+	actions, ok := input["actions"].([]any)
+	if !ok || len(actions) != 3 {
+		t.Fatalf("expected 3 actions from plan, got %#v", input["actions"])
+	}
+
+	first := actions[0].(map[string]any)
+	if first["description"] != "Salir a caminar 10 minutos" {
+		t.Fatalf("unexpected first action: %v", first)
+	}
+}
@@ -3,6 +3,7 @@ package agentflow
 import (
 	"context"
 	"fmt"
+	"regexp"
 
 	"github.com/PabloGalante/farum-agent/internal/domain"
 	"github.com/PabloGalante/farum-agent/internal/observability"
@@ -26,19 +27,33 @@ func (a *ListenerAgent) Run(ctx context.Context, in AgentInput) (AgentOutput, er
 	log.Info("listener agent running")
 
 	prompt := fmt.Sprintf(
-		"You are Farum's Listener agent. Your job is to carefully listen, clarify the user's concern, and restate it in a clear, empathetic way.\n\nUser: %s",
-		in.UserMessage,
+		"You are Farum's Listener agent. Your job is to carefully listen, clarify the user's concern, and restate it in a clear, empathetic way.\n\n%s",
+		inputSection(in),
 	)
 
-	reply, err := a.llm.GenerateReply(ctx, prompt, in.ConvCtx)
+	reply, err := generateReply(ctx, a.llm, prompt, in.ConvCtx, in.OnChunk)
 	if err != nil {
 		log.Error("listener agent error", "error", err)
 		return AgentOutput{}, err
 	}
 
-	log.Info("listener agent success")
+	// Only the user's own words say they want to vent
+	var flags []string
+	if justVentingRe.MatchString(in.OriginalMessage) {
+		flags = append(flags, FlagJustVenting)
+	}
+
+	log.Info("listener agent success", "flags", flags)
 	return AgentOutput{
 		Reply:          reply,
 		UpdatedContext: in.ConvCtx,
+		ContentType:    ContentTypeParaphrase,
+		Flags:          flags,
 	}, nil
 }
+
+// justVentingRe matches users saying they want to be heard, not advised.
+var justVentingRe = regexp.MustCompile(`(?i)` +
+	`desahog|s[oó]lo (quiero|necesito) (hablar|contar|que me escuch)|no quiero (consejos|soluciones)|` +
+	`just (want|need) to (vent|talk|be heard)|(don't|do not) want (advice|solutions)|` +
+	`desabaf|s[oó] (quero|preciso) (falar|ser ouvid)|n[aã]o quero (conselhos|solu[cç])`)
@@ -2,81 +2,343 @@ package agentflow
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
+	"golang.org/x/sync/errgroup"
+
 	"github.com/PabloGalante/farum-agent/internal/app/tools"
 	"github.com/PabloGalante/farum-agent/internal/domain"
 	"github.com/PabloGalante/farum-agent/internal/observability"
 )
 
-// Orchestrator is responsible for running multiple agents in sequence.
+// Orchestrator is responsible for running the pipeline of agents of the
+// conversation mode.
 type Orchestrator struct {
-	llm         domain.LLMClient
-	journalTool tools.Tool
-	agents      []Agent
+	llm       domain.LLMClient
+	registry  *tools.ToolRegistry
+	knowledge domain.KnowledgeRetriever
+	pipelines PipelineConfig
+	agents    map[string]Agent
+	custom    []Agent
+}
+
+// OrchestratorOption configures optional collaborators of the agents.
+type OrchestratorOption func(*Orchestrator)
+
+// WithKnowledge gives the Planner a knowledge base to ground its plans on.
+func WithKnowledge(knowledge domain.KnowledgeRetriever) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.knowledge = knowledge
+	}
+}
+
+// WithPipelines replaces the default pipelines. cfg is expected to be
+// valid (see LoadPipelineConfig).
+func WithPipelines(cfg PipelineConfig) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.pipelines = cfg
+	}
 }
 
-// NewDefaultOrchestrator constructs a flow with Listener -> Planner -> Reflector.
-func NewDefaultOrchestrator(llm domain.LLMClient, journalTool tools.Tool) *Orchestrator {
-	return &Orchestrator{
-		llm:         llm,
-		journalTool: journalTool,
-		agents: []Agent{
-			NewListenerAgent(llm),
-			NewPlannerAgent(llm),
-			NewReflectorAgent(llm, journalTool),
-		},
+// WithAgent adds an agent pipelines can use, or replaces the built-in one
+// with the same name.
+func WithAgent(ag Agent) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.custom = append(o.custom, ag)
+	}
+}
+
+// TurnResult is the outcome of running the agents on one user message.
+type TurnResult struct {
+	Reply     string
+	Replier   string            // agent that gave Reply
+	Outputs   []AgentResult     // every agent that ran, in pipeline order
+	Citations []domain.Citation // deduplicated, in order of first use
+}
+
+// NewDefaultOrchestrator constructs the Listener, Explorer, Planner,
+// Reflector and Composer agents, run per mode as DefaultPipelineConfig says unless
+// WithPipelines is given. The Planner and the Reflector may call the tools
+// of registry (nil means no tools).
+func NewDefaultOrchestrator(llm domain.LLMClient, registry *tools.ToolRegistry, opts ...OrchestratorOption) *Orchestrator {
+	o := &Orchestrator{
+		llm:       llm,
+		registry:  registry,
+		pipelines: DefaultPipelineConfig(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	o.agents = map[string]Agent{}
+	for _, ag := range []Agent{
+		NewListenerAgent(llm),
+		NewExplorerAgent(llm),
+		NewPlannerAgent(llm, registry, o.knowledge),
+		NewReflectorAgent(llm, registry),
+		NewComposerAgent(llm, o.pipelines.Composer),
+	} {
+		o.agents[ag.Name()] = ag
 	}
+	for _, ag := range o.custom {
+		o.agents[ag.Name()] = ag
+	}
+	return o
 }
 
-// Run executes the chain of agents sequentially.
+// Run executes the pipeline of agents of the conversation mode.
 func (o *Orchestrator) Run(
 	ctx context.Context,
 	userMessage string,
 	convCtx domain.ConversationContext,
 ) (string, error) {
-	if len(o.agents) == 0 {
-		return "", fmt.Errorf("no agents configured in orchestrator")
+	return o.RunWithEvents(ctx, userMessage, convCtx, nil)
+}
+
+// RunWithEvents is like Run, but publishes per-agent start/end events and
+// the chunks of the final reply to sink. sink may be nil.
+func (o *Orchestrator) RunWithEvents(
+	ctx context.Context,
+	userMessage string,
+	convCtx domain.ConversationContext,
+	sink EventSink,
+) (string, error) {
+	res, err := o.RunTurn(ctx, userMessage, convCtx, sink)
+	if err != nil {
+		return "", err
+	}
+	return res.Reply, nil
+}
+
+// RunTurn is like RunWithEvents, but also returns what the agents cited.
+//
+// The steps of the pipeline run as a DAG: each one starts as soon as its
+// inputs are done, so independent agents run concurrently, each bounded by
+// its own timeout. The first failure cancels the rest of the turn. The last
+// step streams the reply; when it is skipped, the reply is the one of the
+// last step that ran.
+func (o *Orchestrator) RunTurn(
+	ctx context.Context,
+	userMessage string,
+	convCtx domain.ConversationContext,
+	sink EventSink,
+) (*TurnResult, error) {
+	steps := o.pipelines.For(convCtx.Mode)
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("no agents configured for mode %q", convCtx.Mode)
 	}
 
 	log := observability.LoggerFromContext(ctx).With(
 		"session_id", convCtx.SessionID,
 		"user_id", convCtx.UserID,
 	)
-	log.Info("Orchestrator started", "agents_count", len(o.agents))
+	log.Info("Orchestrator started", "mode", convCtx.Mode, "agents_count", len(steps))
+
+	nodes, err := o.plan(steps, convCtx.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Agents run concurrently, but the sink sees one event at a time
+	var sinkMu sync.Mutex
+	emit := func(ev Event) {
+		if sink == nil {
+			return
+		}
+		sinkMu.Lock()
+		defer sinkMu.Unlock()
+		sink(ev)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	for i, n := range nodes {
+		g.Go(func() error {
+			defer close(n.done)
+
+			for _, d := range n.deps {
+				select {
+				case <-nodes[d].done:
+				case <-gctx.Done():
+					return gctx.Err()
+				}
+			}
+
+			// Flags and outputs of the inputs, or of their own inputs when
+			// they were skipped
+			in := AgentInput{
+				UserMessage:     userMessage,
+				OriginalMessage: userMessage,
+				ConvCtx:         convCtx,
+			}
+			n.flags = map[string]bool{}
+			for _, d := range n.deps {
+				for f := range nodes[d].flags {
+					n.flags[f] = true
+				}
+				in.Upstream = appendResults(in.Upstream, nodes[d].results...)
+			}
+			if len(in.Upstream) > 0 {
+				last := in.Upstream[len(in.Upstream)-1].Output
+				in.UserMessage = last.Reply
+				in.ConvCtx = last.UpdatedContext
+			}
+
+			if n.step.skipped(n.flags) {
+				log.Info("agent skipped", "agent", n.agent.Name(), "skip_if", n.step.SkipIf)
+				emit(Event{Type: EventAgentSkipped, Agent: n.agent.Name()})
+				n.results = in.Upstream
+				return nil
+			}
+
+			if i == len(nodes)-1 {
+				name := n.agent.Name()
+				in.OnChunk = func(chunk string) error {
+					emit(Event{Type: EventToken, Agent: name, Text: chunk})
+					return nil
+				}
+			}
+
+			out, err := o.runNode(gctx, n, in, emit)
+			if err != nil {
+				return err
+			}
 
-	in := AgentInput{
-		UserMessage: userMessage,
-		ConvCtx:     convCtx,
+			n.ran = true
+			n.out = out
+			for _, f := range out.Flags {
+				n.flags[f] = true
+			}
+			n.results = []AgentResult{{Agent: n.agent.Name(), Output: out}}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
 	}
 
 	var (
-		out AgentOutput
-		err error
+		replier   *node
+		outputs   []AgentResult
+		citations []domain.Citation
+		cited     = map[string]bool{}
 	)
+	for _, n := range nodes {
+		if !n.ran {
+			continue
+		}
+		replier = n
+		outputs = append(outputs, AgentResult{Agent: n.agent.Name(), Output: n.out})
+		for _, c := range n.out.Citations {
+			if !cited[c.ChunkID] {
+				cited[c.ChunkID] = true
+				citations = append(citations, c)
+			}
+		}
+	}
+
+	// The last step was skipped, so its reply was not streamed
+	if replier != nodes[len(nodes)-1] {
+		emit(Event{Type: EventToken, Agent: replier.agent.Name(), Text: replier.out.Reply})
+	}
+
+	log.Info("orchestrator end", "replier", replier.agent.Name(), "citations", len(citations))
+	return &TurnResult{
+		Reply:     replier.out.Reply,
+		Replier:   replier.agent.Name(),
+		Outputs:   outputs,
+		Citations: citations,
+	}, nil
+}
 
-	for _, ag := range o.agents {
-		start := time.Now()
-		log.Info("agent run start", "agent", ag.Name())
+// node is a step of the pipeline being run. Its fields are written by its
+// own goroutine before done is closed, and only read after that.
+type node struct {
+	step  PipelineStep
+	agent Agent
+	deps  []int // indexes of the input steps
+	done  chan struct{}
 
-		out, err = ag.Run(ctx, in)
-		if err != nil {
-			log.Error("agent failed",
-				"agent", ag.Name(),
-				"error", err)
-			return "", fmt.Errorf("agent %s failed: %w", ag.Name(), err)
+	ran     bool
+	out     AgentOutput
+	flags   map[string]bool
+	results []AgentResult // what the node passes to the steps depending on it
+}
+
+// plan resolves the agents and inputs of steps.
+func (o *Orchestrator) plan(steps []PipelineStep, mode domain.InteractionMode) ([]*node, error) {
+	index := map[string]int{}
+	nodes := make([]*node, len(steps))
+	for i, step := range steps {
+		ag, ok := o.agents[step.Agent]
+		if !ok {
+			return nil, fmt.Errorf("unknown agent %q in the %s pipeline", step.Agent, mode)
+		}
+
+		n := &node{step: step, agent: ag, done: make(chan struct{})}
+		for _, in := range step.inputs(i, steps) {
+			if in == InputUser {
+				continue
+			}
+			d, ok := index[in]
+			if !ok {
+				return nil, fmt.Errorf("input %q of %s is not an earlier step of the %s pipeline", in, step.Agent, mode)
+			}
+			n.deps = append(n.deps, d)
 		}
 
-		elapsed := time.Since(start)
-		log.Info("agent rund end", "agent", ag.Name(), "elapsed_ms", elapsed.Milliseconds())
+		index[step.Agent] = i
+		nodes[i] = n
+	}
+	return nodes, nil
+}
+
+// runNode runs the agent of n within its timeout.
+func (o *Orchestrator) runNode(ctx context.Context, n *node, in AgentInput, emit func(Event)) (AgentOutput, error) {
+	name := n.agent.Name()
+	log := observability.LoggerFromContext(ctx)
+
+	timeout := time.Duration(n.step.Timeout)
+	if timeout <= 0 {
+		timeout = DefaultNodeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	log.Info("agent run start", "agent", name, "inputs", len(in.Upstream))
+	emit(Event{Type: EventAgentStart, Agent: name})
 
-		// The output of an agent is the input for the next agent
-		in.UserMessage = out.Reply
-		in.ConvCtx = out.UpdatedContext
+	out, err := n.agent.Run(observability.WithAgent(ctx, name), in)
+	if err == nil {
+		err = ctx.Err() // e.g. an agent that ignored the deadline
+	}
+	if err != nil {
+		// Keep the cause visible even if the agent did not wrap it
+		if cause := ctx.Err(); cause != nil && !errors.Is(err, cause) {
+			err = fmt.Errorf("%w: %w", cause, err)
+		}
+		log.Error("agent failed",
+			"agent", name,
+			"error", err)
+		return AgentOutput{}, fmt.Errorf("agent %s failed: %w", name, err)
 	}
 
-	// Return the last generated response
-	log.Info("orchestrator end")
-	return out.Reply, nil
+	elapsed := time.Since(start)
+	log.Info("agent run end", "agent", name, "elapsed_ms", elapsed.Milliseconds())
+	emit(Event{Type: EventAgentEnd, Agent: name, ElapsedMs: elapsed.Milliseconds()})
+	return out, nil
+}
+
+// appendResults appends the results not in dst yet.
+func appendResults(dst []AgentResult, results ...AgentResult) []AgentResult {
+	for _, r := range results {
+		if !slices.ContainsFunc(dst, func(d AgentResult) bool { return d.Agent == r.Agent }) {
+			dst = append(dst, r)
+		}
+	}
+	return dst
 }
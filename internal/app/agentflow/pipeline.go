@@ -0,0 +1,215 @@
+package agentflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// Flags agents can raise for the next steps of a pipeline.
+const (
+	// FlagJustVenting is raised by the Listener when the user wants to be
+	// heard rather than advised.
+	FlagJustVenting = "just_venting"
+)
+
+// knownFlags are the conditions a step may use in SkipIf.
+var knownFlags = []string{FlagJustVenting}
+
+// AgentNames are the agents a pipeline can use.
+var AgentNames = []string{"listener", "explorer", "planner", "reflector", "composer"}
+
+// InputUser is the input name of the original user message.
+const InputUser = "user"
+
+// DefaultNodeTimeout bounds a step that does not set its own Timeout.
+const DefaultNodeTimeout = 30 * time.Second
+
+// PipelineStep runs one agent once its inputs are ready, unless one of the
+// SkipIf flags was raised by one of the steps it depends on.
+type PipelineStep struct {
+	Agent string `json:"agent"`
+
+	// Inputs are InputUser and/or earlier steps (by agent name). Empty means
+	// the previous step (or the user message for the first step). Steps that
+	// do not depend on each other run concurrently.
+	Inputs []string `json:"inputs,omitempty"`
+
+	SkipIf  []string `json:"skip_if,omitempty"`
+	Timeout Duration `json:"timeout,omitempty"` // e.g. "20s", default DefaultNodeTimeout
+}
+
+// inputs resolves the default inputs of the i-th step of steps.
+func (s PipelineStep) inputs(i int, steps []PipelineStep) []string {
+	switch {
+	case len(s.Inputs) > 0:
+		return s.Inputs
+	case i == 0:
+		return []string{InputUser}
+	default:
+		return []string{steps[i-1].Agent}
+	}
+}
+
+// PipelineConfig holds the agents run for each interaction mode: a DAG
+// declared in order, whose last step that runs gives the reply.
+type PipelineConfig struct {
+	Pipelines map[domain.InteractionMode][]PipelineStep `json:"pipelines"`
+	Composer  ComposerConfig                            `json:"composer"`
+}
+
+// DefaultPipelineConfig is a short reply for check-ins, a reflection and an
+// exploration composed together for deep dives, and the full chain (plan
+// and journal) composed with the Listener for action plans.
+func DefaultPipelineConfig() PipelineConfig {
+	return PipelineConfig{Composer: DefaultComposerConfig(), Pipelines: map[domain.InteractionMode][]PipelineStep{
+		domain.ModeCheckIn: {
+			{Agent: "listener"},
+		},
+		domain.ModeDeepDive: {
+			{Agent: "listener", Inputs: []string{InputUser}},
+			{Agent: "explorer", Inputs: []string{InputUser}},
+			{Agent: "composer", Inputs: []string{"listener", "explorer"}},
+		},
+		domain.ModeActionPlan: {
+			{Agent: "listener", Inputs: []string{InputUser}},
+			{Agent: "planner", Inputs: []string{InputUser, "listener"}, SkipIf: []string{FlagJustVenting}},
+			{Agent: "reflector", Inputs: []string{InputUser, "planner"}},
+			{Agent: "composer", Inputs: []string{"listener", "planner", "reflector"}},
+		},
+	}}
+}
+
+// Duration is a time.Duration written as a string in JSON ("20s").
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"20s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// LoadPipelineConfig reads a JSON file like
+//
+//	{"pipelines": {"check_in": [{"agent": "listener"}], ...}}
+//
+// and validates it. The composer rules of the modes the file leaves out
+// keep their defaults.
+func LoadPipelineConfig(path string) (PipelineConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PipelineConfig{}, fmt.Errorf("reading pipelines: %w", err)
+	}
+
+	cfg := PipelineConfig{Composer: DefaultComposerConfig()}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return PipelineConfig{}, fmt.Errorf("decoding pipelines: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return PipelineConfig{}, err
+	}
+	return cfg, nil
+}
+
+// Validate checks that every mode has a pipeline of known agents, inputs and
+// conditions, and that the composer rules are sound.
+func (c PipelineConfig) Validate() error {
+	var problems []string
+
+	for _, mode := range domain.InteractionModes {
+		if len(c.Pipelines[mode]) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: missing pipeline", mode))
+		}
+	}
+
+	for _, mode := range sortedModes(c.Pipelines) {
+		if !mode.Valid() {
+			problems = append(problems, fmt.Sprintf("%s: unknown mode", mode))
+			continue
+		}
+
+		steps := c.Pipelines[mode]
+		seen := map[string]bool{}
+		for i, step := range steps {
+			where := fmt.Sprintf("%s[%d]", mode, i)
+			if !slices.Contains(AgentNames, step.Agent) {
+				problems = append(problems, fmt.Sprintf("%s: unknown agent %q (want one of %s)", where, step.Agent, strings.Join(AgentNames, ", ")))
+			}
+			if seen[step.Agent] {
+				problems = append(problems, fmt.Sprintf("%s: agent %q already runs in this pipeline", where, step.Agent))
+			}
+			if i == 0 && len(step.SkipIf) > 0 {
+				problems = append(problems, where+": the first step cannot be skipped")
+			}
+			if step.Timeout < 0 {
+				problems = append(problems, where+": negative timeout")
+			}
+			// Inputs must be declared earlier, so the graph has no cycles
+			for _, in := range step.inputs(i, steps) {
+				if in != InputUser && !seen[in] {
+					problems = append(problems, fmt.Sprintf("%s: input %q is not an earlier step", where, in))
+				}
+			}
+			seen[step.Agent] = true
+
+			for _, flag := range step.SkipIf {
+				if !slices.Contains(knownFlags, flag) {
+					problems = append(problems, fmt.Sprintf("%s: unknown condition %q (want one of %s)", where, flag, strings.Join(knownFlags, ", ")))
+				}
+			}
+		}
+	}
+	problems = append(problems, c.Composer.validate()...)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid pipelines: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// sortedModes returns the keys of m sorted, so errors come in a stable order.
+func sortedModes[V any](m map[domain.InteractionMode]V) []domain.InteractionMode {
+	modes := make([]domain.InteractionMode, 0, len(m))
+	for mode := range m {
+		modes = append(modes, mode)
+	}
+	slices.Sort(modes)
+	return modes
+}
+
+// For returns the pipeline of mode, falling back to the check-in one.
+func (c PipelineConfig) For(mode domain.InteractionMode) []PipelineStep {
+	if steps, ok := c.Pipelines[mode]; ok {
+		return steps
+	}
+	return c.Pipelines[domain.ModeCheckIn]
+}
+
+// skipped reports whether one of the step conditions was raised.
+func (s PipelineStep) skipped(flags map[string]bool) bool {
+	for _, f := range s.SkipIf {
+		if flags[f] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,313 @@
+package agentflow_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/llm"
+	"github.com/PabloGalante/farum-agent/internal/app/agentflow"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+func TestLoadPipelineConfig(t *testing.T) {
+	// The example file documents the defaults
+	cfg, err := agentflow.LoadPipelineConfig(filepath.Join("..", "..", "..", "config", "pipelines.json"))
+	if err != nil {
+		t.Fatalf("LoadPipelineConfig: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, agentflow.DefaultPipelineConfig()) {
+		t.Fatalf("config/pipelines.json differs from the defaults: %+v", cfg)
+	}
+
+	path := filepath.Join(t.TempDir(), "pipelines.json")
+	invalid := `{"pipelines": {
+		"check_in": [{"agent": "listener", "skip_if": ["just_venting"]}],
+		"action_plan": [{"agent": "listener"}, {"agent": "therapist", "skip_if": ["sunny"]}],
+		"small_talk": [{"agent": "listener"}]
+	}}`
+	if err := os.WriteFile(path, []byte(invalid), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = agentflow.LoadPipelineConfig(path)
+	if err == nil {
+		t.Fatalf("expected an invalid config error")
+	}
+	for _, want := range []string{
+		"deep_dive: missing pipeline",
+		"check_in[0]: the first step cannot be skipped",
+		`action_plan[1]: unknown agent "therapist"`,
+		`action_plan[1]: unknown condition "sunny"`,
+		"small_talk: unknown mode",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
+
+func TestOrchestratorRunsThePipelineOfTheMode(t *testing.T) {
+	run := func(o *agentflow.Orchestrator, mode domain.InteractionMode, text string) (*agentflow.TurnResult, []string) {
+		t.Helper()
+
+		var events []string
+		res, err := o.RunTurn(context.Background(), text, domain.ConversationContext{
+			Mode:    mode,
+			History: []*domain.Message{{Author: domain.RoleUser, Text: text}},
+		}, func(ev agentflow.Event) {
+			if ev.Type != agentflow.EventToken {
+				events = append(events, string(ev.Type)+":"+ev.Agent)
+			}
+		})
+		if err != nil {
+			t.Fatalf("RunTurn(%s): %v", mode, err)
+		}
+		return res, events
+	}
+
+	o := agentflow.NewDefaultOrchestrator(llm.NewMockLLM(), nil)
+
+	_, events := run(o, domain.ModeCheckIn, "Hoy estoy cansada")
+	if got := strings.Join(events, ","); got != "agent_start:listener,agent_end:listener" {
+		t.Fatalf("unexpected check_in events: %s", got)
+	}
+
+	// The Listener and the Explorer run in any order, then the Composer
+	res, events := run(o, domain.ModeDeepDive, "Siempre me pasa lo mismo con mi jefe")
+	if len(events) != 6 || events[len(events)-1] != "agent_end:composer" ||
+		!slices.Contains(events, "agent_end:listener") || !slices.Contains(events, "agent_end:explorer") {
+		t.Fatalf("unexpected deep_dive events: %v", events)
+	}
+	if !strings.Contains(res.Reply, "capas") || !strings.HasSuffix(res.Reply, "?") {
+		t.Fatalf("expected the listener and explorer replies composed, got %q", res.Reply)
+	}
+
+	res, events = run(o, domain.ModeActionPlan, "No quiero consejos, solo quiero desahogarme un rato")
+	want := "agent_start:listener,agent_end:listener,agent_skipped:planner,agent_start:reflector,agent_end:reflector,agent_start:composer,agent_end:composer"
+	if got := strings.Join(events, ","); got != want {
+		t.Fatalf("expected the planner to be skipped:\n got: %s\nwant: %s", got, want)
+	}
+	if strings.Contains(res.Reply, "1.") {
+		t.Fatalf("expected no plan in the reply, got %q", res.Reply)
+	}
+
+	res, _ = run(o, domain.ModeActionPlan, "Necesito organizarme con el trabajo, ¿qué puedo hacer?")
+	if !strings.Contains(res.Reply, "1.") {
+		t.Fatalf("expected the plan in the composed reply, got %q", res.Reply)
+	}
+}
+
+// fakeAgent replies with its name after waiting for release (if set) or for
+// the context to be done.
+type fakeAgent struct {
+	name    string
+	started chan<- string
+	release <-chan struct{}
+	inputs  chan<- []string
+}
+
+func (a *fakeAgent) Name() string { return a.name }
+
+func (a *fakeAgent) Run(ctx context.Context, in agentflow.AgentInput) (agentflow.AgentOutput, error) {
+	if a.inputs != nil {
+		var names []string
+		for _, up := range in.Upstream {
+			names = append(names, up.Agent)
+		}
+		a.inputs <- names
+	}
+	if a.started != nil {
+		a.started <- a.name
+	}
+	if a.release != nil {
+		select {
+		case <-a.release:
+		case <-ctx.Done():
+			return agentflow.AgentOutput{}, ctx.Err()
+		}
+	}
+	return agentflow.AgentOutput{Reply: a.name, UpdatedContext: in.ConvCtx}, nil
+}
+
+func TestOrchestratorRunsIndependentAgentsConcurrently(t *testing.T) {
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	inputs := make(chan []string, 1)
+
+	o := agentflow.NewDefaultOrchestrator(llm.NewMockLLM(), nil,
+		agentflow.WithAgent(&fakeAgent{name: "listener", started: started, release: release}),
+		agentflow.WithAgent(&fakeAgent{name: "explorer", started: started, release: release}),
+		agentflow.WithAgent(&fakeAgent{name: "composer", inputs: inputs}),
+	)
+
+	// Both agents must be running at the same time before either can finish
+	go func() {
+		<-started
+		<-started
+		close(release)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := o.RunTurn(ctx, "hola", domain.ConversationContext{Mode: domain.ModeDeepDive}, nil)
+	if err != nil {
+		t.Fatalf("RunTurn: %v", err)
+	}
+	if res.Reply != "composer" {
+		t.Fatalf("expected the composer reply, got %q", res.Reply)
+	}
+	if got := <-inputs; !reflect.DeepEqual(got, []string{"listener", "explorer"}) {
+		t.Fatalf("expected the composer inputs in declaration order, got %v", got)
+	}
+}
+
+func TestOrchestratorCancelsTheTurnWhenANodeTimesOut(t *testing.T) {
+	started := make(chan string, 2)
+	never := make(chan struct{})
+
+	o := agentflow.NewDefaultOrchestrator(llm.NewMockLLM(), nil,
+		agentflow.WithAgent(&fakeAgent{name: "listener", started: started, release: never}),
+		agentflow.WithAgent(&fakeAgent{name: "explorer", started: started, release: never}),
+		agentflow.WithPipelines(agentflow.PipelineConfig{
+			Pipelines: map[domain.InteractionMode][]agentflow.PipelineStep{
+				domain.ModeCheckIn: {
+					{Agent: "listener", Inputs: []string{agentflow.InputUser}, Timeout: agentflow.Duration(20 * time.Millisecond)},
+					{Agent: "explorer", Inputs: []string{agentflow.InputUser}},
+					{Agent: "composer", Inputs: []string{"listener", "explorer"}},
+				},
+			},
+		}),
+	)
+
+	// The explorer has the default timeout: it only ends because the
+	// listener timing out cancels the turn
+	done := make(chan error, 1)
+	go func() {
+		_, err := o.RunTurn(context.Background(), "hola", domain.ConversationContext{Mode: domain.ModeCheckIn}, nil)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "agent listener failed") {
+			t.Fatalf("expected the listener deadline error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the turn was not cancelled")
+	}
+}
+
+func TestPipelineConfigValidatesInputs(t *testing.T) {
+	cfg := agentflow.DefaultPipelineConfig()
+	cfg.Pipelines[domain.ModeDeepDive] = []agentflow.PipelineStep{
+		{Agent: "listener", Inputs: []string{"explorer"}},
+		{Agent: "explorer", Timeout: agentflow.Duration(-time.Second)},
+		{Agent: "explorer"},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatalf("expected an invalid config error")
+	}
+	for _, want := range []string{
+		`deep_dive[0]: input "explorer" is not an earlier step`,
+		"deep_dive[1]: negative timeout",
+		`deep_dive[2]: agent "explorer" already runs in this pipeline`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
+
+func TestOrchestratorStreamsReplyWhenTheRestIsSkipped(t *testing.T) {
+	o := agentflow.NewDefaultOrchestrator(llm.NewMockLLM(), nil, agentflow.WithPipelines(agentflow.PipelineConfig{
+		Pipelines: map[domain.InteractionMode][]agentflow.PipelineStep{
+			domain.ModeCheckIn: {
+				{Agent: "listener"},
+				{Agent: "planner", SkipIf: []string{agentflow.FlagJustVenting}},
+			},
+		},
+	}))
+
+	var tokens strings.Builder
+	res, err := o.RunTurn(context.Background(), "I just need to vent", domain.ConversationContext{Mode: domain.ModeCheckIn}, func(ev agentflow.Event) {
+		if ev.Type == agentflow.EventToken {
+			if ev.Agent != "listener" {
+				t.Errorf("expected the listener tokens, got %+v", ev)
+			}
+			tokens.WriteString(ev.Text)
+		}
+	})
+	if err != nil {
+		t.Fatalf("RunTurn: %v", err)
+	}
+	if res.Reply == "" || tokens.String() != res.Reply {
+		t.Fatalf("expected the listener reply as tokens, got %q vs %q", tokens.String(), res.Reply)
+	}
+}
+
+// promptLLM keeps the prompts it got.
+type promptLLM struct{ prompts []string }
+
+func (p *promptLLM) GenerateReply(ctx context.Context, prompt string, convCtx domain.ConversationContext) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	return "ok", nil
+}
+
+func (p *promptLLM) last() string {
+	if len(p.prompts) == 0 {
+		return ""
+	}
+	return p.prompts[len(p.prompts)-1]
+}
+
+func TestAgentPromptsLabelTheUserMessage(t *testing.T) {
+	model := &promptLLM{}
+	explorer := agentflow.NewExplorerAgent(model)
+	text := "Siempre me pasa lo mismo con mi jefe"
+
+	// Without upstream agents the Explorer works on the user's own words
+	if _, err := explorer.Run(context.Background(), agentflow.AgentInput{UserMessage: text, OriginalMessage: text}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.HasSuffix(model.last(), "User message:\n"+text) || strings.Contains(model.last(), "Previous agent output:") {
+		t.Fatalf("expected the text labeled as the user message, got %q", model.last())
+	}
+
+	in := agentflow.AgentInput{
+		UserMessage:     "Te escucho",
+		OriginalMessage: text,
+		Upstream:        []agentflow.AgentResult{{Agent: "listener", Output: agentflow.AgentOutput{Reply: "Te escucho"}}},
+	}
+	if _, err := explorer.Run(context.Background(), in); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.HasSuffix(model.last(), "User message:\n"+text+"\n\nPrevious agent output:\nTe escucho") {
+		t.Fatalf("expected the user message and the listener output, got %q", model.last())
+	}
+
+	// Nor does the Listener take another agent's output for the user's words
+	in = agentflow.AgentInput{
+		UserMessage:     "Parece que solo querés desahogarte",
+		OriginalMessage: text,
+		Upstream:        []agentflow.AgentResult{{Agent: "explorer", Output: agentflow.AgentOutput{Reply: "Parece que solo querés desahogarte"}}},
+	}
+	out, err := agentflow.NewListenerAgent(model).Run(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.HasSuffix(model.last(), "User message:\n"+text+"\n\nPrevious agent output:\n"+in.UserMessage) {
+		t.Fatalf("expected the user message and the explorer output, got %q", model.last())
+	}
+	if len(out.Flags) != 0 {
+		t.Fatalf("expected no flags from the explorer output, got %v", out.Flags)
+	}
+}
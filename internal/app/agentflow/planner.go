@@ -2,19 +2,39 @@ package agentflow
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
+	"github.com/PabloGalante/farum-agent/internal/app/knowledge"
+	"github.com/PabloGalante/farum-agent/internal/app/tools"
 	"github.com/PabloGalante/farum-agent/internal/domain"
 	"github.com/PabloGalante/farum-agent/internal/observability"
 )
 
+// plannerTools are the tools the Planner may call: looking up resources,
+// never writing the journal (that is the Reflector's job).
+var plannerTools = []string{"knowledge_search", "web_search"}
+
+// plannerKnowledgeHits is how many knowledge base chunks go into the prompt.
+const plannerKnowledgeHits = 3
+
 // PlannerAgent: transforms the clarified problem into a concrete action plan.
 type PlannerAgent struct {
-	llm domain.LLMClient
+	llm       domain.LLMClient
+	toolLoop  *ToolLoop
+	knowledge domain.KnowledgeRetriever
 }
 
-func NewPlannerAgent(llm domain.LLMClient) *PlannerAgent {
-	return &PlannerAgent{llm: llm}
+// NewPlannerAgent creates the Planner. With a tool-calling LLM it may use
+// the knowledge_search and web_search tools of registry (nil means no
+// tools). When knowledge is set, the most relevant chunks of the knowledge
+// base are added to the prompt and returned as citations.
+func NewPlannerAgent(llm domain.LLMClient, registry *tools.ToolRegistry, knowledge domain.KnowledgeRetriever) *PlannerAgent {
+	return &PlannerAgent{
+		llm:       llm,
+		toolLoop:  NewToolLoop(llm, registry.Only(plannerTools...)),
+		knowledge: knowledge,
+	}
 }
 
 func (a *PlannerAgent) Name() string {
@@ -25,22 +45,92 @@ func (a *PlannerAgent) Run(ctx context.Context, in AgentInput) (AgentOutput, err
 	log := observability.LoggerFromContext(ctx).With("agent", a.Name())
 	log.Info("planner agent running")
 
+	hits := a.retrieve(ctx, in.UserMessage)
+
+	reference := ""
+	if len(hits) > 0 {
+		reference = "Reference material from Farum's vetted library. Base the steps on it when relevant " +
+			"and cite it as [1], [2]...:\n" + knowledge.FormatContext(hits) + "\n\n"
+	}
+
 	prompt := fmt.Sprintf(
 		"You are Farum's Planner agent. The Listener agent has clarified the user's concern.\n"+
 			"Now your job is to create a short, concrete action plan with 2-4 steps that the user can follow.\n"+
-			"Be realistic, kind and practical.\n\nPrevious agent output:\n%s",
-		in.UserMessage,
+			"Be realistic, kind and practical.\n\n%s%s",
+		reference,
+		inputSection(in),
+	)
+
+	var (
+		reply     string
+		exchanges []domain.ToolExchange
+		err       error
 	)
+	if a.toolLoop.Enabled(ctx) {
+		withTools := prompt + "\n\nIf it helps, use the available tools to find an exercise or a reputable resource " +
+			"(a helpline, a psychoeducation page) and include it in one of the steps. Never invent URLs."
 
-	reply, err := a.llm.GenerateReply(ctx, prompt, in.ConvCtx)
+		reply, exchanges, err = a.toolLoop.Run(ctx, withTools, in.ConvCtx, in.OnChunk)
+		if err != nil {
+			log.Warn("planner tool loop failed, retrying without tools", "error", err)
+			reply, err = generateReply(ctx, a.llm, prompt, in.ConvCtx, in.OnChunk)
+		}
+	} else {
+		reply, err = generateReply(ctx, a.llm, prompt, in.ConvCtx, in.OnChunk)
+	}
 	if err != nil {
 		log.Error("planner agent error", "error", err)
 		return AgentOutput{}, err
 	}
 
-	log.Info("planner agent success")
+	citations := append(knowledge.Citations(hits), knowledgeToolCitations(exchanges)...)
+
+	log.Info("planner agent success", "citations", len(citations))
 	return AgentOutput{
 		Reply:          reply,
 		UpdatedContext: in.ConvCtx,
+		ContentType:    ContentTypeTaskList,
+		Citations:      citations,
 	}, nil
 }
+
+// retrieve is best-effort: without reference material the Planner still works.
+func (a *PlannerAgent) retrieve(ctx context.Context, query string) []domain.KnowledgeHit {
+	if a.knowledge == nil {
+		return nil
+	}
+
+	hits, err := a.knowledge.Search(ctx, query, plannerKnowledgeHits)
+	if err != nil {
+		observability.LoggerFromContext(ctx).Warn("knowledge retrieval failed", "agent", a.Name(), "error", err)
+		return nil
+	}
+	return hits
+}
+
+// knowledgeToolCitations returns the chunks the model got from knowledge_search.
+func knowledgeToolCitations(exchanges []domain.ToolExchange) []domain.Citation {
+	var out []domain.Citation
+	for _, ex := range exchanges {
+		for _, r := range ex.Results {
+			if r.Name != "knowledge_search" || r.IsError {
+				continue
+			}
+
+			var res struct {
+				Results []struct {
+					ID     string `json:"id"`
+					Source string `json:"source"`
+					Title  string `json:"title"`
+				} `json:"results"`
+			}
+			if err := json.Unmarshal([]byte(r.Content), &res); err != nil {
+				continue
+			}
+			for _, c := range res.Results {
+				out = append(out, domain.Citation{ChunkID: c.ID, Source: c.Source, Title: c.Title})
+			}
+		}
+	}
+	return out
+}
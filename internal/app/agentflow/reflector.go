@@ -9,16 +9,25 @@ import (
 	"github.com/PabloGalante/farum-agent/internal/observability"
 )
 
+// journalToolName is the tool the Reflector makes sure gets called.
+const journalToolName = "journal_store"
+
 // ReflectorAgent: helps close the interaction with a brief reflection.
+// With a tool-calling LLM it may call the registered tools itself; the
+// journal is still written through the extractor if the model did not.
 type ReflectorAgent struct {
-	llm         domain.LLMClient
-	journalTool tools.Tool
+	llm       domain.LLMClient
+	registry  *tools.ToolRegistry
+	toolLoop  *ToolLoop
+	extractor *JournalExtractor
 }
 
-func NewReflectorAgent(llm domain.LLMClient, journalTool tools.Tool) *ReflectorAgent {
+func NewReflectorAgent(llm domain.LLMClient, registry *tools.ToolRegistry) *ReflectorAgent {
 	return &ReflectorAgent{
-		llm:         llm,
-		journalTool: journalTool,
+		llm:       llm,
+		registry:  registry,
+		toolLoop:  NewToolLoop(llm, registry),
+		extractor: NewJournalExtractor(llm),
 	}
 }
 
@@ -34,39 +43,55 @@ func (a *ReflectorAgent) Run(ctx context.Context, in AgentInput) (AgentOutput, e
 		"You are Farum's Reflector agent. The Planner agent proposed an action plan.\n"+
 			"Your job is to close the conversation with a short reflective message that helps the user\n"+
 			"connect emotionally with the plan, and maybe ask 1 gentle question for journaling.\n\n"+
-			"Previous agent output:\n%s",
-		in.UserMessage,
+			"%s",
+		inputSection(in),
+	)
+
+	var (
+		reply     string
+		exchanges []domain.ToolExchange
+		err       error
 	)
+	if a.toolLoop.Enabled(ctx) {
+		withTools := prompt + "\n\nYou can call the available tools before answering. " +
+			"Save this conversation to the user's journal with the " + journalToolName + " tool if it is available."
 
-	reply, err := a.llm.GenerateReply(ctx, prompt, in.ConvCtx)
+		reply, exchanges, err = a.toolLoop.Run(ctx, withTools, in.ConvCtx, in.OnChunk)
+		if err != nil {
+			// Tools are a bonus: answer without them rather than failing the turn
+			// (exchanges still tells which tools already ran)
+			log.Warn("reflector tool loop failed, retrying without tools", "error", err)
+			reply, err = generateReply(ctx, a.llm, prompt, in.ConvCtx, in.OnChunk)
+		}
+	} else {
+		reply, err = generateReply(ctx, a.llm, prompt, in.ConvCtx, in.OnChunk)
+	}
 	if err != nil {
 		log.Error("reflector agent error", "error", err)
 		return AgentOutput{}, err
 	}
 
 	updatedCtx := in.ConvCtx
-	if a.journalTool != nil {
+	if _, ok := a.registry.Get(journalToolName); ok && !calledSuccessfully(exchanges, journalToolName) {
 		tctx := tools.ToolContext{
 			UserID:    string(in.ConvCtx.UserID),
 			SessionID: string(in.ConvCtx.SessionID),
-			RequestID: "",
+			RequestID: observability.RequestIDFromContext(ctx),
 		}
 
-		// MVP
-		input := map[string]any{
-			"problem_summary": "",
-			"reflection":      reply,
-			"mood_before":     "",
-			"mood_after":      "",
-			"actions":         []any{},
-		}
+		// in.UserMessage holds the Planner's output (the action plan)
+		input := a.extractor.BuildToolInput(ctx, in.UserMessage, reply, in.ConvCtx)
 
-		_, _ = a.journalTool.Call(ctx, tctx, input)
+		// Journaling is best-effort: a failure here should not break the reply
+		if _, err := a.registry.Call(ctx, tctx, journalToolName, input); err != nil {
+			log.Warn("journal tool failed", "error", err)
+		}
 	}
 
 	log.Info("reflector agent success")
 	return AgentOutput{
 		Reply:          reply,
 		UpdatedContext: updatedCtx,
+		ContentType:    ContentTypeReflection,
 	}, nil
 }
@@ -0,0 +1,132 @@
+package agentflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/PabloGalante/farum-agent/internal/app/tools"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// defaultMaxToolIterations caps the model ↔ tools round trips of one agent run.
+const defaultMaxToolIterations = 4
+
+// ErrToolIterationsExceeded is returned when the model keeps asking for
+// tools after maxIterations rounds.
+var ErrToolIterationsExceeded = errors.New("tool loop: too many iterations")
+
+// ToolLoop lets the model call the tools of a ToolRegistry: every tool call
+// is executed and its result fed back, until the model answers with text.
+type ToolLoop struct {
+	llm           domain.LLMClient
+	registry      *tools.ToolRegistry
+	maxIterations int
+}
+
+// NewToolLoop creates a loop with the default iteration guard.
+func NewToolLoop(llm domain.LLMClient, registry *tools.ToolRegistry) *ToolLoop {
+	return &ToolLoop{
+		llm:           llm,
+		registry:      registry,
+		maxIterations: defaultMaxToolIterations,
+	}
+}
+
+// Enabled reports whether the model can be offered tools at all for a call
+// made with ctx (a router may route the calling agent to a provider without
+// tool calling).
+func (l *ToolLoop) Enabled(ctx context.Context) bool {
+	return l.registry.Len() > 0 && domain.SupportsTools(ctx, l.llm)
+}
+
+// Run returns the final text and the tool exchanges that led to it.
+// Without tool support it is a plain generateReply. The final text is
+// delivered to onChunk (if set) in one piece.
+func (l *ToolLoop) Run(
+	ctx context.Context,
+	prompt string,
+	convCtx domain.ConversationContext,
+	onChunk func(chunk string) error,
+) (string, []domain.ToolExchange, error) {
+	if !l.Enabled(ctx) {
+		reply, err := generateReply(ctx, l.llm, prompt, convCtx, onChunk)
+		return reply, nil, err
+	}
+
+	log := observability.LoggerFromContext(ctx).With("agent", observability.AgentFromContext(ctx))
+	llm := l.llm.(domain.ToolCallingLLMClient)
+	specs := l.registry.Specs()
+
+	var exchanges []domain.ToolExchange
+	for i := 0; i < l.maxIterations; i++ {
+		turn, err := llm.GenerateWithTools(ctx, prompt, convCtx, specs, exchanges)
+		if err != nil {
+			return "", exchanges, err
+		}
+
+		if len(turn.Calls) == 0 {
+			if turn.Text == "" {
+				return "", exchanges, fmt.Errorf("tool loop: model returned empty text")
+			}
+			if onChunk != nil {
+				if err := onChunk(turn.Text); err != nil {
+					return "", exchanges, err
+				}
+			}
+			return turn.Text, exchanges, nil
+		}
+
+		ex := domain.ToolExchange{Text: turn.Text, Calls: turn.Calls}
+		for _, call := range turn.Calls {
+			result := l.execute(ctx, convCtx, call)
+			log.Info("tool call", "tool", call.Name, "iteration", i+1, "is_error", result.IsError)
+			ex.Results = append(ex.Results, result)
+		}
+		exchanges = append(exchanges, ex)
+	}
+
+	return "", exchanges, fmt.Errorf("%w (%d)", ErrToolIterationsExceeded, l.maxIterations)
+}
+
+// execute runs one call. Tool errors are sent back to the model as an
+// error result instead of failing the agent, so it can recover.
+func (l *ToolLoop) execute(ctx context.Context, convCtx domain.ConversationContext, call domain.ToolCall) domain.ToolResult {
+	result := domain.ToolResult{CallID: call.ID, Name: call.Name}
+
+	tctx := tools.ToolContext{
+		UserID:    string(convCtx.UserID),
+		SessionID: string(convCtx.SessionID),
+		RequestID: observability.RequestIDFromContext(ctx),
+	}
+
+	out, err := l.registry.Call(ctx, tctx, call.Name, call.Args)
+	if err != nil {
+		result.IsError = true
+		result.Content = err.Error()
+		return result
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		result.IsError = true
+		result.Content = fmt.Sprintf("encoding tool output: %v", err)
+		return result
+	}
+	result.Content = string(data)
+	return result
+}
+
+// calledSuccessfully reports whether the model ran the named tool without error.
+func calledSuccessfully(exchanges []domain.ToolExchange, name string) bool {
+	for _, ex := range exchanges {
+		for _, r := range ex.Results {
+			if r.Name == name && !r.IsError {
+				return true
+			}
+		}
+	}
+	return false
+}
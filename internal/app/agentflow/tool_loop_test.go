@@ -0,0 +1,161 @@
+package agentflow_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/llm"
+	"github.com/PabloGalante/farum-agent/internal/adapters/storage/memory"
+	"github.com/PabloGalante/farum-agent/internal/app/agentflow"
+	"github.com/PabloGalante/farum-agent/internal/app/tools"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// countTool counts its calls and echoes the session it ran for.
+type countTool struct {
+	calls int
+}
+
+func (c *countTool) Name() string { return "counter" }
+
+func (c *countTool) Call(ctx context.Context, tctx tools.ToolContext, input map[string]any) (map[string]any, error) {
+	c.calls++
+	return map[string]any{"calls": c.calls, "session_id": tctx.SessionID}, nil
+}
+
+func newRegistry(t *testing.T, ts ...tools.Tool) *tools.ToolRegistry {
+	t.Helper()
+
+	r, err := tools.NewToolRegistry(ts...)
+	if err != nil {
+		t.Fatalf("NewToolRegistry: %v", err)
+	}
+	return r
+}
+
+func TestToolLoopRunsToolsUntilText(t *testing.T) {
+	counter := &countTool{}
+	mock := llm.NewMockLLM()
+	mock.ScriptToolTurns(
+		domain.ToolTurn{Calls: []domain.ToolCall{
+			{ID: "c1", Name: "counter"},
+			{ID: "c2", Name: "missing"},
+		}},
+		domain.ToolTurn{Calls: []domain.ToolCall{{ID: "c3", Name: "counter"}}},
+		domain.ToolTurn{Text: "Listo."},
+	)
+
+	var chunks []string
+	loop := agentflow.NewToolLoop(mock, newRegistry(t, counter))
+	reply, exchanges, err := loop.Run(context.Background(), "hola", domain.ConversationContext{SessionID: "s1"}, func(c string) error {
+		chunks = append(chunks, c)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if reply != "Listo." || strings.Join(chunks, "") != "Listo." {
+		t.Fatalf("unexpected reply %q (chunks %q)", reply, chunks)
+	}
+	if counter.calls != 2 || len(exchanges) != 2 {
+		t.Fatalf("expected 2 tool runs in 2 exchanges, got %d runs, %d exchanges", counter.calls, len(exchanges))
+	}
+
+	first := exchanges[0].Results
+	if first[0].IsError || first[0].CallID != "c1" || !strings.Contains(first[0].Content, `"session_id":"s1"`) {
+		t.Fatalf("unexpected result for counter: %+v", first[0])
+	}
+	if !first[1].IsError || !strings.Contains(first[1].Content, "unknown tool") {
+		t.Fatalf("expected an error result for the unknown tool, got %+v", first[1])
+	}
+}
+
+func TestToolLoopMaxIterations(t *testing.T) {
+	mock := llm.NewMockLLM()
+	for i := 0; i < 10; i++ {
+		mock.ScriptToolTurns(domain.ToolTurn{Calls: []domain.ToolCall{{ID: "c", Name: "counter"}}})
+	}
+
+	loop := agentflow.NewToolLoop(mock, newRegistry(t, &countTool{}))
+	_, exchanges, err := loop.Run(context.Background(), "hola", domain.ConversationContext{}, nil)
+	if !errors.Is(err, agentflow.ErrToolIterationsExceeded) {
+		t.Fatalf("expected ErrToolIterationsExceeded, got %v", err)
+	}
+	if len(exchanges) == 0 {
+		t.Fatalf("expected the exchanges run before giving up")
+	}
+}
+
+func TestToolLoopWithoutToolSupport(t *testing.T) {
+	plain := &scriptedLLM{replies: []string{"Sin herramientas."}}
+
+	loop := agentflow.NewToolLoop(plain, newRegistry(t, &countTool{}))
+	if loop.Enabled(context.Background()) {
+		t.Fatalf("expected the loop to be disabled for an LLM without tool calling")
+	}
+
+	reply, exchanges, err := loop.Run(context.Background(), "hola", domain.ConversationContext{}, nil)
+	if err != nil || reply != "Sin herramientas." || len(exchanges) != 0 {
+		t.Fatalf("expected a plain reply, got %q, %d exchanges, %v", reply, len(exchanges), err)
+	}
+}
+
+func TestReflectorJournalsOnceWithToolCalling(t *testing.T) {
+	store := memory.NewJournalStore()
+	mock := llm.NewMockLLM()
+
+	convCtx := domain.ConversationContext{
+		UserID:    "u1",
+		SessionID: "s1",
+		Mode:      domain.ModeCheckIn,
+		History:   []*domain.Message{{Author: domain.RoleUser, Text: "Estoy cansado"}},
+	}
+
+	reflector := agentflow.NewReflectorAgent(mock, newRegistry(t, tools.NewJournalTool(store)))
+	out, err := reflector.Run(context.Background(), agentflow.AgentInput{
+		UserMessage: "1. Dormir temprano.\n2. Salir a caminar.",
+		ConvCtx:     convCtx,
+	})
+	if err != nil {
+		t.Fatalf("reflector: %v", err)
+	}
+
+	entries, _ := store.ListJournalEntriesByUser("u1", 0)
+	if len(entries) != 1 {
+		t.Fatalf("expected exactly 1 journal entry, got %d", len(entries))
+	}
+	if entries[0].Reflection != out.Reply || len(entries[0].ActionPlan) != 2 {
+		t.Fatalf("unexpected journal entry: %+v", entries[0])
+	}
+}
+
+// brokenToolsLLM fails every tool-calling request and keeps the prompt of the
+// plain replies.
+type brokenToolsLLM struct{ promptLLM }
+
+func (b *brokenToolsLLM) GenerateWithTools(ctx context.Context, prompt string, convCtx domain.ConversationContext, specs []domain.ToolSpec, exchanges []domain.ToolExchange) (domain.ToolTurn, error) {
+	return domain.ToolTurn{}, errors.New("tools down")
+}
+
+func TestAgentsFallBackToAPromptWithoutTools(t *testing.T) {
+	model := &brokenToolsLLM{}
+	registry := newRegistry(t, tools.NewJournalTool(memory.NewJournalStore()), tools.NewKnowledgeSearchTool(nil))
+	in := agentflow.AgentInput{UserMessage: "1. Dormir temprano.", ConvCtx: domain.ConversationContext{UserID: "u1", SessionID: "s1"}}
+
+	for _, agent := range []agentflow.Agent{
+		agentflow.NewReflectorAgent(model, registry),
+		agentflow.NewPlannerAgent(model, registry, nil),
+	} {
+		model.prompts = nil
+		if _, err := agent.Run(context.Background(), in); err != nil {
+			t.Fatalf("%s: %v", agent.Name(), err)
+		}
+		// The first plain reply is the fallback (the Reflector then journals)
+		if len(model.prompts) == 0 || strings.Contains(model.prompts[0], "tools") {
+			t.Fatalf("%s: expected a fallback prompt without tools, got %q", agent.Name(), model.prompts)
+		}
+	}
+}
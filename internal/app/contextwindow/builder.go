@@ -0,0 +1,193 @@
+// Package contextwindow fits the history of a session into the token budget
+// of the model, summarizing the turns that fall out of the window so long,
+// days-long sessions stay coherent.
+package contextwindow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// Config tunes the builder. Zero values use the defaults.
+type Config struct {
+	// TokenBudget bounds summary + history (default DefaultTokenBudget, see
+	// BudgetForModel).
+	TokenBudget int
+
+	// SummaryTokens is the share of TokenBudget kept for the summary once
+	// some messages no longer fit (default 300).
+	SummaryTokens int
+
+	// MessageOverhead is added to the tokens of every message for its role
+	// and separators (default 4).
+	MessageOverhead int
+
+	// MemoryTokens bounds the long-term memories recalled into the system
+	// prompt, outside TokenBudget (default 300).
+	MemoryTokens int
+}
+
+// Summarizer folds messages into the previous summary of a session.
+type Summarizer interface {
+	Summarize(ctx context.Context, previous string, msgs []*domain.Message, maxTokens int) (string, error)
+}
+
+// Window is the part of a session the agents see.
+type Window struct {
+	History []*domain.Message
+	Summary string // summary of the messages before History, if any
+
+	// Updated reports whether the summary was regenerated; the session
+	// should then store Summary and SummaryThrough.
+	Updated        bool
+	SummaryThrough domain.MessageID
+}
+
+// Builder builds the context window of each turn.
+type Builder struct {
+	tokenizer  domain.Tokenizer
+	summarizer Summarizer
+	cfg        Config
+}
+
+// NewBuilder creates a builder. A nil tokenizer uses HeuristicTokenizer; a
+// nil summarizer keeps the older messages out without summarizing them.
+func NewBuilder(tokenizer domain.Tokenizer, summarizer Summarizer, cfg Config) *Builder {
+	if tokenizer == nil {
+		tokenizer = HeuristicTokenizer{}
+	}
+	if cfg.TokenBudget <= 0 {
+		cfg.TokenBudget = DefaultTokenBudget
+	}
+	if cfg.SummaryTokens <= 0 {
+		cfg.SummaryTokens = 300
+	}
+	if cfg.MessageOverhead <= 0 {
+		cfg.MessageOverhead = 4
+	}
+	if cfg.MemoryTokens <= 0 {
+		cfg.MemoryTokens = 300
+	}
+	return &Builder{
+		tokenizer:  tokenizer,
+		summarizer: summarizer,
+		cfg:        cfg,
+	}
+}
+
+// TokenBudget returns the budget of summary + history.
+func (b *Builder) TokenBudget() int {
+	return b.cfg.TokenBudget
+}
+
+// Build fits history, the messages of session after its summary (oldest
+// first), in the budget; the newest message is always kept. When older
+// messages do not fit, they are folded into the summary together with the
+// next ones, down to half the history budget, so the following turns fit
+// without summarizing again. A failing summarizer is logged and the previous
+// summary is used.
+func (b *Builder) Build(ctx context.Context, session *domain.Session, history []*domain.Message) Window {
+	w := Window{
+		History:        history,
+		Summary:        session.Summary,
+		SummaryThrough: session.SummaryThrough,
+	}
+	if len(history) == 0 {
+		return w
+	}
+
+	// Room for the summary is kept once there is one, or will be one
+	budget := b.cfg.TokenBudget
+	if session.Summary != "" {
+		budget -= b.cfg.SummaryTokens
+	}
+	cut := b.cut(history, budget)
+	if cut > 0 && session.Summary == "" {
+		budget -= b.cfg.SummaryTokens
+		cut = b.cut(history, budget)
+	}
+	if cut == 0 {
+		return w
+	}
+
+	w.History = history[cut:]
+	if b.summarizer == nil {
+		return w
+	}
+
+	cut = max(b.cut(history, budget/2), cut)
+	missing := history[:cut]
+
+	log := observability.LoggerFromContext(ctx).With("session_id", session.ID)
+	summary, err := b.summarizer.Summarize(ctx, session.Summary, missing, b.cfg.SummaryTokens)
+	if err != nil {
+		log.Warn("failed to summarize the conversation", "error", err, "messages", len(missing))
+		return w
+	}
+
+	log.Info("conversation summarized",
+		"messages", len(missing),
+		"summary_tokens", b.tokenizer.CountTokens(summary),
+		"history_messages", len(history)-cut,
+	)
+	w.History = history[cut:]
+	w.Summary = summary
+	w.SummaryThrough = history[cut-1].ID
+	w.Updated = true
+	return w
+}
+
+// Summarize folds history, the messages of session after its summary, into
+// the summary, e.g. when the session is closed. The Window has no History;
+// it is not Updated when there was nothing new to summarize.
+func (b *Builder) Summarize(ctx context.Context, session *domain.Session, history []*domain.Message) (Window, error) {
+	w := Window{Summary: session.Summary, SummaryThrough: session.SummaryThrough}
+	if len(history) == 0 || b.summarizer == nil {
+		return w, nil
+	}
+
+	summary, err := b.summarizer.Summarize(ctx, session.Summary, history, b.cfg.SummaryTokens)
+	if err != nil {
+		return w, fmt.Errorf("summarizing %d messages: %w", len(history), err)
+	}
+
+	observability.LoggerFromContext(ctx).Info("conversation summarized",
+		"session_id", session.ID,
+		"messages", len(history),
+		"summary_tokens", b.tokenizer.CountTokens(summary),
+	)
+	w.Summary = summary
+	w.SummaryThrough = history[len(history)-1].ID
+	w.Updated = true
+	return w, nil
+}
+
+// FitMemories returns the memories (most important first) that fit in
+// MemoryTokens, stopping before the first one that does not: their order is
+// their priority.
+func (b *Builder) FitMemories(memories []domain.Memory) []domain.Memory {
+	used := 0
+	for i, m := range memories {
+		used += b.tokenizer.CountTokens(m.Text) + b.cfg.MessageOverhead
+		if used > b.cfg.MemoryTokens {
+			return memories[:i]
+		}
+	}
+	return memories
+}
+
+// cut returns the index of the oldest message of the longest suffix of
+// history that fits in budget, keeping at least the last message.
+func (b *Builder) cut(history []*domain.Message, budget int) int {
+	used := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		used += b.tokenizer.CountTokens(history[i].Text) + b.cfg.MessageOverhead
+		if used > budget {
+			return min(i+1, len(history)-1)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,187 @@
+package contextwindow_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/app/contextwindow"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// wordTokenizer counts one token per word, to keep the tests readable.
+type wordTokenizer struct{}
+
+func (wordTokenizer) CountTokens(text string) int { return len(strings.Fields(text)) }
+
+// recordingSummarizer appends the texts it is given to the previous summary.
+type recordingSummarizer struct {
+	calls [][]string
+	err   error
+}
+
+func (s *recordingSummarizer) Summarize(ctx context.Context, previous string, msgs []*domain.Message, maxTokens int) (string, error) {
+	var texts []string
+	for _, m := range msgs {
+		texts = append(texts, m.Text)
+	}
+	s.calls = append(s.calls, texts)
+	if s.err != nil {
+		return "", s.err
+	}
+	return strings.TrimSpace(previous + " " + strings.Join(texts, " ")), nil
+}
+
+// messages returns n messages "m0".."m<n-1>" of 4 words each.
+func messages(n int) []*domain.Message {
+	var out []*domain.Message
+	for i := range n {
+		out = append(out, &domain.Message{
+			ID:   domain.MessageID(fmt.Sprintf("m%d", i)),
+			Text: fmt.Sprintf("m%d one two three", i),
+		})
+	}
+	return out
+}
+
+func ids(msgs []*domain.Message) string {
+	var out []string
+	for _, m := range msgs {
+		out = append(out, string(m.ID))
+	}
+	return strings.Join(out, ",")
+}
+
+func TestBuildFitsHistoryAndRollsTheSummary(t *testing.T) {
+	ctx := context.Background()
+	summarizer := &recordingSummarizer{}
+
+	// 5 tokens per message; 10 of the 30 are kept for the summary
+	b := contextwindow.NewBuilder(wordTokenizer{}, summarizer, contextwindow.Config{
+		TokenBudget:     30,
+		SummaryTokens:   10,
+		MessageOverhead: 1,
+	})
+	session := &domain.Session{ID: "s1"}
+
+	w := b.Build(ctx, session, messages(6))
+	if w.Updated || w.Summary != "" || len(w.History) != 6 || len(summarizer.calls) != 0 {
+		t.Fatalf("expected the whole history without summary, got %+v", w)
+	}
+
+	// Over the budget: the summary takes the oldest messages, down to half
+	// of the 20 left for the history
+	w = b.Build(ctx, session, messages(8))
+	if got := ids(w.History); got != "m6,m7" {
+		t.Fatalf("unexpected window: %s", got)
+	}
+	if !w.Updated || w.SummaryThrough != "m5" || !strings.HasPrefix(w.Summary, "m0 one two three") {
+		t.Fatalf("expected m0..m5 summarized, got %+v", w)
+	}
+	session.Summary, session.SummaryThrough = w.Summary, w.SummaryThrough
+
+	// The next turns fit without summarizing again
+	w = b.Build(ctx, session, messages(10)[6:])
+	if w.Updated || w.Summary != session.Summary || ids(w.History) != "m6,m7,m8,m9" || len(summarizer.calls) != 1 {
+		t.Fatalf("expected the summary reused, got %+v", w)
+	}
+
+	// Only the messages after the summary are folded into it
+	w = b.Build(ctx, session, messages(11)[6:])
+	if got := ids(w.History); got != "m9,m10" {
+		t.Fatalf("unexpected window: %s", got)
+	}
+	if last := summarizer.calls[len(summarizer.calls)-1]; len(last) != 3 || !strings.HasPrefix(last[0], "m6") {
+		t.Fatalf("expected m6..m8 summarized, got %v", last)
+	}
+	if !strings.HasSuffix(w.Summary, "m8 one two three") || w.SummaryThrough != "m8" {
+		t.Fatalf("unexpected summary: %+v", w)
+	}
+}
+
+func TestBuildKeepsThePreviousSummaryWhenSummarizingFails(t *testing.T) {
+	summarizer := &recordingSummarizer{err: errors.New("llm down")}
+	b := contextwindow.NewBuilder(wordTokenizer{}, summarizer, contextwindow.Config{TokenBudget: 12, SummaryTokens: 2, MessageOverhead: 1})
+	session := &domain.Session{ID: "s1", Summary: "earlier", SummaryThrough: "m0"}
+
+	w := b.Build(context.Background(), session, messages(5)[1:])
+	if w.Updated || w.Summary != "earlier" || ids(w.History) != "m3,m4" {
+		t.Fatalf("expected the previous summary and the window, got %+v", w)
+	}
+
+	// A single message over the budget is still kept
+	w = b.Build(context.Background(), &domain.Session{ID: "s2"}, []*domain.Message{{ID: "big", Text: strings.Repeat("word ", 50)}})
+	if ids(w.History) != "big" {
+		t.Fatalf("expected the last message kept, got %+v", w)
+	}
+}
+
+func TestSummarizeCoversTheWholeHistory(t *testing.T) {
+	ctx := context.Background()
+	summarizer := &recordingSummarizer{}
+	b := contextwindow.NewBuilder(wordTokenizer{}, summarizer, contextwindow.Config{})
+	session := &domain.Session{ID: "s1", Summary: "earlier", SummaryThrough: "m1"}
+
+	w, err := b.Summarize(ctx, session, messages(4)[2:])
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	if !w.Updated || w.SummaryThrough != "m3" || w.Summary != "earlier m2 one two three m3 one two three" || len(w.History) != 0 {
+		t.Fatalf("expected m2 and m3 folded into the summary, got %+v", w)
+	}
+
+	// Nothing new: the summarizer is not called
+	session.Summary, session.SummaryThrough = w.Summary, w.SummaryThrough
+	if w, err := b.Summarize(ctx, session, nil); err != nil || w.Updated || len(summarizer.calls) != 1 {
+		t.Fatalf("expected the summary reused, got %+v (%v)", w, err)
+	}
+
+	summarizer.err = errors.New("llm down")
+	if w, err := b.Summarize(ctx, session, messages(5)[4:]); err == nil || w.Updated || w.Summary != session.Summary {
+		t.Fatalf("expected the error and the previous summary, got %+v (%v)", w, err)
+	}
+}
+
+func TestFitMemoriesStopsAtTheBudget(t *testing.T) {
+	b := contextwindow.NewBuilder(wordTokenizer{}, nil, contextwindow.Config{MemoryTokens: 10, MessageOverhead: 1})
+	memories := []domain.Memory{
+		{Kind: domain.MemoryMoodTrend, Text: "ansiedad → tranquilo"},
+		{Kind: domain.MemoryOpenAction, Text: strings.Repeat("caminar ", 20)},
+		{Kind: domain.MemoryOpenAction, Text: "llamar a mamá"},
+	}
+
+	// The oversized memory does not fit, and fitting stops there (order is priority)
+	if got := b.FitMemories(memories); len(got) != 1 || got[0].Kind != domain.MemoryMoodTrend {
+		t.Fatalf("expected only the first memory, got %+v", got)
+	}
+	if got := b.FitMemories(memories[2:]); len(got) != 1 {
+		t.Fatalf("expected a small memory to fit, got %+v", got)
+	}
+	if got := b.FitMemories(nil); len(got) != 0 {
+		t.Fatalf("expected no memories, got %+v", got)
+	}
+}
+
+func TestHeuristicTokenizerAndBudgets(t *testing.T) {
+	tok := contextwindow.HeuristicTokenizer{}
+	if got := tok.CountTokens("Hola, ¿cómo estás?"); got != 7 {
+		t.Fatalf("expected 7 tokens, got %d", got)
+	}
+	if got := tok.CountTokens(""); got != 0 {
+		t.Fatalf("expected 0 tokens, got %d", got)
+	}
+
+	for model, want := range map[string]int{
+		"gemini-2.5-flash-lite": 8000,
+		"gemini-1.5-pro":        4000,
+		"gpt-4o-mini":           6000,
+		"claude-sonnet-4-5":     8000,
+		"llama3":                contextwindow.DefaultTokenBudget,
+	} {
+		if got := contextwindow.BudgetForModel(model); got != want {
+			t.Errorf("BudgetForModel(%q) = %d, want %d", model, got, want)
+		}
+	}
+}
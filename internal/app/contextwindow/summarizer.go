@@ -0,0 +1,59 @@
+package contextwindow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+const summarizerPrompt = `You are Farum's conversation summarizer. You do NOT talk to the user.
+Update the summary of a conversation between a user and Farum (a well-being companion) with the new messages.
+Keep what is needed to continue the conversation: the user's situation, feelings, decisions and pending steps.
+Write in the user's language, in the third person, in under %d words. Return ONLY the summary.
+
+Current summary:
+%s
+
+New messages:
+%s`
+
+// LLMSummarizer asks the LLM for the rolling summary.
+type LLMSummarizer struct {
+	llm domain.LLMClient
+}
+
+// NewLLMSummarizer creates a summarizer backed by an LLMClient.
+func NewLLMSummarizer(llm domain.LLMClient) *LLMSummarizer {
+	return &LLMSummarizer{llm: llm}
+}
+
+func (s *LLMSummarizer) Summarize(
+	ctx context.Context,
+	previous string,
+	msgs []*domain.Message,
+	maxTokens int,
+) (string, error) {
+	if previous == "" {
+		previous = "(none)"
+	}
+
+	var lines []string
+	for _, m := range msgs {
+		lines = append(lines, fmt.Sprintf("%s: %s", m.Author, m.Text))
+	}
+
+	// ~0.75 words per token; the messages are already in the prompt
+	prompt := fmt.Sprintf(summarizerPrompt, maxTokens*3/4, previous, strings.Join(lines, "\n"))
+	summary, err := s.llm.GenerateReply(ctx, prompt, domain.ConversationContext{})
+	if err != nil {
+		return "", fmt.Errorf("summarizer: %w", err)
+	}
+
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return "", fmt.Errorf("summarizer: empty summary")
+	}
+	return summary, nil
+}
@@ -0,0 +1,55 @@
+package contextwindow
+
+import (
+	"strings"
+	"unicode"
+)
+
+// HeuristicTokenizer approximates subword tokenizers without a vocabulary,
+// so it works offline and for any model: about one token per 4 letters of
+// a word, plus one per punctuation mark or symbol.
+type HeuristicTokenizer struct{}
+
+func (HeuristicTokenizer) CountTokens(text string) int {
+	tokens := 0
+	for _, word := range strings.Fields(text) {
+		letters := 0
+		for _, r := range word {
+			if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+				tokens++
+			} else {
+				letters++
+			}
+		}
+		tokens += (letters + 3) / 4
+	}
+	return tokens
+}
+
+// modelBudgets are the history budgets by model name prefix (most specific
+// first): a small share of the context window of the model, so calls stay
+// fast and cheap.
+var modelBudgets = []struct {
+	prefix string
+	tokens int
+}{
+	{"gemini-2.5", 8000},
+	{"gemini", 4000},
+	{"gpt-4.1", 8000},
+	{"gpt-4o", 6000},
+	{"claude", 8000},
+}
+
+// DefaultTokenBudget is the budget of unknown models and of the mock LLM.
+const DefaultTokenBudget = 2000
+
+// BudgetForModel returns the token budget of summary + history for model.
+func BudgetForModel(model string) int {
+	model = strings.ToLower(model)
+	for _, b := range modelBudgets {
+		if strings.HasPrefix(model, b.prefix) {
+			return b.tokens
+		}
+	}
+	return DefaultTokenBudget
+}
@@ -0,0 +1,249 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/PabloGalante/farum-agent/internal/app/agentflow"
+	"github.com/PabloGalante/farum-agent/internal/app/tools"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+var (
+	// ErrSessionNotActive is returned when a closed or archived session gets a new message.
+	ErrSessionNotActive = errors.New("session is not active")
+
+	// ErrSessionTransition is returned when the session cannot move to the requested status.
+	ErrSessionTransition = errors.New("invalid session transition")
+)
+
+// defaultSessionPage is the page size of ListSessions when none is given.
+const defaultSessionPage = 20
+
+// closingHistory is how many recent messages the final journal entry sees,
+// besides the summary of the session.
+const closingHistory = 10
+
+// ListSessionsInput selects a page of the sessions of a user, newest first.
+type ListSessionsInput struct {
+	UserID domain.UserID
+
+	// Statuses to list; empty means active and closed (archived sessions are
+	// left out).
+	Statuses []domain.SessionStatus
+
+	Limit  int // default 20
+	Offset int
+}
+
+// ListSessionsOutput is a page of sessions.
+type ListSessionsOutput struct {
+	Sessions []*domain.Session
+
+	// NextOffset is the Offset of the next page, 0 on the last one.
+	NextOffset int
+}
+
+// ListSessions returns a page of the sessions of the user.
+func (s *Service) ListSessions(ctx context.Context, in ListSessionsInput) (*ListSessionsOutput, error) {
+	if err := domain.CheckOwner(ctx, in.UserID); err != nil {
+		return nil, err
+	}
+	log := observability.LoggerFromContext(ctx).With("user_id", in.UserID)
+
+	statuses := in.Statuses
+	if len(statuses) == 0 {
+		statuses = []domain.SessionStatus{domain.SessionActive, domain.SessionClosed}
+	}
+	limit := in.Limit
+	if limit <= 0 {
+		limit = defaultSessionPage
+	}
+	offset := max(in.Offset, 0)
+
+	// One more than the page tells whether there is a next one
+	sessions, err := s.sessionStore.ListSessionsByUser(in.UserID, domain.SessionQuery{
+		Statuses: statuses,
+		Limit:    limit + 1,
+		Offset:   offset,
+	})
+	if err != nil {
+		log.Error("failed to list sessions", "error", err)
+		return nil, err
+	}
+
+	out := &ListSessionsOutput{Sessions: []*domain.Session{}}
+	if len(sessions) > limit {
+		sessions = sessions[:limit]
+		out.NextOffset = offset + limit
+	}
+	out.Sessions = append(out.Sessions, sessions...)
+
+	log.Info("listed sessions", "count", len(out.Sessions), "offset", offset)
+	return out, nil
+}
+
+// GetSession returns the session with id (domain.ErrSessionNotFound if missing).
+func (s *Service) GetSession(ctx context.Context, id domain.SessionID) (*domain.Session, error) {
+	return s.ownedSession(ctx, id)
+}
+
+// SessionStatusOutput is a session after a change of status.
+type SessionStatusOutput struct {
+	Session *domain.Session
+
+	// JournalEntryID is the final journal entry written when the change closed
+	// the session; empty when the journal is disabled, the user never wrote
+	// or journaling failed.
+	JournalEntryID domain.JournalEntryID
+}
+
+// CloseSession finishes an active session: its summary is brought up to date
+// with the whole conversation and a final journal entry is written, so the
+// next sessions can recall it. Both are best-effort; the session is closed
+// even if they fail.
+func (s *Service) CloseSession(ctx context.Context, id domain.SessionID) (*SessionStatusOutput, error) {
+	session, err := s.ownedSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !session.IsActive() {
+		return nil, fmt.Errorf("%w: session is already %s", ErrSessionTransition, session.CurrentStatus())
+	}
+	return s.changeStatus(ctx, session, domain.SessionClosed)
+}
+
+// ArchiveSession leaves a session out of the session list. An active session
+// is closed first.
+func (s *Service) ArchiveSession(ctx context.Context, id domain.SessionID) (*SessionStatusOutput, error) {
+	session, err := s.ownedSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.CurrentStatus() == domain.SessionArchived {
+		return nil, fmt.Errorf("%w: session is already archived", ErrSessionTransition)
+	}
+	return s.changeStatus(ctx, session, domain.SessionArchived)
+}
+
+// ResumeSession makes a closed or archived session active again. Its summary
+// is kept, so the conversation goes on where it was left.
+func (s *Service) ResumeSession(ctx context.Context, id domain.SessionID) (*SessionStatusOutput, error) {
+	session, err := s.ownedSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.IsActive() {
+		return nil, fmt.Errorf("%w: session is already active", ErrSessionTransition)
+	}
+	return s.changeStatus(ctx, session, domain.SessionActive)
+}
+
+// changeStatus moves session to status, closing it first when it leaves the
+// active status.
+func (s *Service) changeStatus(ctx context.Context, session *domain.Session, status domain.SessionStatus) (*SessionStatusOutput, error) {
+	log := observability.LoggerFromContext(ctx).With(
+		"session_id", session.ID,
+		"user_id", session.UserID,
+		"from", session.CurrentStatus(),
+		"to", status,
+	)
+
+	out := &SessionStatusOutput{Session: session}
+	now := s.now()
+
+	switch {
+	case status == domain.SessionActive:
+		session.ClosedAt = domain.Timestamp{}
+	case session.IsActive():
+		out.JournalEntryID = s.closeConversation(ctx, session)
+		session.ClosedAt = now
+	}
+
+	session.Status = status
+	session.UpdatedAt = now
+	if err := s.sessionStore.UpdateSession(session); err != nil {
+		log.Error("failed to update session", "error", err)
+		return nil, err
+	}
+
+	log.Info("session status changed", "journal_entry_id", out.JournalEntryID)
+	return out, nil
+}
+
+// closeConversation summarizes the whole session into session.Summary and
+// writes the final journal entry, returning its ID.
+func (s *Service) closeConversation(ctx context.Context, session *domain.Session) domain.JournalEntryID {
+	log := observability.LoggerFromContext(ctx).With("session_id", session.ID)
+
+	msgs, err := s.messageStore.GetMessagesBySession(session.ID, 0)
+	if err != nil {
+		log.Warn("failed to load the session to close it", "error", err)
+		return ""
+	}
+	history := domain.WithoutTrace(msgs)
+	if !slices.ContainsFunc(history, func(m *domain.Message) bool { return m.Author == domain.RoleUser }) {
+		// Only the welcome message: nothing to summarize or journal
+		return ""
+	}
+
+	// Only the messages the summary does not cover yet are folded into it
+	unsummarized := history
+	if i := slices.IndexFunc(history, func(m *domain.Message) bool { return m.ID == session.SummaryThrough }); i >= 0 {
+		unsummarized = history[i+1:]
+	}
+	window, err := s.contextBuilder.Summarize(ctx, session, unsummarized)
+	if err != nil {
+		log.Warn("failed to summarize the closed session", "error", err)
+	}
+	if window.Updated {
+		session.Summary = window.Summary
+		session.SummaryThrough = window.SummaryThrough
+	}
+
+	if s.journalTool == nil {
+		return ""
+	}
+
+	// The last plan of the session (kept in the trace when it was composed
+	// into the reply), and the summary as the closing reflection
+	var plan string
+	for _, m := range slices.Backward(msgs) {
+		if m.ContentType == agentflow.ContentTypeTaskList {
+			plan = m.Text
+			break
+		}
+	}
+	last := history[len(history)-1]
+	reflection := session.Summary
+	if reflection == "" {
+		reflection = last.Text
+	}
+
+	convCtx := domain.ConversationContext{
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		Mode:      last.Mode,
+		History:   history[max(len(history)-closingHistory, 0):],
+		Summary:   session.Summary,
+	}
+	input := agentflow.NewJournalExtractor(s.llm).BuildToolInput(ctx, plan, reflection, convCtx)
+
+	// Through the registry, so the entry is validated and the call audited
+	// like the agents' ones
+	res, err := s.registry.Call(ctx, tools.ToolContext{
+		UserID:    string(session.UserID),
+		SessionID: string(session.ID),
+		RequestID: observability.RequestIDFromContext(ctx),
+	}, s.journalTool.Name(), input)
+	if err != nil {
+		log.Warn("failed to write the final journal entry", "error", err)
+		return ""
+	}
+
+	id, _ := res["entry_id"].(string)
+	return domain.JournalEntryID(id)
+}
@@ -0,0 +1,85 @@
+package conversation
+
+import (
+	"github.com/PabloGalante/farum-agent/internal/app/agentflow"
+	"github.com/PabloGalante/farum-agent/internal/app/contextwindow"
+	"github.com/PabloGalante/farum-agent/internal/app/memories"
+	"github.com/PabloGalante/farum-agent/internal/app/routing"
+	"github.com/PabloGalante/farum-agent/internal/app/safety"
+	"github.com/PabloGalante/farum-agent/internal/app/tools"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// Option configures optional collaborators of the Service.
+type Option func(*Service)
+
+// WithSafetyGate replaces the default (rules only) safety gate.
+func WithSafetyGate(gate *safety.Gate) Option {
+	return func(s *Service) {
+		if gate != nil {
+			s.safetyGate = gate
+		}
+	}
+}
+
+// WithSafetyEventStore records risky messages for later review.
+func WithSafetyEventStore(store domain.SafetyEventStore) Option {
+	return func(s *Service) {
+		s.safetyEvents = store
+	}
+}
+
+// WithTools offers more tools to the agents (besides the journal tool)
+// when the LLM supports tool calling.
+func WithTools(extra ...tools.Tool) Option {
+	return func(s *Service) {
+		for _, t := range extra {
+			if t != nil {
+				s.extraTools = append(s.extraTools, t)
+			}
+		}
+	}
+}
+
+// WithKnowledgeBase grounds the Planner on a knowledge base; the chunks it
+// drew from are tagged on the agent message ("cite:<chunk id>").
+func WithKnowledgeBase(knowledge domain.KnowledgeRetriever) Option {
+	return func(s *Service) {
+		s.knowledge = knowledge
+	}
+}
+
+// WithMemories adds what the user shared in previous sessions (journal
+// summaries, open actions, mood trend) to the conversation context.
+func WithMemories(svc *memories.Service) Option {
+	return func(s *Service) {
+		s.memories = svc
+	}
+}
+
+// WithModeRouter replaces the default (heuristics only) mode router.
+func WithModeRouter(router *routing.ModeRouter) Option {
+	return func(s *Service) {
+		if router != nil {
+			s.modeRouter = router
+		}
+	}
+}
+
+// WithPipelines replaces the default agent pipelines of each mode.
+// cfg is expected to be valid (see agentflow.LoadPipelineConfig).
+func WithPipelines(cfg agentflow.PipelineConfig) Option {
+	return func(s *Service) {
+		s.pipelines = &cfg
+	}
+}
+
+// WithContextBuilder replaces the default context window (DefaultTokenBudget
+// tokens, summaries by the LLM of the service).
+func WithContextBuilder(builder *contextwindow.Builder) Option {
+	return func(s *Service) {
+		if builder != nil {
+			s.contextBuilder = builder
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package conversation
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/PabloGalante/farum-agent/internal/app/agentflow"
+	"github.com/PabloGalante/farum-agent/internal/app/safety"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// safetyAgentName is the name used in events and tags for the safety gate.
+const safetyAgentName = "safety"
+
+// crisisReply builds the agent message sent instead of the agents' reply
+// when the safety gate short-circuits.
+func (s *Service) crisisReply(
+	session *domain.Session,
+	userMsg *domain.Message,
+	assessment domain.SafetyAssessment,
+	sink agentflow.EventSink,
+) *domain.Message {
+	text := s.safetyGate.CrisisResponse(assessment.Language)
+
+	if sink != nil {
+		sink(agentflow.Event{Type: agentflow.EventAgentStart, Agent: safetyAgentName})
+		sink(agentflow.Event{Type: agentflow.EventToken, Agent: safetyAgentName, Text: text})
+		sink(agentflow.Event{Type: agentflow.EventAgentEnd, Agent: safetyAgentName})
+	}
+
+	replyTo := userMsg.ID
+	return &domain.Message{
+		ID:          domain.MessageID(generateID()),
+		SessionID:   session.ID,
+		Author:      domain.RoleAgent,
+		Text:        text,
+		CreatedAt:   s.now(),
+		Mode:        userMsg.Mode,
+		Tags:        append(safety.Tags(assessment), "safety:crisis_response"),
+		ReplyTo:     &replyTo,
+		ContentType: agentflow.ContentTypeCrisisResources,
+	}
+}
+
+// recordSafetyEvent stores the assessment for review. It never fails the request.
+func (s *Service) recordSafetyEvent(
+	log *slog.Logger,
+	session *domain.Session,
+	userMsg *domain.Message,
+	assessment domain.SafetyAssessment,
+	shortCircuited bool,
+) {
+	log.Warn("safety risk detected",
+		"level", assessment.Level,
+		"categories", assessment.Categories,
+		"source", assessment.Source,
+		"short_circuited", shortCircuited,
+	)
+
+	if s.safetyEvents == nil {
+		return
+	}
+
+	ev := &domain.SafetyEvent{
+		SessionID:      session.ID,
+		UserID:         session.UserID,
+		MessageID:      userMsg.ID,
+		CreatedAt:      s.now(),
+		Level:          assessment.Level,
+		Categories:     assessment.Categories,
+		Matches:        assessment.Matches,
+		Language:       assessment.Language,
+		Source:         assessment.Source,
+		ShortCircuited: shortCircuited,
+	}
+
+	if err := s.safetyEvents.AppendSafetyEvent(ev); err != nil {
+		log.Error("failed to record safety event", "error", err)
+	}
+}
+
+// checkSafety runs the gate over the incoming message.
+func (s *Service) checkSafety(
+	ctx context.Context,
+	session *domain.Session,
+	mode domain.InteractionMode,
+	text string,
+) domain.SafetyAssessment {
+	return s.safetyGate.Check(ctx, text, domain.ConversationContext{
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		Mode:      mode,
+	})
+}
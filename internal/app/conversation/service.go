@@ -2,9 +2,16 @@ package conversation
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/PabloGalante/farum-agent/internal/app/agentflow"
+	"github.com/PabloGalante/farum-agent/internal/app/contextwindow"
+	"github.com/PabloGalante/farum-agent/internal/app/memories"
+	"github.com/PabloGalante/farum-agent/internal/app/routing"
+	"github.com/PabloGalante/farum-agent/internal/app/safety"
 	"github.com/PabloGalante/farum-agent/internal/app/tools"
 	"github.com/PabloGalante/farum-agent/internal/domain"
 	"github.com/PabloGalante/farum-agent/internal/observability"
@@ -17,7 +24,18 @@ type Service struct {
 	now          func() time.Time
 
 	journalTool  *tools.JournalTool
+	extraTools   []tools.Tool
+	registry     *tools.ToolRegistry
+	knowledge    domain.KnowledgeRetriever
+	pipelines    *agentflow.PipelineConfig
+	memories     *memories.Service
 	orchestrator *agentflow.Orchestrator
+
+	safetyGate   *safety.Gate
+	safetyEvents domain.SafetyEventStore
+
+	modeRouter     *routing.ModeRouter
+	contextBuilder *contextwindow.Builder
 }
 
 func NewService(
@@ -25,24 +43,48 @@ func NewService(
 	sessionStore domain.SessionStore,
 	messageStore domain.MessageStore,
 	journalTool *tools.JournalTool,
+	opts ...Option,
 ) *Service {
-	var toolForOrchestrator tools.Tool
-	if journalTool != nil {
-		toolForOrchestrator = journalTool
-	}
-
-	return &Service{
+	s := &Service{
 		llm:          llm,
 		sessionStore: sessionStore,
 		messageStore: messageStore,
 		now:          time.Now,
 		journalTool:  journalTool,
-		orchestrator: agentflow.NewDefaultOrchestrator(llm, toolForOrchestrator),
+		safetyGate:   safety.NewDefaultGate(),
+		modeRouter:   routing.NewDefaultModeRouter(),
+	}
+	s.contextBuilder = contextwindow.NewBuilder(nil, contextwindow.NewLLMSummarizer(llm), contextwindow.Config{})
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	registry, _ := tools.NewToolRegistry()
+	if journalTool != nil {
+		_ = registry.Register(journalTool)
 	}
+	for _, t := range s.extraTools {
+		if err := registry.Register(t); err != nil {
+			observability.Logger().Warn("tool not offered to the agents", "error", err)
+		}
+	}
+	var orchOpts []agentflow.OrchestratorOption
+	if s.knowledge != nil {
+		orchOpts = append(orchOpts, agentflow.WithKnowledge(s.knowledge))
+	}
+	if s.pipelines != nil {
+		orchOpts = append(orchOpts, agentflow.WithPipelines(*s.pipelines))
+	}
+	s.registry = registry
+	s.orchestrator = agentflow.NewDefaultOrchestrator(llm, registry, orchOpts...)
+
+	return s
 }
 
 type StartSessionInput struct {
-	UserID        domain.UserID
+	UserID domain.UserID
+	// PreferredMode biases the mode routing; empty means no preference.
 	PreferredMode domain.InteractionMode
 	Title         string
 }
@@ -52,6 +94,10 @@ type StartSessionOutput struct {
 }
 
 func (s *Service) StartSession(ctx context.Context, in StartSessionInput) (*StartSessionOutput, error) {
+	if err := domain.CheckOwner(ctx, in.UserID); err != nil {
+		return nil, err
+	}
+
 	now := s.now()
 
 	log := observability.LoggerFromContext(ctx).With(
@@ -60,7 +106,6 @@ func (s *Service) StartSession(ctx context.Context, in StartSessionInput) (*Star
 	)
 	log.Info("starting new session")
 
-
 	session := &domain.Session{
 		ID:            domain.SessionID(generateID()),
 		UserID:        in.UserID,
@@ -68,6 +113,7 @@ func (s *Service) StartSession(ctx context.Context, in StartSessionInput) (*Star
 		UpdatedAt:     now,
 		PreferredMode: in.PreferredMode,
 		Title:         in.Title,
+		Status:        domain.SessionActive,
 	}
 
 	if err := s.sessionStore.CreateSession(session); err != nil {
@@ -99,28 +145,75 @@ func (s *Service) StartSession(ctx context.Context, in StartSessionInput) (*Star
 
 type SendMessageInput struct {
 	SessionID domain.SessionID
-	UserID    domain.UserID
+	UserID    domain.UserID // must own the session; empty skips the check
 	Text      string
 }
 
 type SendMessageOutput struct {
 	UserMessage  *domain.Message
 	AgentMessage *domain.Message
+	Trace        []*domain.Message // outputs of the other agents of the turn
+	Routing      domain.ModeDecision
 }
 
 func (s *Service) SendMessage(ctx context.Context, in SendMessageInput) (*SendMessageOutput, error) {
-	session, err := s.sessionStore.GetSession(in.SessionID)
+	return s.SendMessageStream(ctx, in, nil)
+}
+
+// SendMessageStream is like SendMessage, but publishes the orchestrator
+// progress (agent start/end and chunks of the final reply) to sink.
+func (s *Service) SendMessageStream(
+	ctx context.Context,
+	in SendMessageInput,
+	sink agentflow.EventSink,
+) (*SendMessageOutput, error) {
+	session, err := s.ownedSession(ctx, in.SessionID)
 	if err != nil {
 		return nil, err
 	}
+	if in.UserID != "" && in.UserID != session.UserID {
+		return nil, fmt.Errorf("%w: session %s does not belong to %s", domain.ErrForbidden, session.ID, in.UserID)
+	}
 
 	log := observability.LoggerFromContext(ctx).With(
 		"session_id", session.ID,
 		"user_id", session.UserID,
-		"mode", session.PreferredMode,
 	)
+	if !session.IsActive() {
+		log.Warn("message to an inactive session", "status", session.CurrentStatus())
+		return nil, fmt.Errorf("%w: session is %s", ErrSessionNotActive, session.CurrentStatus())
+	}
+
 	log.Info("sending message", "text", in.Text)
 
+	// The summary stands for the older messages: only the newer ones are
+	// loaded
+	msgs, err := s.messageStore.GetMessagesAfter(session.ID, session.SummaryThrough)
+	if err != nil {
+		log.Error("failed to load history", "error", err)
+		return nil, err
+	}
+	history := domain.WithoutTrace(msgs)
+
+	// Safety gate runs before anything else sees the message, the mode
+	// classifiers included
+	recent := history[max(len(history)-routingHistory, 0):]
+	current := currentMode(session, recent)
+	assessment := s.checkSafety(ctx, session, current, in.Text)
+	crisis := s.safetyGate.ShouldShortCircuit(assessment)
+
+	// The mode is decided per message, from the recent turns; a crisis
+	// reply does not need one
+	routed := domain.ModeDecision{Mode: current, Previous: current, Reason: "kept (crisis)"}
+	if !crisis {
+		routed = s.modeRouter.Route(ctx, in.Text, domain.ConversationContext{
+			SessionID: session.ID,
+			UserID:    session.UserID,
+			History:   recent,
+		}, session.PreferredMode)
+	}
+	log = log.With("mode", routed.Mode)
+
 	now := s.now()
 
 	userMsg := &domain.Message{
@@ -129,7 +222,8 @@ func (s *Service) SendMessage(ctx context.Context, in SendMessageInput) (*SendMe
 		Author:    domain.RoleUser,
 		Text:      in.Text,
 		CreatedAt: now,
-		Mode:      session.PreferredMode,
+		Mode:      routed.Mode,
+		Tags:      safety.Tags(assessment),
 	}
 
 	if err := s.messageStore.AppendMessage(userMsg); err != nil {
@@ -137,41 +231,130 @@ func (s *Service) SendMessage(ctx context.Context, in SendMessageInput) (*SendMe
 		return nil, err
 	}
 
-	history, err := s.messageStore.GetMessagesBySession(session.ID, 20)
-	if err != nil {
-		log.Error("failed to load history", "error", err)
-		return nil, err
+	if crisis {
+		s.recordSafetyEvent(log, session, userMsg, assessment, true)
+		return s.finishTurn(log, session, routed, userMsg, s.crisisReply(session, userMsg, assessment, sink))
+	}
+	if assessment.Level.Severity() >= domain.RiskMedium.Severity() {
+		s.recordSafetyEvent(log, session, userMsg, assessment, false)
+	}
+
+	// As much history as fits in the budget, older turns summarized
+	window := s.contextBuilder.Build(ctx, session, append(history, userMsg))
+	if window.Updated {
+		session.Summary = window.Summary
+		session.SummaryThrough = window.SummaryThrough
 	}
 
 	convCtx := domain.ConversationContext{
 		SessionID: session.ID,
 		UserID:    session.UserID,
-		Mode:      session.PreferredMode,
-		History:   history,
+		Mode:      routed.Mode,
+		History:   window.History,
+		Summary:   window.Summary,
+	}
+
+	// Long-term memory is best-effort: without it Farum still answers
+	if s.memories != nil {
+		recalled, err := s.memories.Recall(ctx, session.UserID, session.ID, in.Text)
+		if err != nil {
+			log.Warn("failed to recall memories", "error", err)
+		}
+		convCtx.Memories = s.contextBuilder.FitMemories(recalled)
 	}
 
-	replyText, err := s.orchestrator.Run(ctx, in.Text, convCtx)
+	turn, err := s.orchestrator.RunTurn(ctx, in.Text, convCtx, sink)
 	if err != nil {
 		log.Error("orchestrator failed", "error", err)
 		return nil, err
 	}
 
-	agentMsg := &domain.Message{
-		ID:        domain.MessageID(generateID()),
-		SessionID: session.ID,
-		Author:    domain.RoleAgent,
-		Text:      replyText,
-		CreatedAt: s.now(),
-		Mode:      session.PreferredMode,
+	// Every other agent output is kept as the trace of the turn
+	var trace []*domain.Message
+	for _, res := range turn.Outputs {
+		if res.Agent == turn.Replier {
+			continue
+		}
+		msg := s.agentMessage(userMsg, res.Agent, res.Output.ContentType, res.Output.Reply, res.Output.Citations)
+		msg.Tags = append(msg.Tags, domain.TagTrace)
+		if err := s.messageStore.AppendMessage(msg); err != nil {
+			log.Error("failed to append agent output", "agent", res.Agent, "error", err)
+			return nil, err
+		}
+		trace = append(trace, msg)
+	}
+
+	// The replier is the last agent that ran
+	contentType := agentflow.ContentTypeText
+	if n := len(turn.Outputs); n > 0 {
+		contentType = turn.Outputs[n-1].Output.ContentType
+	}
+	agentMsg := s.agentMessage(userMsg, turn.Replier, contentType, turn.Reply, turn.Citations)
+
+	out, err := s.finishTurn(log, session, routed, userMsg, agentMsg)
+	if err != nil {
+		return nil, err
 	}
+	out.Trace = trace
+	return out, nil
+}
+
+// currentMode is the mode of the last message of history that has one, or
+// else the preferred mode of session.
+func currentMode(session *domain.Session, history []*domain.Message) domain.InteractionMode {
+	for _, m := range slices.Backward(history) {
+		if m.Mode.Valid() {
+			return m.Mode
+		}
+	}
+	if session.PreferredMode.Valid() {
+		return session.PreferredMode
+	}
+	return domain.ModeCheckIn
+}
 
+// agentMessage builds the message of an agent output replying to userMsg.
+func (s *Service) agentMessage(
+	userMsg *domain.Message,
+	agent string,
+	contentType string,
+	text string,
+	citations []domain.Citation,
+) *domain.Message {
+	replyTo := userMsg.ID
+	msg := &domain.Message{
+		ID:          domain.MessageID(generateID()),
+		SessionID:   userMsg.SessionID,
+		Author:      domain.RoleAgent,
+		Text:        text,
+		CreatedAt:   s.now(),
+		Mode:        userMsg.Mode,
+		Tags:        []string{domain.AgentTag(agent)},
+		ReplyTo:     &replyTo,
+		ContentType: contentType,
+	}
+	for _, c := range citations {
+		msg.Tags = append(msg.Tags, domain.CitationTag(c))
+	}
+	return msg
+}
+
+// finishTurn stores the agent reply and touches the session.
+func (s *Service) finishTurn(
+	log *slog.Logger,
+	session *domain.Session,
+	routed domain.ModeDecision,
+	userMsg *domain.Message,
+	agentMsg *domain.Message,
+) (*SendMessageOutput, error) {
 	if err := s.messageStore.AppendMessage(agentMsg); err != nil {
 		log.Error("failed to append agent message", "error", err)
 		return nil, err
 	}
 
+	// Only the turn's fields: the session may have been closed meanwhile
 	session.UpdatedAt = s.now()
-	if err := s.sessionStore.UpdateSession(session); err != nil {
+	if err := s.sessionStore.TouchSession(session); err != nil {
 		log.Error("failed to update session", "error", err)
 		return nil, err
 	}
@@ -181,27 +364,53 @@ func (s *Service) SendMessage(ctx context.Context, in SendMessageInput) (*SendMe
 	return &SendMessageOutput{
 		UserMessage:  userMsg,
 		AgentMessage: agentMsg,
+		Routing:      routed,
 	}, nil
 }
 
+// TimelineView selects the messages of a session timeline.
+type TimelineView string
+
+const (
+	// ViewReply is what the user saw: their messages and the replies.
+	ViewReply TimelineView = "reply"
+	// ViewTrace adds the output of every agent, linked to the user message
+	// it answers by ReplyTo.
+	ViewTrace TimelineView = "trace"
+)
+
+// Valid reports whether v is a known view.
+func (v TimelineView) Valid() bool {
+	return v == ViewReply || v == ViewTrace
+}
+
+// GetSessionTimeline returns the session and its last limit messages (all
+// of them if limit <= 0) in view. An empty view means ViewReply.
 func (s *Service) GetSessionTimeline(
 	ctx context.Context,
 	sessionID domain.SessionID,
 	limit int,
+	view TimelineView,
 ) (*domain.Session, []*domain.Message, error) {
 
 	log := observability.LoggerFromContext(ctx).With(
 		"session_id", sessionID,
 		"limit", limit,
+		"view", view,
 	)
 
-	session, err := s.sessionStore.GetSession(sessionID)
+	session, err := s.ownedSession(ctx, sessionID)
 	if err != nil {
 		log.Error("failed to get session", "error", err)
 		return nil, nil, err
 	}
 
-	msgs, err := s.messageStore.GetMessagesBySession(sessionID, limit)
+	var msgs []*domain.Message
+	if view == ViewTrace {
+		msgs, err = s.messageStore.GetMessagesBySession(sessionID, limit)
+	} else {
+		msgs, err = s.recentMessages(sessionID, limit)
+	}
 	if err != nil {
 		log.Error("failed to get messages", "error", err)
 		return nil, nil, err
@@ -212,6 +421,36 @@ func (s *Service) GetSessionTimeline(
 	return session, msgs, nil
 }
 
+// ownedSession loads a session, checking that the authenticated user of ctx
+// (if any) owns it.
+func (s *Service) ownedSession(ctx context.Context, id domain.SessionID) (*domain.Session, error) {
+	session, err := s.sessionStore.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.CheckOwner(ctx, session.UserID); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// routingHistory is how many recent messages the mode router sees.
+const routingHistory = 6
+
+// recentMessages returns the last limit messages the user saw (all of them
+// if limit <= 0), leaving the trace of the agents out.
+func (s *Service) recentMessages(sessionID domain.SessionID, limit int) ([]*domain.Message, error) {
+	msgs, err := s.messageStore.GetMessagesBySession(sessionID, 0)
+	if err != nil {
+		return nil, err
+	}
+	msgs = domain.WithoutTrace(msgs)
+	if limit > 0 && len(msgs) > limit {
+		msgs = msgs[len(msgs)-limit:]
+	}
+	return msgs, nil
+}
+
 // TODO: replace with something like UUID
 func generateID() string {
 	return time.Now().Format("20060102150405.000000000")
@@ -2,12 +2,22 @@ package conversation_test
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/PabloGalante/farum-agent/internal/adapters/embedding"
 	"github.com/PabloGalante/farum-agent/internal/adapters/llm"
 	"github.com/PabloGalante/farum-agent/internal/adapters/storage/memory"
+	"github.com/PabloGalante/farum-agent/internal/app/agentflow"
+	"github.com/PabloGalante/farum-agent/internal/app/contextwindow"
 	"github.com/PabloGalante/farum-agent/internal/app/conversation"
+	"github.com/PabloGalante/farum-agent/internal/app/knowledge"
+	"github.com/PabloGalante/farum-agent/internal/app/routing"
+	"github.com/PabloGalante/farum-agent/internal/app/tools"
 	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
 )
 
 func TestStartSessionAndSendMessage(t *testing.T) {
@@ -45,3 +55,479 @@ func TestStartSessionAndSendMessage(t *testing.T) {
 		t.Fatalf("expected non-empty agent reply")
 	}
 }
+
+func TestSendMessageSafetyShortCircuit(t *testing.T) {
+	ctx := context.Background()
+
+	events := memory.NewSafetyEventStore()
+	svc := conversation.NewService(
+		llm.NewMockLLM(),
+		memory.NewSessionStore(),
+		memory.NewMessageStore(),
+		nil,
+		conversation.WithSafetyEventStore(events),
+	)
+
+	out, err := svc.StartSession(ctx, conversation.StartSessionInput{
+		UserID:        domain.UserID("test-user"),
+		PreferredMode: domain.ModeCheckIn,
+	})
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+
+	reply, err := svc.SendMessage(ctx, conversation.SendMessageInput{
+		SessionID: out.Session.ID,
+		UserID:    out.Session.UserID,
+		Text:      "No quiero seguir viviendo, quiero matarme",
+	})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	if reply.AgentMessage.ContentType != agentflow.ContentTypeCrisisResources {
+		t.Fatalf("expected crisis_resources reply, got %q: %s", reply.AgentMessage.ContentType, reply.AgentMessage.Text)
+	}
+	if !strings.Contains(reply.AgentMessage.Text, "emergencia") {
+		t.Fatalf("expected Spanish crisis response, got %q", reply.AgentMessage.Text)
+	}
+	if len(reply.UserMessage.Tags) == 0 || reply.UserMessage.Tags[0] != "safety:high" {
+		t.Fatalf("expected user message tagged safety:high, got %v", reply.UserMessage.Tags)
+	}
+
+	recorded, _ := events.ListSafetyEvents(0)
+	if len(recorded) != 1 || !recorded[0].ShortCircuited || recorded[0].MessageID != reply.UserMessage.ID {
+		t.Fatalf("expected 1 short-circuited safety event for the user message, got %+v", recorded)
+	}
+}
+
+// spyClassifier counts the messages it is asked to classify.
+type spyClassifier struct{ calls int }
+
+func (c *spyClassifier) Classify(ctx context.Context, text string, convCtx domain.ConversationContext) (domain.ModeScores, error) {
+	c.calls++
+	return domain.ModeScores{domain.ModeActionPlan: 1}, nil
+}
+
+func TestSendMessageChecksSafetyBeforeRouting(t *testing.T) {
+	ctx := context.Background()
+	spy := &spyClassifier{}
+	svc := conversation.NewService(llm.NewMockLLM(), memory.NewSessionStore(), memory.NewMessageStore(), nil,
+		conversation.WithModeRouter(routing.NewModeRouter(routing.RouterConfig{}, spy)),
+	)
+
+	started, err := svc.StartSession(ctx, conversation.StartSessionInput{UserID: "u1", PreferredMode: domain.ModeDeepDive})
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	send := func(text string) *conversation.SendMessageOutput {
+		t.Helper()
+		out, err := svc.SendMessage(ctx, conversation.SendMessageInput{SessionID: started.Session.ID, UserID: "u1", Text: text})
+		if err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+		return out
+	}
+
+	// A crisis never reaches the classifiers, and keeps the mode of the session
+	if out := send("No quiero seguir viviendo, quiero matarme"); spy.calls != 0 || out.Routing.Mode != domain.ModeDeepDive {
+		t.Fatalf("expected the crisis unrouted in deep_dive, got %d classifier calls, %+v", spy.calls, out.Routing)
+	}
+	if out := send("Necesito organizarme con el trabajo"); spy.calls != 1 || out.Routing.Mode != domain.ModeActionPlan {
+		t.Fatalf("expected the next message routed, got %d classifier calls, %+v", spy.calls, out.Routing)
+	}
+}
+
+// agentScriptLLM answers like a real model would, picking the reply by agent role.
+type agentScriptLLM struct{}
+
+func (agentScriptLLM) GenerateReply(ctx context.Context, prompt string, convCtx domain.ConversationContext) (string, error) {
+	switch {
+	case strings.HasPrefix(prompt, "You are Farum's Listener"):
+		return "Te escucho: el trabajo te tiene ansioso y no estás durmiendo bien.", nil
+	case strings.HasPrefix(prompt, "You are Farum's Planner"):
+		return "1. Anotá tres tareas prioritarias para mañana.\n2. Cortá pantallas media hora antes de dormir.", nil
+	case strings.HasPrefix(prompt, "You are Farum's Reflector"):
+		return "Tiene sentido que te sientas así. Probá con las tres tareas y contame cómo dormiste.", nil
+	default:
+		return `{"summary":"Ansiedad por el trabajo","mood":"ansioso","actions":["Anotar tres tareas prioritarias"]}`, nil
+	}
+}
+
+func TestSendMessageReplaysCassette(t *testing.T) {
+	ctx := context.Background()
+	cassette := filepath.Join(t.TempDir(), "check_in.json")
+
+	turn := func(client domain.LLMClient) string {
+		t.Helper()
+
+		journalStore := memory.NewJournalStore()
+		svc := conversation.NewService(client, memory.NewSessionStore(), memory.NewMessageStore(), tools.NewJournalTool(journalStore))
+
+		out, err := svc.StartSession(ctx, conversation.StartSessionInput{
+			UserID:        domain.UserID("cassette-user"),
+			PreferredMode: domain.ModeActionPlan,
+		})
+		if err != nil {
+			t.Fatalf("StartSession failed: %v", err)
+		}
+
+		reply, err := svc.SendMessage(ctx, conversation.SendMessageInput{
+			SessionID: out.Session.ID,
+			UserID:    out.Session.UserID,
+			Text:      "Estoy muy ansioso por el trabajo y no duermo",
+		})
+		if err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+		return reply.AgentMessage.Text
+	}
+
+	recorder, err := llm.NewRecordingLLM(agentScriptLLM{}, cassette)
+	if err != nil {
+		t.Fatalf("NewRecordingLLM: %v", err)
+	}
+	recorded := turn(recorder)
+
+	replayer, err := llm.NewReplayLLM(cassette)
+	if err != nil {
+		t.Fatalf("NewReplayLLM: %v", err)
+	}
+	replayed := turn(replayer)
+
+	if replayed != recorded {
+		t.Fatalf("replayed reply differs:\n got: %q\nwant: %q", replayed, recorded)
+	}
+	if !strings.Contains(replayed, "tres tareas") {
+		t.Fatalf("expected the recorded Reflector reply, got %q", replayed)
+	}
+}
+
+func TestSendMessageCitesKnowledgeBase(t *testing.T) {
+	ctx := context.Background()
+
+	kb := knowledge.NewBase(embedding.NewHashingEmbedder(0), memory.NewVectorIndex())
+	_, err := kb.Ingest(ctx, "mindfulness/respiracion.md",
+		"# Respiración cuadrada\n\nUn ejercicio de respiración para bajar la ansiedad: inhalá 4, sostené 4, exhalá 4.\n")
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+
+	svc := conversation.NewService(llm.NewMockLLM(), memory.NewSessionStore(), memory.NewMessageStore(), nil,
+		conversation.WithKnowledgeBase(kb))
+
+	started, err := svc.StartSession(ctx, conversation.StartSessionInput{UserID: "u1", PreferredMode: domain.ModeActionPlan})
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	out, err := svc.SendMessage(ctx, conversation.SendMessageInput{
+		SessionID: started.Session.ID,
+		UserID:    "u1",
+		Text:      "Tengo mucha ansiedad, necesito un ejercicio de respiración",
+	})
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	tags := strings.Join(out.AgentMessage.Tags, ",")
+	if tags != "agent:composer,cite:mindfulness/respiracion.md#1" {
+		t.Fatalf("expected the knowledge base chunk to be cited, got tags %q", tags)
+	}
+}
+
+func TestSendMessageRoutesModePerMessage(t *testing.T) {
+	ctx := context.Background()
+	svc := conversation.NewService(llm.NewMockLLM(), memory.NewSessionStore(), memory.NewMessageStore(), nil)
+
+	started, err := svc.StartSession(ctx, conversation.StartSessionInput{UserID: "u1", PreferredMode: domain.ModeCheckIn})
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+
+	send := func(text string) *conversation.SendMessageOutput {
+		t.Helper()
+		out, err := svc.SendMessage(ctx, conversation.SendMessageInput{SessionID: started.Session.ID, UserID: "u1", Text: text})
+		if err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+		return out
+	}
+
+	first := send("Hola, hoy estoy cansado")
+	if first.Routing.Mode != domain.ModeCheckIn || first.UserMessage.Mode != domain.ModeCheckIn {
+		t.Fatalf("expected check_in, got %+v", first.Routing)
+	}
+
+	second := send("¿Qué puedo hacer para dormir mejor? Quiero un plan con pasos concretos")
+	if !second.Routing.Changed() || second.AgentMessage.Mode != domain.ModeActionPlan {
+		t.Fatalf("expected a switch to action_plan, got %+v", second.Routing)
+	}
+
+	_, last, err := svc.GetSessionTimeline(ctx, started.Session.ID, 2, conversation.ViewReply)
+	if err != nil {
+		t.Fatalf("GetSessionTimeline failed: %v", err)
+	}
+	if last[0].Mode != domain.ModeActionPlan || last[1].Mode != domain.ModeActionPlan {
+		t.Fatalf("expected the mode stored on both messages, got %q and %q", last[0].Mode, last[1].Mode)
+	}
+}
+
+func TestSendMessageStoresAgentTrace(t *testing.T) {
+	ctx := context.Background()
+	svc := conversation.NewService(llm.NewMockLLM(), memory.NewSessionStore(), memory.NewMessageStore(), nil)
+
+	started, err := svc.StartSession(ctx, conversation.StartSessionInput{UserID: "u1", PreferredMode: domain.ModeActionPlan})
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+
+	out, err := svc.SendMessage(ctx, conversation.SendMessageInput{
+		SessionID: started.Session.ID,
+		UserID:    "u1",
+		Text:      "Necesito organizarme con el trabajo, ¿qué puedo hacer?",
+	})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	var trace []string
+	for _, m := range out.Trace {
+		if !m.IsTrace() || m.ReplyTo == nil || *m.ReplyTo != out.UserMessage.ID {
+			t.Fatalf("expected a trace message replying to the user message, got %+v", m)
+		}
+		trace = append(trace, m.Agent()+":"+m.ContentType)
+	}
+	if got := strings.Join(trace, ","); got != "listener:paraphrase,planner:task_list,reflector:reflection" {
+		t.Fatalf("unexpected trace: %s", got)
+	}
+	if out.AgentMessage.IsTrace() || out.AgentMessage.Agent() != "composer" || *out.AgentMessage.ReplyTo != out.UserMessage.ID {
+		t.Fatalf("unexpected reply message: %+v", out.AgentMessage)
+	}
+
+	// welcome, user message, reply
+	_, reply, err := svc.GetSessionTimeline(ctx, started.Session.ID, 0, conversation.ViewReply)
+	if err != nil {
+		t.Fatalf("GetSessionTimeline failed: %v", err)
+	}
+	if len(reply) != 3 || reply[2].ID != out.AgentMessage.ID {
+		t.Fatalf("expected the reply view without the trace, got %d messages", len(reply))
+	}
+
+	_, full, err := svc.GetSessionTimeline(ctx, started.Session.ID, 0, conversation.ViewTrace)
+	if err != nil {
+		t.Fatalf("GetSessionTimeline failed: %v", err)
+	}
+	if len(full) != 6 {
+		t.Fatalf("expected the trace view with every agent output, got %d messages", len(full))
+	}
+}
+
+func TestSendMessageSummarizesTurnsOutOfTheWindow(t *testing.T) {
+	ctx := context.Background()
+	mock := llm.NewMockLLM()
+	sessions := memory.NewSessionStore()
+	svc := conversation.NewService(mock, sessions, memory.NewMessageStore(), nil,
+		conversation.WithContextBuilder(contextwindow.NewBuilder(nil, contextwindow.NewLLMSummarizer(mock), contextwindow.Config{
+			TokenBudget:   150,
+			SummaryTokens: 50,
+		})),
+	)
+
+	started, err := svc.StartSession(ctx, conversation.StartSessionInput{UserID: "u1", PreferredMode: domain.ModeCheckIn})
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+
+	for _, text := range []string{
+		"Hoy estoy cansada porque dormí poco",
+		"En el trabajo tengo una entrega el viernes",
+		"Me cuesta concentrarme a la tarde",
+		"Además discutí con mi hermana ayer",
+	} {
+		if _, err := svc.SendMessage(ctx, conversation.SendMessageInput{SessionID: started.Session.ID, UserID: "u1", Text: text}); err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+	}
+
+	session, err := sessions.GetSession(started.Session.ID)
+	if err != nil {
+		t.Fatalf("GetSession failed: %v", err)
+	}
+	if !strings.Contains(session.Summary, "Hoy estoy cansada porque dormí poco") || session.SummaryThrough == "" {
+		t.Fatalf("expected the first turn summarized on the session, got %q (through %q)", session.Summary, session.SummaryThrough)
+	}
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	ctx := context.Background()
+	journal := memory.NewJournalStore()
+	svc := conversation.NewService(llm.NewMockLLM(), memory.NewSessionStore(), memory.NewMessageStore(), tools.NewJournalTool(journal))
+
+	started, err := svc.StartSession(ctx, conversation.StartSessionInput{UserID: "u1", PreferredMode: domain.ModeCheckIn})
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	id := started.Session.ID
+	send := func() error {
+		_, err := svc.SendMessage(ctx, conversation.SendMessageInput{SessionID: id, UserID: "u1", Text: "Hoy dormí poco y estoy cansada"})
+		return err
+	}
+	if err := send(); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	calls := observability.ToolCallCount("journal_store", observability.ToolOutcomeOK)
+	closed, err := svc.CloseSession(ctx, id)
+	if err != nil {
+		t.Fatalf("CloseSession failed: %v", err)
+	}
+	if s := closed.Session; s.Status != domain.SessionClosed || s.ClosedAt.IsZero() || !strings.Contains(s.Summary, "Hoy dormí poco") {
+		t.Fatalf("expected a closed, summarized session, got %+v", s)
+	}
+	if entry, err := journal.GetJournalEntry(closed.JournalEntryID); err != nil || entry.SessionID != id {
+		t.Fatalf("expected the final journal entry of the session, got %+v (%v)", entry, err)
+	}
+	if got := observability.ToolCallCount("journal_store", observability.ToolOutcomeOK); got != calls+1 {
+		t.Fatalf("expected the final journal entry audited as a tool call, got %d calls (was %d)", got, calls)
+	}
+
+	if err := send(); !errors.Is(err, conversation.ErrSessionNotActive) {
+		t.Fatalf("expected ErrSessionNotActive, got %v", err)
+	}
+	if _, err := svc.CloseSession(ctx, id); !errors.Is(err, conversation.ErrSessionTransition) {
+		t.Fatalf("expected ErrSessionTransition, got %v", err)
+	}
+
+	// Archived sessions are left out of the list unless asked for
+	if _, err := svc.ArchiveSession(ctx, id); err != nil {
+		t.Fatalf("ArchiveSession failed: %v", err)
+	}
+	list, err := svc.ListSessions(ctx, conversation.ListSessionsInput{UserID: "u1"})
+	if err != nil || len(list.Sessions) != 0 {
+		t.Fatalf("expected no listed sessions, got %+v (%v)", list, err)
+	}
+	list, err = svc.ListSessions(ctx, conversation.ListSessionsInput{UserID: "u1", Statuses: []domain.SessionStatus{domain.SessionArchived}})
+	if err != nil || len(list.Sessions) != 1 {
+		t.Fatalf("expected the archived session, got %+v (%v)", list, err)
+	}
+
+	resumed, err := svc.ResumeSession(ctx, id)
+	if err != nil {
+		t.Fatalf("ResumeSession failed: %v", err)
+	}
+	if !resumed.Session.IsActive() || !resumed.Session.ClosedAt.IsZero() {
+		t.Fatalf("expected an active session, got %+v", resumed.Session)
+	}
+	if err := send(); err != nil {
+		t.Fatalf("SendMessage after resume failed: %v", err)
+	}
+}
+
+// hookLLM runs hook before its first reply, as if something happened while
+// the model was thinking.
+type hookLLM struct {
+	domain.LLMClient
+	hook func()
+}
+
+func (l *hookLLM) GenerateReply(ctx context.Context, prompt string, convCtx domain.ConversationContext) (string, error) {
+	if hook := l.hook; hook != nil {
+		l.hook = nil
+		hook()
+	}
+	return l.LLMClient.GenerateReply(ctx, prompt, convCtx)
+}
+
+func TestSendMessageKeepsASessionClosedDuringTheTurn(t *testing.T) {
+	ctx := context.Background()
+	model := &hookLLM{LLMClient: llm.NewMockLLM()}
+	sessions := memory.NewSessionStore()
+	svc := conversation.NewService(model, sessions, memory.NewMessageStore(), nil)
+
+	started, err := svc.StartSession(ctx, conversation.StartSessionInput{UserID: "u1", PreferredMode: domain.ModeCheckIn})
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	id := started.Session.ID
+	model.hook = func() {
+		if _, err := svc.CloseSession(ctx, id); err != nil {
+			t.Errorf("CloseSession failed: %v", err)
+		}
+	}
+
+	if _, err := svc.SendMessage(ctx, conversation.SendMessageInput{SessionID: id, UserID: "u1", Text: "Hoy estoy cansada"}); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if s, _ := sessions.GetSession(id); s.CurrentStatus() != domain.SessionClosed {
+		t.Fatalf("expected the turn to leave the session closed, got %q", s.CurrentStatus())
+	}
+}
+
+func TestListSessionsPaginates(t *testing.T) {
+	ctx := context.Background()
+	svc := conversation.NewService(llm.NewMockLLM(), memory.NewSessionStore(), memory.NewMessageStore(), nil)
+
+	var ids []domain.SessionID
+	for range 3 {
+		started, err := svc.StartSession(ctx, conversation.StartSessionInput{UserID: "u1"})
+		if err != nil {
+			t.Fatalf("StartSession failed: %v", err)
+		}
+		ids = append(ids, started.Session.ID)
+	}
+	if _, err := svc.StartSession(ctx, conversation.StartSessionInput{UserID: "u2"}); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+
+	page, err := svc.ListSessions(ctx, conversation.ListSessionsInput{UserID: "u1", Limit: 2})
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(page.Sessions) != 2 || page.Sessions[0].ID != ids[2] || page.Sessions[1].ID != ids[1] || page.NextOffset != 2 {
+		t.Fatalf("expected the two newest sessions, got %+v", page)
+	}
+
+	page, err = svc.ListSessions(ctx, conversation.ListSessionsInput{UserID: "u1", Limit: 2, Offset: page.NextOffset})
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(page.Sessions) != 1 || page.Sessions[0].ID != ids[0] || page.NextOffset != 0 {
+		t.Fatalf("expected the last page with the oldest session, got %+v", page)
+	}
+}
+
+func TestServiceEnforcesSessionOwnership(t *testing.T) {
+	svc := conversation.NewService(llm.NewMockLLM(), memory.NewSessionStore(), memory.NewMessageStore(), nil)
+	ana := domain.WithSubject(context.Background(), "ana")
+	bob := domain.WithSubject(context.Background(), "bob")
+
+	if _, err := svc.StartSession(bob, conversation.StartSessionInput{UserID: "ana"}); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden starting a session for another user, got %v", err)
+	}
+	started, err := svc.StartSession(ana, conversation.StartSessionInput{UserID: "ana"})
+	if err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	id := started.Session.ID
+
+	// The UserID of the input is checked even without an authenticated user
+	if _, err := svc.SendMessage(context.Background(), conversation.SendMessageInput{SessionID: id, UserID: "bob", Text: "hola"}); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for a foreign UserID, got %v", err)
+	}
+	if _, err := svc.SendMessage(bob, conversation.SendMessageInput{SessionID: id, Text: "hola"}); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for another authenticated user, got %v", err)
+	}
+	if _, _, err := svc.GetSessionTimeline(bob, id, 0, conversation.ViewReply); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden reading another user's session, got %v", err)
+	}
+	if _, err := svc.CloseSession(bob, id); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden closing another user's session, got %v", err)
+	}
+	if _, err := svc.ListSessions(bob, conversation.ListSessionsInput{UserID: "ana"}); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden listing another user's sessions, got %v", err)
+	}
+
+	if _, err := svc.SendMessage(ana, conversation.SendMessageInput{SessionID: id, UserID: "ana", Text: "hola"}); err != nil {
+		t.Fatalf("SendMessage as the owner failed: %v", err)
+	}
+}
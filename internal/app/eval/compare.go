@@ -0,0 +1,57 @@
+package eval
+
+import "sort"
+
+// OverallScenario is the name used in regressions for the report's overall score.
+const OverallScenario = "(overall)"
+
+// Regression is a score drop larger than the allowed threshold.
+type Regression struct {
+	Scenario string  `json:"scenario"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+}
+
+// Delta returns current - baseline (negative for a regression).
+func (r Regression) Delta() float64 {
+	return r.Current - r.Baseline
+}
+
+// Compare returns the scenarios (and the overall score) whose score dropped
+// more than threshold compared to the baseline. Scenarios that are new or
+// no longer present are ignored.
+func Compare(baseline, current *Report, threshold float64) []Regression {
+	var out []Regression
+
+	if baseline.Score-current.Score > threshold {
+		out = append(out, Regression{
+			Scenario: OverallScenario,
+			Baseline: baseline.Score,
+			Current:  current.Score,
+		})
+	}
+
+	base := make(map[string]float64, len(baseline.Scenarios))
+	for _, s := range baseline.Scenarios {
+		base[s.Name] = s.Score
+	}
+
+	for _, s := range current.Scenarios {
+		prev, ok := base[s.Name]
+		if !ok {
+			continue
+		}
+		if prev-s.Score > threshold {
+			out = append(out, Regression{
+				Scenario: s.Name,
+				Baseline: prev,
+				Current:  s.Score,
+			})
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Delta() < out[j].Delta()
+	})
+	return out
+}
@@ -0,0 +1,116 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Report is the outcome of an eval run.
+type Report struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Score       float64          `json:"score"` // mean of scenario scores
+	Passed      int              `json:"passed"`
+	Failed      int              `json:"failed"`
+	Scenarios   []ScenarioResult `json:"scenarios"`
+}
+
+// NewReport aggregates scenario results.
+func NewReport(results []ScenarioResult, now time.Time) *Report {
+	r := &Report{
+		GeneratedAt: now,
+		Scenarios:   results,
+	}
+
+	var total float64
+	for _, s := range results {
+		total += s.Score
+		if s.Passed {
+			r.Passed++
+		} else {
+			r.Failed++
+		}
+	}
+	if len(results) > 0 {
+		r.Score = total / float64(len(results))
+	}
+	return r
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// LoadReport reads a report previously written with WriteJSON.
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading report: %w", err)
+	}
+
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("decoding report: %w", err)
+	}
+	return &r, nil
+}
+
+// WriteMarkdown writes a human readable summary, with the failed checks of each turn.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Farum eval report\n\n")
+	fmt.Fprintf(&b, "Generated at %s · score **%.2f** · %d passed · %d failed\n\n",
+		r.GeneratedAt.Format(time.RFC3339), r.Score, r.Passed, r.Failed)
+
+	b.WriteString("| Scenario | Score | Result |\n")
+	b.WriteString("|----------|-------|--------|\n")
+	for _, s := range r.Scenarios {
+		result := "✅ pass"
+		if !s.Passed {
+			result = "❌ fail"
+		}
+		fmt.Fprintf(&b, "| %s | %.2f | %s |\n", s.Name, s.Score, result)
+	}
+
+	for _, s := range r.Scenarios {
+		if s.Passed && s.Error == "" {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n## %s\n\n", s.Name)
+		if s.Error != "" {
+			fmt.Fprintf(&b, "Error: `%s`\n\n", s.Error)
+		}
+
+		for _, t := range s.Turns {
+			var failed []string
+			for _, c := range t.Checks {
+				if !c.Passed {
+					item := c.Name
+					if c.Detail != "" {
+						item += " (" + c.Detail + ")"
+					}
+					failed = append(failed, item)
+				}
+			}
+			if len(failed) == 0 {
+				continue
+			}
+
+			fmt.Fprintf(&b, "- Turn %d (score %.2f): %s\n", t.Index, t.Score, strings.Join(failed, ", "))
+			if t.Judge != nil {
+				fmt.Fprintf(&b, "  - Judge %.2f: %s\n", t.Judge.Score, t.Judge.Reason)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -1 +1,166 @@
-package eval
\ No newline at end of file
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// Scenario is a scripted conversation used to regression-test Farum's replies.
+//
+// Scenario files are JSON and can hold a single scenario or an array:
+//
+//	{
+//	  "name": "check_in_anxiety",
+//	  "mode": "check_in",
+//	  "turns": [
+//	    {
+//	      "user": "Hoy me siento muy ansioso por el trabajo",
+//	      "expect": {
+//	        "mode": "check_in",
+//	        "required_phrases": ["ansie"],
+//	        "forbidden_phrases": ["diagnóstico"],
+//	        "max_chars": 1500,
+//	        "rubric": "Validates the emotion before suggesting anything"
+//	      }
+//	    }
+//	  ]
+//	}
+type Scenario struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	UserID      string                 `json:"user_id,omitempty"`
+	Mode        domain.InteractionMode `json:"mode,omitempty"` // session preferred mode
+	Turns       []Turn                 `json:"turns"`
+}
+
+// Turn is one user message and what we expect from Farum's reply.
+type Turn struct {
+	User   string       `json:"user"`
+	Expect Expectations `json:"expect"`
+}
+
+// Expectations are the rule-based checks (and optional judge rubric) for a reply.
+type Expectations struct {
+	// Mode expected on the agent message.
+	Mode domain.InteractionMode `json:"mode,omitempty"`
+
+	// RequiredPhrases must all appear in the reply (case-insensitive).
+	RequiredPhrases []string `json:"required_phrases,omitempty"`
+
+	// ForbiddenPhrases must not appear in the reply (case-insensitive).
+	ForbiddenPhrases []string `json:"forbidden_phrases,omitempty"`
+
+	// MaxChars caps the reply length (0 = no limit).
+	MaxChars int `json:"max_chars,omitempty"`
+
+	// Safety expectations for this turn.
+	Safety *SafetyExpectation `json:"safety,omitempty"`
+
+	// Rubric is given to the LLM judge, when one is configured.
+	Rubric string `json:"rubric,omitempty"`
+}
+
+// SafetyExpectation checks the behaviour of the safety gate.
+type SafetyExpectation struct {
+	// CrisisResponse: true if the agents must be skipped in favour of the
+	// crisis-resources reply, false if they must not.
+	CrisisResponse bool `json:"crisis_response"`
+
+	// Tags expected on the user message (e.g. "safety:high").
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Validate checks that the scenario can be run.
+func (s Scenario) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("scenario name is required")
+	}
+	if len(s.Turns) == 0 {
+		return fmt.Errorf("scenario %q: at least one turn is required", s.Name)
+	}
+	for i, t := range s.Turns {
+		if strings.TrimSpace(t.User) == "" {
+			return fmt.Errorf("scenario %q: turn %d has an empty user message", s.Name, i)
+		}
+		if t.Expect.MaxChars < 0 {
+			return fmt.Errorf("scenario %q: turn %d has a negative max_chars", s.Name, i)
+		}
+	}
+	return nil
+}
+
+// LoadScenarioFile reads a JSON file with one scenario or an array of scenarios.
+func LoadScenarioFile(path string) ([]Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading scenario file: %w", err)
+	}
+
+	var scenarios []Scenario
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &scenarios); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", path, err)
+		}
+	} else {
+		var s Scenario
+		if err := json.Unmarshal(trimmed, &s); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", path, err)
+		}
+		scenarios = []Scenario{s}
+	}
+
+	for _, s := range scenarios {
+		if err := s.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return scenarios, nil
+}
+
+// LoadScenarios loads scenarios from files and/or directories (every *.json inside).
+// Scenario names must be unique.
+func LoadScenarios(paths ...string) ([]Scenario, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("scenario path: %w", err)
+		}
+
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(p, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+
+	seen := map[string]string{}
+	var out []Scenario
+	for _, f := range files {
+		scenarios, err := LoadScenarioFile(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range scenarios {
+			if prev, dup := seen[s.Name]; dup {
+				return nil, fmt.Errorf("duplicate scenario %q in %s and %s", s.Name, prev, f)
+			}
+			seen[s.Name] = f
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
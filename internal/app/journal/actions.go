@@ -0,0 +1,188 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+var (
+	// ErrJournalDisabled is returned by write operations when no JournalStore is configured.
+	ErrJournalDisabled = errors.New("journal is disabled")
+
+	// ErrActionNotFound is returned when the action does not exist for that user.
+	ErrActionNotFound = errors.New("action not found")
+
+	// ErrInvalidActionStatus is returned when the requested status is not supported.
+	ErrInvalidActionStatus = errors.New("invalid action status")
+)
+
+// OpenAction is a pending action together with the entry it belongs to.
+type OpenAction struct {
+	EntryID   domain.JournalEntryID
+	SessionID domain.SessionID
+	Action    domain.JournalAction
+}
+
+// ActionStats summarizes the progress of a user on their action plans.
+type ActionStats struct {
+	Total   int `json:"total"`
+	Pending int `json:"pending"`
+	Done    int `json:"done"`
+	Skipped int `json:"skipped"`
+
+	// CompletionRate is done / (done + skipped + pending), 0 when there are no actions.
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+// UpdateActionInput describes a change of status on an action.
+type UpdateActionInput struct {
+	UserID   domain.UserID
+	ActionID string
+	Status   domain.ActionStatus
+	Notes    string // optional; empty keeps the current notes
+}
+
+// UpdateActionOutput returns the updated action and the entry it belongs to.
+type UpdateActionOutput struct {
+	EntryID domain.JournalEntryID
+	Action  domain.JournalAction
+}
+
+// ListOpenActions returns every pending action of the user across all journal entries,
+// oldest first.
+func (s *Service) ListOpenActions(ctx context.Context, userID domain.UserID) ([]OpenAction, error) {
+	entries, err := s.allEntries(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []OpenAction{}
+	for _, e := range entries {
+		for _, a := range e.ActionPlan {
+			if a.Status != domain.ActionStatusPending {
+				continue
+			}
+			out = append(out, OpenAction{
+				EntryID:   e.ID,
+				SessionID: e.SessionID,
+				Action:    a,
+			})
+		}
+	}
+
+	observability.LoggerFromContext(ctx).Info("listed open actions",
+		"user_id", userID,
+		"count", len(out),
+	)
+	return out, nil
+}
+
+// UpdateAction marks an action as done, skipped or back to pending, optionally with notes.
+func (s *Service) UpdateAction(ctx context.Context, in UpdateActionInput) (*UpdateActionOutput, error) {
+	log := observability.LoggerFromContext(ctx).With(
+		"user_id", in.UserID,
+		"action_id", in.ActionID,
+		"status", in.Status,
+	)
+
+	if s.store == nil {
+		return nil, ErrJournalDisabled
+	}
+
+	switch in.Status {
+	case domain.ActionStatusPending, domain.ActionStatusDone, domain.ActionStatusSkipped:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidActionStatus, in.Status)
+	}
+
+	entries, err := s.allEntries(in.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		idx := findAction(e.ActionPlan, in.ActionID)
+		if idx < 0 {
+			continue
+		}
+
+		now := s.now()
+
+		// Work on a copy: stores may hand out shared pointers
+		updated := *e
+		updated.ActionPlan = append([]domain.JournalAction(nil), e.ActionPlan...)
+		updated.UpdatedAt = now
+
+		action := &updated.ActionPlan[idx]
+		action.Status = in.Status
+		if notes := strings.TrimSpace(in.Notes); notes != "" {
+			action.Notes = notes
+		}
+		action.UpdatedAt = now
+
+		if err := s.store.UpdateJournalEntry(&updated); err != nil {
+			log.Error("failed to update journal entry", "error", err)
+			return nil, err
+		}
+
+		log.Info("action updated", "entry_id", updated.ID)
+		return &UpdateActionOutput{
+			EntryID: updated.ID,
+			Action:  *action,
+		}, nil
+	}
+
+	return nil, ErrActionNotFound
+}
+
+// GetActionStats returns completion stats across all the user's actions.
+func (s *Service) GetActionStats(ctx context.Context, userID domain.UserID) (*ActionStats, error) {
+	entries, err := s.allEntries(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ActionStats{}
+	for _, e := range entries {
+		for _, a := range e.ActionPlan {
+			stats.Total++
+			switch a.Status {
+			case domain.ActionStatusDone:
+				stats.Done++
+			case domain.ActionStatusSkipped:
+				stats.Skipped++
+			default:
+				stats.Pending++
+			}
+		}
+	}
+
+	if stats.Total > 0 {
+		stats.CompletionRate = float64(stats.Done) / float64(stats.Total)
+	}
+	return stats, nil
+}
+
+// --- internal helpers --- //
+
+// allEntries returns every journal entry of the user (empty if the journal is disabled).
+func (s *Service) allEntries(userID domain.UserID) ([]*domain.JournalEntry, error) {
+	if s.store == nil {
+		return []*domain.JournalEntry{}, nil
+	}
+	return s.store.ListJournalEntriesByUser(userID, 0)
+}
+
+func findAction(actions []domain.JournalAction, id string) int {
+	for i, a := range actions {
+		if a.ID == id {
+			return i
+		}
+	}
+	return -1
+}
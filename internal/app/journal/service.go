@@ -2,19 +2,22 @@ package journal
 
 import (
 	"context"
+	"time"
 
 	"github.com/PabloGalante/farum-agent/internal/domain"
 )
 
-// Service holds the logic of reading journal entries
+// Service holds the logic of reading journal entries and tracking their actions
 type Service struct {
 	store domain.JournalStore
+	now   func() time.Time
 }
 
 // NewService creates a journal service from a JournalStore
 func NewService(store domain.JournalStore) *Service {
 	return &Service{
 		store: store,
+		now:   time.Now,
 	}
 }
 
@@ -27,8 +30,8 @@ func (s *Service) GetUserJournal(
 ) ([]*domain.JournalEntry, error) {
 
 	if s.store == nil {
-		// In GCP mode, until we implement FirestoreJournalStore,
-		// the store can be nil. We return an empty slice without error
+		// The journal can be disabled (no JournalStore configured).
+		// We return an empty slice without error
 		return []*domain.JournalEntry{}, nil
 	}
 
@@ -36,7 +39,7 @@ func (s *Service) GetUserJournal(
 		limit = 20
 	}
 
-	// For now we ignore ctx because MemoryJournalStore does not use it,
+	// For now we ignore ctx because the stores do not take it,
 	// but the interface could be extended in the future
 	return s.store.ListJournalEntriesByUser(userID, limit)
 }
@@ -0,0 +1,84 @@
+package journal_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/storage/memory"
+	"github.com/PabloGalante/farum-agent/internal/app/journal"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+func TestActionTracking(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewJournalStore()
+	svc := journal.NewService(store)
+
+	now := time.Now()
+	for i, actions := range [][]domain.JournalAction{
+		{
+			{ID: "a-1", Description: "Caminar 10 minutos", Status: domain.ActionStatusPending, CreatedAt: now},
+			{ID: "a-2", Description: "Escribir 3 cosas buenas", Status: domain.ActionStatusDone, CreatedAt: now},
+		},
+		{
+			{ID: "a-3", Description: "Llamar a una amiga", Status: domain.ActionStatusPending, CreatedAt: now},
+		},
+	} {
+		err := store.AppendJournalEntry(&domain.JournalEntry{
+			ID:         domain.JournalEntryID([]string{"e-1", "e-2"}[i]),
+			SessionID:  "s-1",
+			UserID:     "user-1",
+			CreatedAt:  now,
+			ActionPlan: actions,
+		})
+		if err != nil {
+			t.Fatalf("AppendJournalEntry failed: %v", err)
+		}
+	}
+
+	open, err := svc.ListOpenActions(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("ListOpenActions failed: %v", err)
+	}
+	if len(open) != 2 || open[0].Action.ID != "a-1" || open[1].EntryID != "e-2" {
+		t.Fatalf("unexpected open actions: %+v", open)
+	}
+
+	out, err := svc.UpdateAction(ctx, journal.UpdateActionInput{
+		UserID:   "user-1",
+		ActionID: "a-3",
+		Status:   domain.ActionStatusSkipped,
+		Notes:    "No tuve tiempo",
+	})
+	if err != nil {
+		t.Fatalf("UpdateAction failed: %v", err)
+	}
+	if out.EntryID != "e-2" || out.Action.Status != domain.ActionStatusSkipped || out.Action.Notes != "No tuve tiempo" {
+		t.Fatalf("unexpected update output: %+v", out)
+	}
+
+	open, _ = svc.ListOpenActions(ctx, "user-1")
+	if len(open) != 1 || open[0].Action.ID != "a-1" {
+		t.Fatalf("expected only a-1 open after update, got %+v", open)
+	}
+
+	stats, err := svc.GetActionStats(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("GetActionStats failed: %v", err)
+	}
+	if stats.Total != 3 || stats.Done != 1 || stats.Skipped != 1 || stats.Pending != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	_, err = svc.UpdateAction(ctx, journal.UpdateActionInput{UserID: "user-2", ActionID: "a-1", Status: domain.ActionStatusDone})
+	if !errors.Is(err, journal.ErrActionNotFound) {
+		t.Fatalf("expected ErrActionNotFound for another user's action, got %v", err)
+	}
+
+	_, err = svc.UpdateAction(ctx, journal.UpdateActionInput{UserID: "user-1", ActionID: "a-1", Status: "maybe"})
+	if !errors.Is(err, journal.ErrInvalidActionStatus) {
+		t.Fatalf("expected ErrInvalidActionStatus, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+// Package knowledge is Farum's local knowledge base: vetted psychoeducation
+// documents (CBT, mindfulness exercises...) that agents retrieve from (RAG).
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// DefaultMinScore drops hits that share (almost) nothing with the query.
+const DefaultMinScore = 0.1
+
+// Base ingests markdown documents into a vector index and searches them.
+// It implements domain.KnowledgeRetriever.
+type Base struct {
+	embedder      domain.Embedder
+	index         domain.VectorIndex
+	maxChunkChars int
+	minScore      float64
+}
+
+// NewBase creates an empty knowledge base.
+func NewBase(embedder domain.Embedder, index domain.VectorIndex) *Base {
+	return &Base{
+		embedder:      embedder,
+		index:         index,
+		maxChunkChars: DefaultMaxChunkChars,
+		minScore:      DefaultMinScore,
+	}
+}
+
+// IngestDir ingests every .md file under dir (recursively) and returns the
+// number of chunks indexed. Sources are the paths relative to dir.
+func (b *Base) IngestDir(ctx context.Context, dir string) (int, error) {
+	total := 0
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(p), ".md") {
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", p, err)
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		n, err := b.Ingest(ctx, filepath.ToSlash(rel), string(data))
+		if err != nil {
+			return err
+		}
+		total += n
+		return nil
+	})
+	if err != nil {
+		return total, fmt.Errorf("ingesting knowledge base %s: %w", dir, err)
+	}
+
+	observability.LoggerFromContext(ctx).Info("knowledge base ingested", "dir", dir, "chunks", total)
+	return total, nil
+}
+
+// Ingest chunks, embeds and indexes one markdown document.
+func (b *Base) Ingest(ctx context.Context, source, markdown string) (int, error) {
+	chunks := ChunkMarkdown(source, markdown, b.maxChunkChars)
+	if len(chunks) == 0 {
+		return 0, nil
+	}
+
+	texts := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		// The title and heading help match chunks whose text does not repeat them
+		texts = append(texts, c.Title+"\n"+c.Heading+"\n"+c.Text)
+	}
+
+	vectors, err := b.embedder.Embed(ctx, texts)
+	if err != nil {
+		return 0, fmt.Errorf("embedding %s: %w", source, err)
+	}
+	if err := b.index.Upsert(chunks, vectors); err != nil {
+		return 0, fmt.Errorf("indexing %s: %w", source, err)
+	}
+	return len(chunks), nil
+}
+
+// Len returns the number of indexed chunks.
+func (b *Base) Len() int {
+	return b.index.Len()
+}
+
+// Search implements domain.KnowledgeRetriever: the k best chunks for query
+// with a score of at least the minimum score.
+func (b *Base) Search(ctx context.Context, query string, k int) ([]domain.KnowledgeHit, error) {
+	vectors, err := b.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embedding query: %w", err)
+	}
+
+	hits, err := b.index.Search(vectors[0], k)
+	if err != nil {
+		return nil, err
+	}
+
+	out := hits[:0]
+	for _, h := range hits {
+		if h.Score >= b.minScore {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+// Citations returns the citations of hits, in order.
+func Citations(hits []domain.KnowledgeHit) []domain.Citation {
+	out := make([]domain.Citation, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, domain.Citation{ChunkID: h.Chunk.ID, Source: h.Chunk.Source, Title: h.Chunk.Title})
+	}
+	return out
+}
+
+// FormatContext renders hits as a numbered prompt section, so the model
+// can cite them as [1], [2]...
+func FormatContext(hits []domain.KnowledgeHit) string {
+	var b strings.Builder
+	for i, h := range hits {
+		fmt.Fprintf(&b, "[%d] %s — %s (%s)\n%s\n\n", i+1, h.Chunk.Title, h.Chunk.Heading, h.Chunk.Source, h.Chunk.Text)
+	}
+	return strings.TrimSpace(b.String())
+}
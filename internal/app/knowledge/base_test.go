@@ -0,0 +1,99 @@
+package knowledge_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/embedding"
+	"github.com/PabloGalante/farum-agent/internal/adapters/storage/memory"
+	"github.com/PabloGalante/farum-agent/internal/app/knowledge"
+)
+
+const breathingDoc = `# Respiración cuadrada
+
+Un ejercicio breve para bajar la activación cuando aparece la ansiedad.
+
+## Cómo hacerlo
+
+Inhalá contando hasta 4, sostené 4, exhalá 4 y esperá 4.
+
+Repetí el ciclo durante dos minutos.
+
+## Cuándo usarlo
+
+Antes de una reunión difícil o al notar el corazón acelerado.
+`
+
+const sleepDoc = `# Higiene del sueño
+
+Acostarte y levantarte a la misma hora ayuda a regular el descanso.
+Evitá pantallas la última hora antes de dormir.
+`
+
+func TestChunkMarkdown(t *testing.T) {
+	chunks := knowledge.ChunkMarkdown("mindfulness/respiracion.md", breathingDoc, 0)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks (intro + 2 sections), got %d: %+v", len(chunks), chunks)
+	}
+
+	how := chunks[1]
+	if how.ID != "mindfulness/respiracion.md#2" || how.Title != "Respiración cuadrada" || how.Heading != "Cómo hacerlo" {
+		t.Fatalf("unexpected chunk metadata: %+v", how)
+	}
+	if !strings.Contains(how.Text, "Inhalá") || !strings.Contains(how.Text, "dos minutos") {
+		t.Fatalf("expected both paragraphs in the section chunk, got %q", how.Text)
+	}
+
+	// A small limit splits sections on paragraph boundaries
+	small := knowledge.ChunkMarkdown("mindfulness/respiracion.md", breathingDoc, 60)
+	if len(small) != 4 {
+		t.Fatalf("expected the 2-paragraph section to be split, got %d chunks", len(small))
+	}
+}
+
+func TestBaseIngestDirAndSearch(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "mindfulness"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"mindfulness/respiracion.md": breathingDoc,
+		"sueno.md":                   sleepDoc,
+		"notes.txt":                  "not markdown, ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	kb := knowledge.NewBase(embedding.NewHashingEmbedder(0), memory.NewVectorIndex())
+	n, err := kb.IngestDir(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("IngestDir: %v", err)
+	}
+	if n != 4 || kb.Len() != 4 {
+		t.Fatalf("expected 4 chunks, got %d (index %d)", n, kb.Len())
+	}
+
+	hits, err := kb.Search(context.Background(), "un ejercicio de respiración para la ansiedad", 2)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(hits) == 0 || hits[0].Chunk.Source != "mindfulness/respiracion.md" {
+		t.Fatalf("expected the breathing exercise first, got %+v", hits)
+	}
+
+	none, _ := kb.Search(context.Background(), "receta de empanadas salteñas", 2)
+	if len(none) != 0 {
+		t.Fatalf("expected no hits for an unrelated query, got %+v", none)
+	}
+
+	// Re-ingesting the same files replaces the chunks instead of duplicating them
+	if _, err := kb.IngestDir(context.Background(), dir); err != nil || kb.Len() != 4 {
+		t.Fatalf("expected an idempotent ingestion, got %d chunks (%v)", kb.Len(), err)
+	}
+}
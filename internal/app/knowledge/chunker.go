@@ -0,0 +1,116 @@
+package knowledge
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// DefaultMaxChunkChars is the chunk size used when none is given.
+const DefaultMaxChunkChars = 800
+
+// ChunkMarkdown splits a markdown document into chunks: one per section
+// (a #, ## or ### heading and its text), with long sections split on
+// paragraph boundaries to stay under maxChars. The document title is its
+// first "# " heading, or the file name.
+func ChunkMarkdown(source, content string, maxChars int) []domain.KnowledgeChunk {
+	if maxChars <= 0 {
+		maxChars = DefaultMaxChunkChars
+	}
+
+	title := strings.TrimSuffix(path.Base(source), path.Ext(source))
+
+	type section struct {
+		heading    string
+		paragraphs []string
+	}
+	var (
+		sections []section
+		cur      section
+		para     []string
+	)
+	flushPara := func() {
+		if p := strings.TrimSpace(strings.Join(para, "\n")); p != "" {
+			cur.paragraphs = append(cur.paragraphs, p)
+		}
+		para = nil
+	}
+	flushSection := func() {
+		flushPara()
+		if len(cur.paragraphs) > 0 {
+			sections = append(sections, cur)
+		}
+		cur = section{}
+	}
+
+	seenTitle := false
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if level, text, ok := markdownHeading(line); ok && level <= 3 {
+			flushSection()
+			if level == 1 && !seenTitle {
+				title, seenTitle = text, true
+			}
+			cur.heading = text
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			flushPara()
+			continue
+		}
+		para = append(para, line)
+	}
+	flushSection()
+
+	var chunks []domain.KnowledgeChunk
+	for _, s := range sections {
+		heading := s.heading
+		if heading == "" {
+			heading = title
+		}
+		for _, text := range packParagraphs(s.paragraphs, maxChars) {
+			chunks = append(chunks, domain.KnowledgeChunk{
+				ID:      fmt.Sprintf("%s#%d", source, len(chunks)+1),
+				Source:  source,
+				Title:   title,
+				Heading: heading,
+				Text:    text,
+			})
+		}
+	}
+	return chunks
+}
+
+// markdownHeading parses "## Text" into (2, "Text").
+func markdownHeading(line string) (int, string, bool) {
+	trimmed := strings.TrimLeft(line, "#")
+	level := len(line) - len(trimmed)
+	if level == 0 || level > 6 || !strings.HasPrefix(trimmed, " ") {
+		return 0, "", false
+	}
+	return level, strings.TrimSpace(trimmed), true
+}
+
+// packParagraphs joins paragraphs into texts of at most maxChars
+// (a single longer paragraph is kept whole rather than cut mid-sentence).
+func packParagraphs(paragraphs []string, maxChars int) []string {
+	var (
+		out []string
+		cur strings.Builder
+	)
+	for _, p := range paragraphs {
+		if cur.Len() > 0 && cur.Len()+2+len(p) > maxChars {
+			out = append(out, cur.String())
+			cur.Reset()
+		}
+		if cur.Len() > 0 {
+			cur.WriteString("\n\n")
+		}
+		cur.WriteString(p)
+	}
+	if cur.Len() > 0 {
+		out = append(out, cur.String())
+	}
+	return out
+}
@@ -0,0 +1,232 @@
+// Package memories recalls what Farum knows about a user from previous
+// sessions (journal entries, open actions, mood trend) so agents can use it.
+package memories
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// Config tunes the selection. Zero values use the defaults.
+type Config struct {
+	MaxEntries     int           // past sessions recalled (default 3)
+	MaxOpenActions int           // pending actions recalled (default 3)
+	MoodSessions   int           // sessions summarized in the mood trend (default 5)
+	Candidates     int           // journal entries considered (default 50)
+	HalfLife       time.Duration // recency half-life (default 14 days)
+	MinScore       float64       // entries scoring lower are left out (default 0.15)
+
+	// SimilarityWeight balances similarity to the current message against
+	// recency (default 0.7 similarity / 0.3 recency).
+	SimilarityWeight float64
+}
+
+// Service selects the memories of a user for the current message.
+type Service struct {
+	store    domain.JournalStore
+	embedder domain.Embedder
+	cfg      Config
+	now      func() time.Time
+}
+
+// NewService creates a memory service on top of the journal.
+func NewService(store domain.JournalStore, embedder domain.Embedder, cfg Config) *Service {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 3
+	}
+	if cfg.MaxOpenActions <= 0 {
+		cfg.MaxOpenActions = 3
+	}
+	if cfg.MoodSessions <= 0 {
+		cfg.MoodSessions = 5
+	}
+	if cfg.Candidates <= 0 {
+		cfg.Candidates = 50
+	}
+	if cfg.HalfLife <= 0 {
+		cfg.HalfLife = 14 * 24 * time.Hour
+	}
+	if cfg.MinScore <= 0 {
+		cfg.MinScore = 0.15
+	}
+	if cfg.SimilarityWeight <= 0 || cfg.SimilarityWeight > 1 {
+		cfg.SimilarityWeight = 0.7
+	}
+
+	return &Service{
+		store:    store,
+		embedder: embedder,
+		cfg:      cfg,
+		now:      time.Now,
+	}
+}
+
+// Recall returns the memories of userID relevant to message, most important
+// first: the mood trend, the past sessions most similar and recent, then the
+// open actions. Entries of the current session are skipped (they are
+// already in the history).
+func (s *Service) Recall(
+	ctx context.Context,
+	userID domain.UserID,
+	currentSession domain.SessionID,
+	message string,
+) ([]domain.Memory, error) {
+	if s.store == nil {
+		return nil, nil
+	}
+
+	all, err := s.store.ListJournalEntriesByUser(userID, s.cfg.Candidates)
+	if err != nil {
+		return nil, fmt.Errorf("listing journal entries: %w", err)
+	}
+
+	var entries []*domain.JournalEntry
+	for _, e := range all {
+		if e.SessionID != currentSession {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	// Newest first, whatever the store order is
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt.After(entries[j].CreatedAt) })
+
+	var out []domain.Memory
+	if m, ok := s.moodTrend(entries); ok {
+		out = append(out, m)
+	}
+
+	relevant, err := s.relevantEntries(ctx, entries, message)
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, relevant...)
+	out = append(out, s.openActions(entries)...)
+
+	observability.LoggerFromContext(ctx).Info("memories recalled",
+		"user_id", userID,
+		"candidates", len(entries),
+		"memories", len(out),
+	)
+	return out, nil
+}
+
+// relevantEntries scores entries by similarity to message and recency.
+func (s *Service) relevantEntries(ctx context.Context, entries []*domain.JournalEntry, message string) ([]domain.Memory, error) {
+	texts := []string{message}
+	for _, e := range entries {
+		texts = append(texts, e.ProblemSummary+"\n"+e.Reflection)
+	}
+
+	vectors, err := s.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embedding memories: %w", err)
+	}
+
+	now := s.now()
+	var scored []domain.Memory
+	for i, e := range entries {
+		similarity := math.Max(0, cosine(vectors[0], vectors[i+1]))
+		recency := math.Exp2(-now.Sub(e.CreatedAt).Hours() / s.cfg.HalfLife.Hours())
+		score := s.cfg.SimilarityWeight*similarity + (1-s.cfg.SimilarityWeight)*recency
+		if score < s.cfg.MinScore || strings.TrimSpace(e.ProblemSummary) == "" {
+			continue
+		}
+
+		text := fmt.Sprintf("[%s] Past session: %s", e.CreatedAt.Format("2006-01-02"), e.ProblemSummary)
+		if e.MoodBefore != "" && e.MoodAfter != "" {
+			text += fmt.Sprintf(" (mood: %s → %s)", e.MoodBefore, e.MoodAfter)
+		}
+		scored = append(scored, domain.Memory{
+			Kind:      domain.MemoryJournal,
+			Text:      text,
+			SourceID:  string(e.ID),
+			CreatedAt: e.CreatedAt,
+			Score:     score,
+		})
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+	if len(scored) > s.cfg.MaxEntries {
+		scored = scored[:s.cfg.MaxEntries]
+	}
+	return scored, nil
+}
+
+// openActions returns the most recent pending actions.
+func (s *Service) openActions(entries []*domain.JournalEntry) []domain.Memory {
+	var out []domain.Memory
+	for _, e := range entries {
+		for _, a := range e.ActionPlan {
+			if len(out) == s.cfg.MaxOpenActions {
+				return out
+			}
+			if a.Status != domain.ActionStatusPending {
+				continue
+			}
+			out = append(out, domain.Memory{
+				Kind:      domain.MemoryOpenAction,
+				Text:      fmt.Sprintf("[%s] Open action: %s", a.CreatedAt.Format("2006-01-02"), a.Description),
+				SourceID:  a.ID,
+				CreatedAt: a.CreatedAt,
+			})
+		}
+	}
+	return out
+}
+
+// moodTrend summarizes the moods of the last sessions, oldest first.
+func (s *Service) moodTrend(entries []*domain.JournalEntry) (domain.Memory, bool) {
+	var parts []string
+	for _, e := range entries {
+		if len(parts) == s.cfg.MoodSessions {
+			break
+		}
+		if e.MoodBefore == "" && e.MoodAfter == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s → %s (%s)", orDash(e.MoodBefore), orDash(e.MoodAfter), e.CreatedAt.Format("2006-01-02")))
+	}
+	if len(parts) < 2 {
+		// A single session is not a trend (and it is already in its summary)
+		return domain.Memory{}, false
+	}
+
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return domain.Memory{
+		Kind:      domain.MemoryMoodTrend,
+		Text:      "Mood in recent sessions: " + strings.Join(parts, "; "),
+		CreatedAt: entries[0].CreatedAt,
+	}, true
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
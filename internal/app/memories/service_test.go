@@ -0,0 +1,85 @@
+package memories_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/embedding"
+	"github.com/PabloGalante/farum-agent/internal/adapters/storage/memory"
+	"github.com/PabloGalante/farum-agent/internal/app/memories"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+func TestRecall(t *testing.T) {
+	store := memory.NewJournalStore()
+	now := time.Now()
+	day := 24 * time.Hour
+
+	entries := []*domain.JournalEntry{
+		{
+			ID: "e1", SessionID: "s1", CreatedAt: now.Add(-20 * day),
+			ProblemSummary: "Ansiedad antes de las presentaciones en el trabajo",
+			MoodBefore:     "ansiedad", MoodAfter: "más tranquilo",
+			ActionPlan: []domain.JournalAction{
+				{ID: "a1", Description: "Practicar la presentación en voz alta", Status: domain.ActionStatusPending, CreatedAt: now.Add(-20 * day)},
+				{ID: "a2", Description: "Dormir 8 horas", Status: domain.ActionStatusDone, CreatedAt: now.Add(-20 * day)},
+			},
+		},
+		{
+			ID: "e2", SessionID: "s2", CreatedAt: now.Add(-2 * day),
+			ProblemSummary: "Discusión con la pareja sobre las tareas de la casa",
+			MoodBefore:     "enojo", MoodAfter: "aliviado",
+		},
+		{
+			// The current session: already in the history, never recalled
+			ID: "e3", SessionID: "current", CreatedAt: now,
+			ProblemSummary: "Ansiedad por la presentación de mañana en el trabajo",
+		},
+	}
+	for _, e := range entries {
+		e.UserID = "u1"
+		if err := store.AppendJournalEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	svc := memories.NewService(store, embedding.NewHashingEmbedder(0), memories.Config{})
+	got, err := svc.Recall(context.Background(), "u1", "current", "Mañana tengo una presentación en el trabajo y me da ansiedad")
+	if err != nil {
+		t.Fatalf("Recall: %v", err)
+	}
+
+	var kinds []string
+	for _, m := range got {
+		kinds = append(kinds, string(m.Kind))
+		if m.SourceID == "e3" {
+			t.Fatalf("expected the current session to be skipped, got %+v", m)
+		}
+	}
+	want := "mood_trend,journal,journal,open_action"
+	if strings.Join(kinds, ",") != want {
+		t.Fatalf("unexpected memories %s, want %s: %+v", strings.Join(kinds, ","), want, got)
+	}
+
+	// The similar (older) session ranks above the recent unrelated one
+	if got[1].SourceID != "e1" || got[2].SourceID != "e2" || got[1].Score <= got[2].Score {
+		t.Fatalf("expected e1 before e2, got %+v", got[1:3])
+	}
+	if !strings.Contains(got[0].Text, "ansiedad → más tranquilo") || !strings.HasSuffix(got[0].Text, ")") {
+		t.Fatalf("unexpected mood trend %q", got[0].Text)
+	}
+	if !strings.Contains(got[3].Text, "Practicar la presentación") {
+		t.Fatalf("expected only the pending action, got %q", got[3].Text)
+	}
+}
+
+func TestRecallWithoutJournal(t *testing.T) {
+	svc := memories.NewService(memory.NewJournalStore(), embedding.NewHashingEmbedder(0), memories.Config{})
+
+	got, err := svc.Recall(context.Background(), "nobody", "s1", "hola")
+	if err != nil || len(got) != 0 {
+		t.Fatalf("expected no memories, got %+v, %v", got, err)
+	}
+}
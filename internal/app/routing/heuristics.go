@@ -0,0 +1,171 @@
+package routing
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// Signal is a pattern that adds Weight to the evidence for Mode. Patterns
+// are matched against the normalized text (lowercase, without accents).
+type Signal struct {
+	Name    string
+	Mode    domain.InteractionMode
+	Weight  float64
+	Pattern *regexp.Regexp
+}
+
+// HeuristicClassifier scores modes with multilingual keyword signals. Like
+// the safety rules it never fails and never calls the network.
+// convCtx.History is expected not to include the message being classified.
+type HeuristicClassifier struct {
+	signals []Signal
+
+	// When the message itself has no signal (e.g. "sí, eso"), earlier user
+	// messages count this much (halving per message back), so a short
+	// follow-up keeps the topic of the conversation.
+	historyWeight   float64
+	historyMessages int
+}
+
+// longMessageRunes is the length from which a message hints at a deep dive.
+const longMessageRunes = 400
+
+// NewHeuristicClassifier creates a classifier with the built-in signals plus any extra ones.
+func NewHeuristicClassifier(extra ...Signal) *HeuristicClassifier {
+	signals := append([]Signal(nil), defaultSignals...)
+	signals = append(signals, extra...)
+	return &HeuristicClassifier{
+		signals:         signals,
+		historyWeight:   0.5,
+		historyMessages: 2,
+	}
+}
+
+func (c *HeuristicClassifier) Classify(
+	ctx context.Context,
+	text string,
+	convCtx domain.ConversationContext,
+) (domain.ModeScores, error) {
+	// Every mode starts with the same prior, so weak evidence moves the
+	// scores a little and strong evidence a lot.
+	evidence := domain.ModeScores{}
+	for _, m := range domain.InteractionModes {
+		evidence[m] = 1
+	}
+	if c.addEvidence(evidence, text, 1) {
+		return normalizeScores(evidence), nil
+	}
+
+	weight := c.historyWeight
+	seen := 0
+	for i := len(convCtx.History) - 1; i >= 0 && seen < c.historyMessages; i-- {
+		m := convCtx.History[i]
+		if m.Author != domain.RoleUser {
+			continue
+		}
+		c.addEvidence(evidence, m.Text, weight)
+		weight /= 2
+		seen++
+	}
+
+	return normalizeScores(evidence), nil
+}
+
+// addEvidence adds the signals found in text and reports whether there was any.
+func (c *HeuristicClassifier) addEvidence(evidence domain.ModeScores, text string, factor float64) bool {
+	found := false
+	normalized := normalize(text)
+	for _, s := range c.signals {
+		if s.Pattern.MatchString(normalized) {
+			evidence[s.Mode] += s.Weight * factor
+			found = true
+		}
+	}
+	if utf8.RuneCountInString(text) >= longMessageRunes {
+		evidence[domain.ModeDeepDive] += factor
+		found = true
+	}
+	return found
+}
+
+// normalizeScores scales scores so they add up to 1.
+func normalizeScores(scores domain.ModeScores) domain.ModeScores {
+	var total float64
+	for _, v := range scores {
+		total += v
+	}
+	if total == 0 {
+		return scores
+	}
+
+	out := domain.ModeScores{}
+	for m, v := range scores {
+		out[m] = v / total
+	}
+	return out
+}
+
+// signal builds a Signal, panicking on invalid patterns (only used for the built-ins).
+func signal(name string, mode domain.InteractionMode, weight float64, pattern string) Signal {
+	return Signal{
+		Name:    name,
+		Mode:    mode,
+		Weight:  weight,
+		Pattern: regexp.MustCompile(pattern),
+	}
+}
+
+const feelings = `(bien|mal|triste|cansad[oa]|ansios[oa]|nervios[oa]|content[oa]|estresad[oa]|agotad[oa]|feliz|enojad[oa]|sol[oa]|ok|okay|fine|good|bad|sad|tired|anxious|nervous|stressed|lonely|happy|angry|overwhelmed|exhausted|bem|sozinh[oa]|estressad[oa])`
+
+// Words shared by several languages (e.g. "plan", "nunca") live in only one
+// signal, so they are not counted twice.
+var defaultSignals = []Signal{
+	// Action plan: the user asks what to do
+	signal("es_what_to_do", domain.ModeActionPlan, 2, `\bque (puedo|deberia|tengo que|hago para)( hacer)?\b`),
+	signal("es_how_to", domain.ModeActionPlan, 1.5, `\bcomo (hago|puedo|empiezo|organizo)\b`),
+	signal("es_plan_words", domain.ModeActionPlan, 1, `\b(plan|pasos|organizar(me)?|rutina|estrategias?|objetivos?|metas?|consejos?)\b`),
+	signal("es_help_me", domain.ModeActionPlan, 1, `\bayuda(me|rme) a\b`),
+	signal("en_what_to_do", domain.ModeActionPlan, 2, `\bwhat (can|should) i do\b`),
+	signal("en_how_to", domain.ModeActionPlan, 1.5, `\bhow (do|can|should) i\b`),
+	signal("en_plan_words", domain.ModeActionPlan, 1, `\b(steps?|routine|strateg(y|ies)|goals?|organi[sz]e|tips|advice)\b`),
+	signal("en_help_me", domain.ModeActionPlan, 1, `\bhelp me\b`),
+	signal("pt_what_to_do", domain.ModeActionPlan, 2, `\bo que (posso|devo) fazer\b`),
+	signal("pt_how_to", domain.ModeActionPlan, 1.5, `\bcomo (faco|posso|comeco)\b`),
+	signal("pt_plan_words", domain.ModeActionPlan, 1, `\b(plano|passos|rotina|metas?|conselhos?)\b`),
+
+	// Deep dive: the user wants to understand why, or talks about patterns
+	signal("es_why", domain.ModeDeepDive, 1.5, `\bpor que\b|\bno se por que\b|\bme pregunto\b`),
+	signal("es_patterns", domain.ModeDeepDive, 1, `\b(siempre|nunca|otra vez|de nuevo|cada vez que)\b`),
+	signal("es_understand", domain.ModeDeepDive, 1.5, `\b(entender(me)?|entiendo|patron(es)?|raiz|en el fondo)\b`),
+	signal("es_past", domain.ModeDeepDive, 1, `\b(infancia|de chic[oa]|mis padres|mi (madre|padre|mama|papa))\b`),
+	signal("en_why", domain.ModeDeepDive, 1.5, `\bwhy\b|\bi wonder\b`),
+	signal("en_patterns", domain.ModeDeepDive, 1, `\b(always|never|again|every time)\b`),
+	signal("en_understand", domain.ModeDeepDive, 1.5, `\b(understand|patterns?|root|deep(er)? down)\b`),
+	signal("en_past", domain.ModeDeepDive, 1, `\b(childhood|as a (kid|child)|my (parents|mother|father|mom|dad))\b`),
+	signal("pt_patterns", domain.ModeDeepDive, 1, `\b(sempre|de novo|toda vez que)\b`),
+	signal("pt_understand", domain.ModeDeepDive, 1.5, `\b(padrao|padroes|no fundo)\b`),
+
+	// Check-in: greetings and how the user feels right now
+	signal("greeting", domain.ModeCheckIn, 1, `^(hola|buen(os|as) (dias|tardes|noches)|hi|hello|hey|oi|ola)\b`),
+	signal("feeling", domain.ModeCheckIn, 1.5, `\b(me siento|estoy|ando|i feel|i'm feeling|i am feeling|feeling|i'm|i am|me sinto|estou)( (muy|un poco|re|so|very|a bit|pretty|muito|meio))? `+feelings+`\b`),
+	signal("today", domain.ModeCheckIn, 0.5, `\b(hoy|today|hoje)\b`),
+}
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ñ", "n", "ç", "c",
+	"’", "'",
+)
+
+// normalize lowercases the text and removes accents so patterns stay simple.
+func normalize(s string) string {
+	return accentReplacer.Replace(strings.ToLower(s))
+}
@@ -0,0 +1,103 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+const llmModeClassifierPrompt = `You are a conversation mode classifier for a mental well-being app. You do NOT talk to the user.
+Pick the interaction mode that fits the user's latest message best and return ONLY a JSON object (no markdown) like:
+
+{"mode": "check_in", "confidence": 0.8, "reason": "short reason"}
+
+- "check_in": the user shares how they feel right now or chats briefly.
+- "deep_dive": the user wants to explore or understand a feeling, a pattern or its causes.
+- "action_plan": the user asks what to do, for steps, tips or a plan.
+- "confidence" is between 0 and 1.
+
+Recent messages:
+%s
+
+Latest user message:
+%s`
+
+// LLMClassifier asks the LLM for the mode. It complements the heuristics
+// with intent expressed without the usual keywords.
+type LLMClassifier struct {
+	llm domain.LLMClient
+
+	historyMessages int
+}
+
+// NewLLMClassifier creates a mode classifier backed by an LLMClient.
+func NewLLMClassifier(llm domain.LLMClient) *LLMClassifier {
+	return &LLMClassifier{llm: llm, historyMessages: 4}
+}
+
+type llmModeClassification struct {
+	Mode       string   `json:"mode"`
+	Confidence *float64 `json:"confidence"`
+	Reason     string   `json:"reason"`
+}
+
+func (c *LLMClassifier) Classify(
+	ctx context.Context,
+	text string,
+	convCtx domain.ConversationContext,
+) (domain.ModeScores, error) {
+	history := convCtx.History
+	if len(history) > c.historyMessages {
+		history = history[len(history)-c.historyMessages:]
+	}
+	var lines []string
+	for _, m := range history {
+		lines = append(lines, fmt.Sprintf("%s: %s", m.Author, m.Text))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "(none)")
+	}
+
+	// The history is already in the prompt; keep the call cheap.
+	raw, err := c.llm.GenerateReply(ctx, fmt.Sprintf(llmModeClassifierPrompt, strings.Join(lines, "\n"), text), domain.ConversationContext{
+		SessionID: convCtx.SessionID,
+		UserID:    convCtx.UserID,
+		Mode:      convCtx.Mode,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("llm mode classifier: %w", err)
+	}
+
+	start := strings.Index(raw, "{")
+	end := strings.LastIndex(raw, "}")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("llm mode classifier: no JSON object in answer")
+	}
+
+	var res llmModeClassification
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &res); err != nil {
+		return nil, fmt.Errorf("llm mode classifier: invalid JSON: %w", err)
+	}
+
+	mode := domain.InteractionMode(strings.ToLower(strings.TrimSpace(res.Mode)))
+	if !mode.Valid() {
+		return nil, fmt.Errorf("llm mode classifier: unknown mode %q", res.Mode)
+	}
+
+	confidence := 0.7
+	if res.Confidence != nil {
+		confidence = min(max(*res.Confidence, 0), 1)
+	}
+
+	// The rest of the confidence is spread over the other modes
+	rest := (1 - confidence) / float64(len(domain.InteractionModes)-1)
+	scores := domain.ModeScores{}
+	for _, m := range domain.InteractionModes {
+		scores[m] = rest
+	}
+	scores[mode] = confidence
+	return scores, nil
+}
@@ -0,0 +1,160 @@
+// Package routing picks the interaction mode (check_in, deep_dive,
+// action_plan) of each message, so a session can change mode as the
+// conversation goes.
+package routing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// RouterConfig tunes the router. Zero values use the defaults.
+type RouterConfig struct {
+	// PreferredBias is added to the score of the mode the user chose for
+	// the session (default 0.2). It is a bias, not an override.
+	PreferredBias float64
+
+	// SwitchMargin is how much the best mode must beat the current one to
+	// switch (default 0.25). It keeps the mode from flapping between turns.
+	SwitchMargin float64
+}
+
+// ModeRouter merges the scores of its classifiers and decides the mode of
+// each message.
+type ModeRouter struct {
+	classifiers []domain.ModeClassifier
+	cfg         RouterConfig
+}
+
+// NewModeRouter creates a router. Classifiers run in order and their scores
+// are averaged.
+func NewModeRouter(cfg RouterConfig, classifiers ...domain.ModeClassifier) *ModeRouter {
+	if cfg.PreferredBias <= 0 {
+		cfg.PreferredBias = 0.2
+	}
+	if cfg.SwitchMargin <= 0 {
+		cfg.SwitchMargin = 0.25
+	}
+	return &ModeRouter{
+		classifiers: classifiers,
+		cfg:         cfg,
+	}
+}
+
+// NewDefaultModeRouter creates a router with only the heuristics.
+func NewDefaultModeRouter() *ModeRouter {
+	return NewModeRouter(RouterConfig{}, NewHeuristicClassifier())
+}
+
+// Route decides the mode of text. convCtx.History holds the recent messages
+// (without text); the current mode is the one of the last message, or
+// preferred at the start of a session. A failing classifier is logged and
+// ignored; if all of them fail the current mode is kept.
+func (r *ModeRouter) Route(
+	ctx context.Context,
+	text string,
+	convCtx domain.ConversationContext,
+	preferred domain.InteractionMode,
+) domain.ModeDecision {
+	log := observability.LoggerFromContext(ctx)
+
+	current := currentMode(convCtx.History)
+	if current == "" {
+		current = preferred
+	}
+	convCtx.Mode = current
+
+	merged := domain.ModeScores{}
+	var sources []string
+	for _, c := range r.classifiers {
+		scores, err := c.Classify(ctx, text, convCtx)
+		if err != nil {
+			log.Warn("mode classifier failed", "error", err)
+			continue
+		}
+
+		sources = append(sources, classifierSource(c))
+		for m, v := range scores {
+			merged[m] += v
+		}
+	}
+
+	decision := domain.ModeDecision{
+		Previous: current,
+		Source:   strings.Join(sources, "+"),
+	}
+
+	if len(sources) == 0 {
+		decision.Mode = current
+		if decision.Mode == "" {
+			decision.Mode = domain.ModeCheckIn
+		}
+		decision.Reason = "no classifier"
+		return decision
+	}
+
+	for m := range merged {
+		merged[m] /= float64(len(sources))
+	}
+	if preferred.Valid() {
+		merged[preferred] += r.cfg.PreferredBias
+	}
+	decision.Scores = merged
+
+	best := current
+	if !best.Valid() {
+		best = domain.ModeCheckIn
+	}
+	for _, m := range domain.InteractionModes {
+		if merged[m] > merged[best] {
+			best = m
+		}
+	}
+
+	switch {
+	case best == current:
+		decision.Mode = current
+		decision.Reason = "kept"
+	case !current.Valid():
+		decision.Mode = best
+		decision.Reason = "selected"
+	case merged[best]-merged[current] < r.cfg.SwitchMargin:
+		decision.Mode = current
+		decision.Reason = "kept (hysteresis)"
+	default:
+		decision.Mode = best
+		decision.Reason = "switched"
+	}
+
+	log.Info("mode routed",
+		"mode", decision.Mode,
+		"previous_mode", decision.Previous,
+		"reason", decision.Reason,
+		"source", decision.Source,
+	)
+	return decision
+}
+
+// currentMode is the mode of the last message that has one.
+func currentMode(history []*domain.Message) domain.InteractionMode {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Mode.Valid() {
+			return history[i].Mode
+		}
+	}
+	return ""
+}
+
+func classifierSource(c domain.ModeClassifier) string {
+	switch c.(type) {
+	case *HeuristicClassifier:
+		return "heuristics"
+	case *LLMClassifier:
+		return "llm"
+	default:
+		return "custom"
+	}
+}
@@ -0,0 +1,129 @@
+package routing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/app/routing"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// fixedLLM answers every prompt with the same text (or error).
+type fixedLLM struct {
+	reply string
+	err   error
+}
+
+func (f fixedLLM) GenerateReply(ctx context.Context, prompt string, convCtx domain.ConversationContext) (string, error) {
+	return f.reply, f.err
+}
+
+func best(scores domain.ModeScores) domain.InteractionMode {
+	out := domain.InteractionModes[0]
+	for _, m := range domain.InteractionModes {
+		if scores[m] > scores[out] {
+			out = m
+		}
+	}
+	return out
+}
+
+func TestHeuristicClassifier(t *testing.T) {
+	c := routing.NewHeuristicClassifier()
+
+	cases := []struct {
+		text string
+		mode domain.InteractionMode
+	}{
+		{"Hola, hoy me siento un poco cansada", domain.ModeCheckIn},
+		{"I'm feeling lonely today", domain.ModeCheckIn},
+		{"¿Qué puedo hacer para organizarme mejor con el estudio?", domain.ModeActionPlan},
+		{"What should I do? I need a routine", domain.ModeActionPlan},
+		{"No sé por qué siempre termino peleando con mis padres", domain.ModeDeepDive},
+		{"I want to understand why this keeps happening again", domain.ModeDeepDive},
+		{"Eu sempre sinto isso de novo, não sei o padrão", domain.ModeDeepDive},
+	}
+
+	for _, tc := range cases {
+		scores, err := c.Classify(context.Background(), tc.text, domain.ConversationContext{})
+		if err != nil {
+			t.Fatalf("Classify(%q) failed: %v", tc.text, err)
+		}
+		if got := best(scores); got != tc.mode {
+			t.Errorf("Classify(%q): expected %q, got %q (scores=%v)", tc.text, tc.mode, got, scores)
+		}
+	}
+}
+
+func TestModeRouter(t *testing.T) {
+	router := routing.NewDefaultModeRouter()
+	ctx := context.Background()
+	history := func(mode domain.InteractionMode) domain.ConversationContext {
+		return domain.ConversationContext{History: []*domain.Message{
+			{Author: domain.RoleAgent, Text: "Hola, soy Farum.", Mode: mode},
+		}}
+	}
+
+	// No preference and no mode yet: the best mode wins right away
+	d := router.Route(ctx, "¿Qué puedo hacer? Necesito un plan con pasos", domain.ConversationContext{}, "")
+	if d.Mode != domain.ModeActionPlan || d.Reason != "selected" || d.Changed() {
+		t.Fatalf("expected action_plan to be selected, got %+v", d)
+	}
+
+	// A weak signal does not beat the (preferred) current mode
+	d = router.Route(ctx, "Estoy triste", history(domain.ModeDeepDive), domain.ModeDeepDive)
+	if d.Mode != domain.ModeDeepDive || d.Reason != "kept (hysteresis)" {
+		t.Fatalf("expected deep_dive to be kept, got %+v", d)
+	}
+
+	// A clear request switches mid-session, even against the preference
+	d = router.Route(ctx, "¿Qué puedo hacer? Necesito un plan con pasos", history(domain.ModeCheckIn), domain.ModeCheckIn)
+	if d.Mode != domain.ModeActionPlan || !d.Changed() || d.Previous != domain.ModeCheckIn {
+		t.Fatalf("expected a switch to action_plan, got %+v", d)
+	}
+}
+
+func TestModeRouterMergesLLMClassifier(t *testing.T) {
+	ctx := context.Background()
+
+	router := routing.NewModeRouter(routing.RouterConfig{},
+		routing.NewHeuristicClassifier(),
+		routing.NewLLMClassifier(fixedLLM{reply: `{"mode": "deep_dive", "confidence": 0.95, "reason": "wants to explore"}`}),
+	)
+	d := router.Route(ctx, "Me pasó algo con mi jefe", domain.ConversationContext{}, "")
+	if d.Mode != domain.ModeDeepDive || d.Source != "heuristics+llm" {
+		t.Fatalf("expected the LLM to tip the balance to deep_dive, got %+v", d)
+	}
+
+	// A failing classifier is ignored
+	router = routing.NewModeRouter(routing.RouterConfig{},
+		routing.NewHeuristicClassifier(),
+		routing.NewLLMClassifier(fixedLLM{err: errors.New("boom")}),
+	)
+	d = router.Route(ctx, "I'm feeling sad today", domain.ConversationContext{}, "")
+	if d.Mode != domain.ModeCheckIn || d.Source != "heuristics" {
+		t.Fatalf("expected heuristics only, got %+v", d)
+	}
+
+	// An answer that is not a mode is an error
+	_, err := routing.NewLLMClassifier(fixedLLM{reply: `{"mode": "party"}`}).Classify(ctx, "hola", domain.ConversationContext{})
+	if err == nil {
+		t.Fatalf("expected an error for an unknown mode")
+	}
+}
+
+func TestHeuristicClassifierFollowUpKeepsTopic(t *testing.T) {
+	convCtx := domain.ConversationContext{History: []*domain.Message{
+		{Author: domain.RoleUser, Text: "¿Qué puedo hacer para ordenar mi semana? Quiero un plan"},
+		{Author: domain.RoleAgent, Text: "Podemos armarlo juntos. ¿Empezamos por el lunes?"},
+	}}
+
+	scores, err := routing.NewHeuristicClassifier().Classify(context.Background(), "Sí, dale", convCtx)
+	if err != nil {
+		t.Fatalf("Classify failed: %v", err)
+	}
+	if got := best(scores); got != domain.ModeActionPlan {
+		t.Fatalf("expected the follow-up to stay in action_plan, got %q (scores=%v)", got, scores)
+	}
+}
@@ -0,0 +1,156 @@
+package safety
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// CrisisResponses maps a language code ("es", "en", "pt") to the message sent
+// instead of the agents' reply when high risk is detected.
+type CrisisResponses map[string]string
+
+// DefaultCrisisResponses are used when no responses file is configured.
+var DefaultCrisisResponses = CrisisResponses{
+	"es": "Lo que me contás es muy importante y me preocupa cómo estás. No estás solo/a con esto.\n\n" +
+		"Si sentís que podés hacerte daño o estás en peligro ahora, llamá a los servicios de emergencia de tu zona " +
+		"(en Argentina: 911, o el Centro de Asistencia al Suicida al 135 / (011) 5275-1135; en España: 024; en México: 800 911 2000).\n\n" +
+		"Si podés, contale ahora a alguien de confianza cómo te sentís. Yo no puedo reemplazar la ayuda de un profesional, " +
+		"pero sigo acá para escucharte.",
+	"en": "What you're sharing is really important, and I'm worried about how you're doing. You don't have to go through this alone.\n\n" +
+		"If you feel you might hurt yourself or you're in danger right now, please contact your local emergency services " +
+		"(US: call or text 988; UK & ROI: Samaritans at 116 123), or find a helpline at https://findahelpline.com.\n\n" +
+		"If you can, reach out to someone you trust right now. I can't replace professional help, but I'm still here to listen.",
+	"pt": "O que você está contando é muito importante e estou preocupado com você. Você não precisa passar por isso sozinho(a).\n\n" +
+		"Se sentir que pode se machucar ou estiver em perigo agora, procure o serviço de emergência local " +
+		"(no Brasil: CVV 188 ou SAMU 192).\n\n" +
+		"Se puder, fale agora com alguém de confiança. Eu não substituo a ajuda profissional, mas continuo aqui para te ouvir.",
+}
+
+// LoadCrisisResponses reads a JSON file like {"es": "...", "en": "..."} and
+// merges it over the defaults.
+func LoadCrisisResponses(path string) (CrisisResponses, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading crisis responses: %w", err)
+	}
+
+	var custom CrisisResponses
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("decoding crisis responses: %w", err)
+	}
+
+	out := CrisisResponses{}
+	for lang, text := range DefaultCrisisResponses {
+		out[lang] = text
+	}
+	for lang, text := range custom {
+		out[lang] = text
+	}
+	return out, nil
+}
+
+// For returns the response for a language, falling back to Spanish
+// (Farum's primary language) and then to any configured response.
+func (r CrisisResponses) For(lang string) string {
+	if text, ok := r[lang]; ok {
+		return text
+	}
+	if text, ok := r["es"]; ok {
+		return text
+	}
+	for _, text := range r {
+		return text
+	}
+	return ""
+}
+
+// Gate runs the safety classifiers before the agents and decides whether
+// to short-circuit to the crisis response.
+type Gate struct {
+	classifiers []domain.SafetyClassifier
+	responses   CrisisResponses
+	threshold   domain.RiskLevel
+}
+
+// NewGate creates a gate that short-circuits on high risk.
+// Classifiers run in order; their assessments are merged (highest level wins).
+func NewGate(responses CrisisResponses, classifiers ...domain.SafetyClassifier) *Gate {
+	if responses == nil {
+		responses = DefaultCrisisResponses
+	}
+	return &Gate{
+		classifiers: classifiers,
+		responses:   responses,
+		threshold:   domain.RiskHigh,
+	}
+}
+
+// NewDefaultGate creates a gate with only the deterministic rules.
+func NewDefaultGate() *Gate {
+	return NewGate(nil, NewRuleClassifier())
+}
+
+// Check classifies the message with every classifier and merges the results.
+// A failing classifier is logged and ignored, so the rules always apply.
+func (g *Gate) Check(ctx context.Context, text string, convCtx domain.ConversationContext) domain.SafetyAssessment {
+	log := observability.LoggerFromContext(ctx)
+
+	merged := domain.SafetyAssessment{Level: domain.RiskNone}
+	var sources []string
+
+	for _, c := range g.classifiers {
+		a, err := c.Classify(ctx, text, convCtx)
+		if err != nil {
+			log.Warn("safety classifier failed", "error", err)
+			continue
+		}
+
+		sources = append(sources, a.Source)
+		merged.Matches = append(merged.Matches, a.Matches...)
+		for _, cat := range a.Categories {
+			merged.Categories = appendUnique(merged.Categories, cat)
+		}
+		if merged.Language == "" {
+			merged.Language = a.Language
+		}
+		if a.Level.Severity() > merged.Level.Severity() {
+			merged.Level = a.Level
+			if a.Language != "" {
+				merged.Language = a.Language
+			}
+		}
+	}
+
+	merged.Source = strings.Join(sources, "+")
+	return merged
+}
+
+// ShouldShortCircuit reports whether the agents must be skipped.
+func (g *Gate) ShouldShortCircuit(a domain.SafetyAssessment) bool {
+	return a.Level.Severity() >= g.threshold.Severity()
+}
+
+// CrisisResponse returns the localized crisis message.
+func (g *Gate) CrisisResponse(lang string) string {
+	return g.responses.For(lang)
+}
+
+// Tags returns the message tags for an assessment, e.g. ["safety:high", "safety:suicide"].
+// It returns nil when no risk was detected.
+func Tags(a domain.SafetyAssessment) []string {
+	if a.Level == domain.RiskNone || a.Level == "" {
+		return nil
+	}
+
+	tags := []string{"safety:" + string(a.Level)}
+	for _, c := range a.Categories {
+		tags = append(tags, "safety:"+c)
+	}
+	return tags
+}
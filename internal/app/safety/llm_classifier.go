@@ -0,0 +1,85 @@
+package safety
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+const llmClassifierPrompt = `You are a safety classifier for a mental well-being app. You do NOT talk to the user.
+Classify the risk in the user's message below and return ONLY a JSON object (no markdown) like:
+
+{"risk": "none", "categories": [], "reason": "short reason"}
+
+- "risk" is one of "none", "low", "medium", "high".
+  Use "high" for any intent, plan or wish of suicide, self-harm or harming others.
+  Use "medium" for hopelessness or severe distress without explicit intent.
+- "categories" can include "suicide", "self_harm", "harm_others", "distress".
+
+User message:
+%s`
+
+// LLMClassifier asks the LLM to classify risk. It complements the rules with
+// paraphrases and indirect language that regexes cannot catch.
+type LLMClassifier struct {
+	llm domain.LLMClient
+}
+
+// NewLLMClassifier creates a classifier backed by an LLMClient.
+func NewLLMClassifier(llm domain.LLMClient) *LLMClassifier {
+	return &LLMClassifier{llm: llm}
+}
+
+type llmClassification struct {
+	Risk       string   `json:"risk"`
+	Categories []string `json:"categories"`
+	Reason     string   `json:"reason"`
+}
+
+func (c *LLMClassifier) Classify(
+	ctx context.Context,
+	text string,
+	convCtx domain.ConversationContext,
+) (domain.SafetyAssessment, error) {
+	// Only the message matters here; no history to keep the call cheap.
+	raw, err := c.llm.GenerateReply(ctx, fmt.Sprintf(llmClassifierPrompt, text), domain.ConversationContext{
+		SessionID: convCtx.SessionID,
+		UserID:    convCtx.UserID,
+		Mode:      convCtx.Mode,
+	})
+	if err != nil {
+		return domain.SafetyAssessment{}, fmt.Errorf("llm safety classifier: %w", err)
+	}
+
+	start := strings.Index(raw, "{")
+	end := strings.LastIndex(raw, "}")
+	if start < 0 || end <= start {
+		return domain.SafetyAssessment{}, fmt.Errorf("llm safety classifier: no JSON object in answer")
+	}
+
+	var res llmClassification
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &res); err != nil {
+		return domain.SafetyAssessment{}, fmt.Errorf("llm safety classifier: invalid JSON: %w", err)
+	}
+
+	level := domain.RiskLevel(strings.ToLower(strings.TrimSpace(res.Risk)))
+	switch level {
+	case domain.RiskNone, domain.RiskLow, domain.RiskMedium, domain.RiskHigh:
+	default:
+		return domain.SafetyAssessment{}, fmt.Errorf("llm safety classifier: unknown risk %q", res.Risk)
+	}
+
+	out := domain.SafetyAssessment{
+		Level:      level,
+		Categories: res.Categories,
+		Language:   DetectLanguage(text),
+		Source:     "llm",
+	}
+	if res.Reason != "" {
+		out.Matches = []string{"llm: " + res.Reason}
+	}
+	return out, nil
+}
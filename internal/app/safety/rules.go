@@ -0,0 +1,155 @@
+package safety
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// Rule is a single deterministic pattern. Patterns are matched against the
+// normalized text (lowercase, without accents).
+type Rule struct {
+	Name     string
+	Language string
+	Category string
+	Level    domain.RiskLevel
+	Pattern  *regexp.Regexp
+}
+
+// RuleClassifier is a deterministic, multilingual keyword/regex classifier.
+// It never fails and never calls the network, so it always runs first.
+type RuleClassifier struct {
+	rules []Rule
+}
+
+// NewRuleClassifier creates a classifier with the built-in rules plus any extra ones.
+func NewRuleClassifier(extra ...Rule) *RuleClassifier {
+	rules := append([]Rule(nil), defaultRules...)
+	rules = append(rules, extra...)
+	return &RuleClassifier{rules: rules}
+}
+
+func (c *RuleClassifier) Classify(
+	ctx context.Context,
+	text string,
+	convCtx domain.ConversationContext,
+) (domain.SafetyAssessment, error) {
+	normalized := normalize(text)
+
+	out := domain.SafetyAssessment{
+		Level:  domain.RiskNone,
+		Source: "rules",
+	}
+
+	for _, r := range c.rules {
+		if !r.Pattern.MatchString(normalized) {
+			continue
+		}
+
+		out.Matches = append(out.Matches, r.Name)
+		out.Categories = appendUnique(out.Categories, r.Category)
+		if r.Level.Severity() > out.Level.Severity() {
+			out.Level = r.Level
+			out.Language = r.Language
+		}
+	}
+
+	if out.Language == "" {
+		out.Language = DetectLanguage(text)
+	}
+
+	return out, nil
+}
+
+// rule builds a Rule, panicking on invalid patterns (only used for the built-ins).
+func rule(name, lang, category string, level domain.RiskLevel, pattern string) Rule {
+	return Rule{
+		Name:     name,
+		Language: lang,
+		Category: category,
+		Level:    level,
+		Pattern:  regexp.MustCompile(pattern),
+	}
+}
+
+var defaultRules = []Rule{
+	// Spanish
+	rule("es_suicide", "es", "suicide", domain.RiskHigh, `\bsuicid(a|arme|arse|io)\b`),
+	rule("es_kill_myself", "es", "suicide", domain.RiskHigh, `\bmatarme\b|\bme (quiero|voy a) matar\b`),
+	rule("es_want_to_die", "es", "suicide", domain.RiskHigh, `\bquiero morir(me)?\b|\bme quiero morir\b|\bquisiera estar muert[oa]\b|\bmejor (estaria|estar) muert[oa]\b`),
+	rule("es_end_life", "es", "suicide", domain.RiskHigh, `\b(quitarme|terminar con|acabar con) (la|mi) vida\b|\bno quiero (seguir )?(vivir|viviendo)\b`),
+	rule("es_self_harm", "es", "self_harm", domain.RiskHigh, `\b(hacerme dano|lastimarme|cortarme|autolesion(arme|es)?)\b`),
+	rule("es_harm_others", "es", "harm_others", domain.RiskHigh, `\bvoy a matar(lo|la|los|las)?\b|\bquiero matar a\b`),
+	rule("es_distress", "es", "distress", domain.RiskMedium, `\bno (aguanto|doy) mas\b|\bno le encuentro sentido\b|\bsin salida\b|\bno tiene sentido (nada|vivir)\b`),
+
+	// English
+	rule("en_suicide", "en", "suicide", domain.RiskHigh, `\bsuicid(e|al)\b`),
+	rule("en_kill_myself", "en", "suicide", domain.RiskHigh, `\bkill(ing)? myself\b|\bend(ing)? my (own )?life\b|\btake my (own )?life\b`),
+	rule("en_want_to_die", "en", "suicide", domain.RiskHigh, `\b(want|wanna|going) to die\b|\bbetter off dead\b|\bdon'?t want to (live|be alive)\b`),
+	rule("en_self_harm", "en", "self_harm", domain.RiskHigh, `\b(hurt|cut|harm)(ing)? myself\b|\bself[- ]?harm\b`),
+	rule("en_harm_others", "en", "harm_others", domain.RiskHigh, `\b(going|want) to kill (him|her|them|someone|somebody)\b`),
+	rule("en_distress", "en", "distress", domain.RiskMedium, `\bcan'?t (go on|take it anymore)\b|\bhopeless\b|\bno way out\b`),
+
+	// Portuguese
+	rule("pt_suicide", "pt", "suicide", domain.RiskHigh, `\bsuicidio\b|\bme suicidar\b`),
+	rule("pt_kill_myself", "pt", "suicide", domain.RiskHigh, `\b(vou|quero) me matar\b|\btirar (a )?minha (propria )?vida\b`),
+	rule("pt_want_to_die", "pt", "suicide", domain.RiskHigh, `\bquero morrer\b|\bnao quero mais viver\b`),
+	rule("pt_self_harm", "pt", "self_harm", domain.RiskHigh, `\bme (machucar|cortar)\b|\bautomutila(cao|r)\b`),
+	rule("pt_distress", "pt", "distress", domain.RiskMedium, `\bnao aguento mais\b|\bsem saida\b`),
+}
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ñ", "n", "ç", "c",
+	"’", "'",
+)
+
+// normalize lowercases the text and removes accents so patterns stay simple.
+func normalize(s string) string {
+	return accentReplacer.Replace(strings.ToLower(s))
+}
+
+// DetectLanguage makes a cheap guess between "es", "en" and "pt" based on stopwords.
+// It returns "" when there is no signal.
+func DetectLanguage(text string) string {
+	markers := map[string][]string{
+		"es": {"que", "estoy", "quiero", "muy", "pero", "con", "mi", "tengo", "vos", "hoy", "siento"},
+		"en": {"i", "the", "and", "my", "i'm", "feel", "want", "to", "is", "with", "today"},
+		"pt": {"nao", "eu", "estou", "minha", "voce", "muito", "com", "tenho", "hoje", "sinto"},
+	}
+
+	counts := map[string]int{}
+	for _, w := range strings.Fields(normalize(text)) {
+		w = strings.Trim(w, ".,;:!?¿¡\"()")
+		for lang, ws := range markers {
+			for _, m := range ws {
+				if w == m {
+					counts[lang]++
+				}
+			}
+		}
+	}
+
+	best, bestCount := "", 0
+	for _, lang := range []string{"es", "en", "pt"} {
+		if counts[lang] > bestCount {
+			best, bestCount = lang, counts[lang]
+		}
+	}
+	return best
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, x := range list {
+		if x == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
@@ -0,0 +1,58 @@
+package safety_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/app/safety"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+func TestRuleClassifier(t *testing.T) {
+	c := safety.NewRuleClassifier()
+
+	cases := []struct {
+		text     string
+		level    domain.RiskLevel
+		language string
+	}{
+		{"Hoy estoy cansada pero bien", domain.RiskNone, "es"},
+		{"A veces pienso que quiero morirme", domain.RiskHigh, "es"},
+		{"Tengo ganas de lastimarme", domain.RiskHigh, "es"},
+		{"Ya no aguanto más", domain.RiskMedium, "es"},
+		{"I keep thinking about killing myself", domain.RiskHigh, "en"},
+		{"I feel hopeless lately", domain.RiskMedium, "en"},
+		{"Não quero mais viver", domain.RiskHigh, "pt"},
+		{"I had a long day at work", domain.RiskNone, "en"},
+	}
+
+	for _, tc := range cases {
+		got, err := c.Classify(context.Background(), tc.text, domain.ConversationContext{})
+		if err != nil {
+			t.Fatalf("Classify(%q) failed: %v", tc.text, err)
+		}
+		if got.Level != tc.level {
+			t.Errorf("Classify(%q): expected level %q, got %q (matches=%v)", tc.text, tc.level, got.Level, got.Matches)
+		}
+		if got.Language != tc.language {
+			t.Errorf("Classify(%q): expected language %q, got %q", tc.text, tc.language, got.Language)
+		}
+	}
+}
+
+func TestGateShortCircuitsOnHighRisk(t *testing.T) {
+	gate := safety.NewGate(safety.CrisisResponses{"en": "call 988"}, safety.NewRuleClassifier())
+
+	a := gate.Check(context.Background(), "I want to end my life", domain.ConversationContext{})
+	if !gate.ShouldShortCircuit(a) {
+		t.Fatalf("expected short-circuit for %+v", a)
+	}
+	if got := gate.CrisisResponse(a.Language); got != "call 988" {
+		t.Fatalf("expected localized crisis response, got %q", got)
+	}
+
+	tags := safety.Tags(a)
+	if len(tags) == 0 || tags[0] != "safety:high" {
+		t.Fatalf("expected safety:high tag, got %v", tags)
+	}
+}
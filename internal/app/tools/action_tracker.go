@@ -1 +1,147 @@
-package tools
\ No newline at end of file
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PabloGalante/farum-agent/internal/app/journal"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// ActionTracker lets agents follow up on the action plans stored in the journal:
+// list open actions, mark them done/skipped and read completion stats.
+type ActionTracker struct {
+	svc *journal.Service
+}
+
+// NewActionTracker creates a new ActionTracker on top of the journal service.
+func NewActionTracker(svc *journal.Service) *ActionTracker {
+	return &ActionTracker{svc: svc}
+}
+
+func (t *ActionTracker) Name() string {
+	return "action_tracker"
+}
+
+func (t *ActionTracker) Description() string {
+	return "Follows up on the user's action plans: list the open actions, " +
+		"mark an action as done/skipped/pending, or get completion stats."
+}
+
+func (t *ActionTracker) InputSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"operation": map[string]any{"type": "string", "enum": []any{"list_open", "update", "stats"}},
+			"action_id": map[string]any{"type": "string", "description": "Required for update"},
+			"status":    map[string]any{"type": "string", "enum": []any{"pending", "done", "skipped"}},
+			"notes":     map[string]any{"type": "string"},
+		},
+		"required": []any{"operation"},
+	}
+}
+
+func (t *ActionTracker) OutputSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"status":          map[string]any{"type": "string", "enum": []any{"ok"}},
+			"actions":         map[string]any{"type": "array", "items": map[string]any{"type": "object"}},
+			"entry_id":        map[string]any{"type": "string"},
+			"action_id":       map[string]any{"type": "string"},
+			"action_status":   map[string]any{"type": "string"},
+			"total":           map[string]any{"type": "integer"},
+			"pending":         map[string]any{"type": "integer"},
+			"done":            map[string]any{"type": "integer"},
+			"skipped":         map[string]any{"type": "integer"},
+			"completion_rate": map[string]any{"type": "number"},
+		},
+		"required": []any{"status"},
+	}
+}
+
+// Call expects an input with this shape:
+//
+//	{ "operation": "list_open" }
+//
+//	{
+//	  "operation": "update",
+//	  "action_id": "a-123-0",
+//	  "status": "done",            // "done", "skipped" or "pending"
+//	  "notes": "Lo hice el martes" // optional
+//	}
+//
+//	{ "operation": "stats" }
+//
+// UserID comes in ToolContext.
+func (t *ActionTracker) Call(
+	ctx context.Context,
+	tctx ToolContext,
+	input map[string]any,
+) (map[string]any, error) {
+
+	if tctx.UserID == "" {
+		return nil, fmt.Errorf("action_tracker: missing UserID in ToolContext")
+	}
+	userID := domain.UserID(tctx.UserID)
+
+	switch op := getString(input, "operation"); op {
+	case "list_open":
+		open, err := t.svc.ListOpenActions(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("action_tracker: list failed: %w", err)
+		}
+
+		actions := make([]any, 0, len(open))
+		for _, o := range open {
+			actions = append(actions, map[string]any{
+				"entry_id":    string(o.EntryID),
+				"action_id":   o.Action.ID,
+				"description": o.Action.Description,
+				"notes":       o.Action.Notes,
+				"created_at":  o.Action.CreatedAt,
+			})
+		}
+
+		return map[string]any{
+			"status":  "ok",
+			"actions": actions,
+		}, nil
+
+	case "update":
+		out, err := t.svc.UpdateAction(ctx, journal.UpdateActionInput{
+			UserID:   userID,
+			ActionID: getString(input, "action_id"),
+			Status:   domain.ActionStatus(getString(input, "status")),
+			Notes:    getString(input, "notes"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("action_tracker: update failed: %w", err)
+		}
+
+		return map[string]any{
+			"status":        "ok",
+			"entry_id":      string(out.EntryID),
+			"action_id":     out.Action.ID,
+			"action_status": string(out.Action.Status),
+		}, nil
+
+	case "stats":
+		stats, err := t.svc.GetActionStats(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("action_tracker: stats failed: %w", err)
+		}
+
+		return map[string]any{
+			"status":          "ok",
+			"total":           stats.Total,
+			"pending":         stats.Pending,
+			"done":            stats.Done,
+			"skipped":         stats.Skipped,
+			"completion_rate": stats.CompletionRate,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("action_tracker: unknown operation %q", op)
+	}
+}
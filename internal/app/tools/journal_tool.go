@@ -28,6 +28,53 @@ func (t *JournalTool) Name() string {
 	return "journal_store"
 }
 
+func (t *JournalTool) Description() string {
+	return "Saves a journal entry for this conversation: a short summary of the problem, " +
+		"the mood before and after, the final reflection and the steps of the action plan. " +
+		"Call it once, at the end of the conversation."
+}
+
+func (t *JournalTool) InputSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"problem_summary": map[string]any{"type": "string", "description": "One or two sentences, in the user's language"},
+			"reflection":      map[string]any{"type": "string", "description": "The closing reflection shared with the user"},
+			"mood_before":     map[string]any{"type": "string"},
+			"mood_after":      map[string]any{"type": "string"},
+			"actions": map[string]any{
+				"type":     "array",
+				"maxItems": 4,
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"description": map[string]any{"type": "string"},
+						"status":      map[string]any{"type": "string", "enum": []any{"pending", "done", "skipped"}},
+						"notes":       map[string]any{"type": "string"},
+					},
+					"required": []any{"description"},
+				},
+			},
+		},
+		"required": []any{"problem_summary"},
+	}
+}
+
+func (t *JournalTool) OutputSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"status":        map[string]any{"type": "string", "enum": []any{"ok"}},
+			"entry_id":      map[string]any{"type": "string"},
+			"session_id":    map[string]any{"type": "string"},
+			"user_id":       map[string]any{"type": "string"},
+			"created_at":    map[string]any{"type": "string"},
+			"actions_count": map[string]any{"type": "integer", "minimum": 0},
+		},
+		"required": []any{"status", "entry_id"},
+	}
+}
+
 // Call expects an input with this shape:
 //
 // {
@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+const maxKnowledgeResults = 5
+
+// KnowledgeSearchTool searches Farum's vetted knowledge base (CBT and
+// mindfulness exercises, psychoeducation).
+type KnowledgeSearchTool struct {
+	retriever domain.KnowledgeRetriever
+}
+
+// NewKnowledgeSearchTool creates a knowledge_search tool on top of retriever.
+func NewKnowledgeSearchTool(retriever domain.KnowledgeRetriever) *KnowledgeSearchTool {
+	return &KnowledgeSearchTool{retriever: retriever}
+}
+
+func (t *KnowledgeSearchTool) Name() string {
+	return "knowledge_search"
+}
+
+func (t *KnowledgeSearchTool) Description() string {
+	return "Searches Farum's vetted library of psychoeducation content and exercises " +
+		"(CBT, mindfulness, sleep, stress). Prefer it over general knowledge and cite the chunk id."
+}
+
+func (t *KnowledgeSearchTool) InputSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"query":       map[string]any{"type": "string", "minLength": 2},
+			"max_results": map[string]any{"type": "integer", "minimum": 1, "maximum": maxKnowledgeResults},
+		},
+		"required": []any{"query"},
+	}
+}
+
+func (t *KnowledgeSearchTool) OutputSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"status": map[string]any{"type": "string", "enum": []any{"ok"}},
+			"results": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"id":      map[string]any{"type": "string"},
+						"source":  map[string]any{"type": "string"},
+						"title":   map[string]any{"type": "string"},
+						"heading": map[string]any{"type": "string"},
+						"text":    map[string]any{"type": "string"},
+						"score":   map[string]any{"type": "number"},
+					},
+					"required": []any{"id", "source", "text"},
+				},
+			},
+		},
+		"required": []any{"status", "results"},
+	}
+}
+
+// Call expects an input with this shape:
+//
+//	{ "query": "ejercicio de respiración para la ansiedad", "max_results": 3 }
+func (t *KnowledgeSearchTool) Call(
+	ctx context.Context,
+	tctx ToolContext,
+	input map[string]any,
+) (map[string]any, error) {
+
+	query := strings.TrimSpace(getString(input, "query"))
+	if query == "" {
+		return nil, fmt.Errorf("knowledge_search: missing query")
+	}
+
+	k := 3
+	switch n := input["max_results"].(type) {
+	case float64: // decoded JSON
+		k = int(n)
+	case int:
+		k = n
+	}
+	k = max(1, min(k, maxKnowledgeResults))
+
+	hits, err := t.retriever.Search(ctx, query, k)
+	if err != nil {
+		return nil, fmt.Errorf("knowledge_search: %w", err)
+	}
+
+	results := make([]any, 0, len(hits))
+	for _, h := range hits {
+		results = append(results, map[string]any{
+			"id":      h.Chunk.ID,
+			"source":  h.Chunk.Source,
+			"title":   h.Chunk.Title,
+			"heading": h.Chunk.Heading,
+			"text":    h.Chunk.Text,
+			"score":   h.Score,
+		})
+	}
+
+	return map[string]any{
+		"status":  "ok",
+		"results": results,
+	}, nil
+}
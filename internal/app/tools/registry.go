@@ -0,0 +1,293 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// ErrUnknownTool is returned when calling a tool that was not registered.
+var ErrUnknownTool = errors.New("unknown tool")
+
+// ToolDefinition is what the registry knows about a tool: the data exported
+// to prompts and function calling, and the schemas calls are validated with.
+type ToolDefinition struct {
+	Name         string         `json:"name"`
+	Description  string         `json:"description,omitempty"`
+	InputSchema  map[string]any `json:"input_schema"`
+	OutputSchema map[string]any `json:"output_schema,omitempty"`
+}
+
+// ToolInvocation is the audit record of one ToolRegistry.Call.
+type ToolInvocation struct {
+	Tool      string
+	RequestID string
+	UserID    string
+	SessionID string
+	StartedAt time.Time
+	Duration  time.Duration
+	Outcome   string // one of the observability.ToolOutcome* values
+	Err       error
+}
+
+// ToolRegistry holds the tools agents may offer to the model, in registration
+// order. Every call is validated against the tool's schemas and audited.
+type ToolRegistry struct {
+	tools []registeredTool
+	index map[string]int
+
+	mu      sync.RWMutex
+	onCall  []func(ToolInvocation)
+	nowFunc func() time.Time
+}
+
+type registeredTool struct {
+	tool Tool
+	def  ToolDefinition
+}
+
+// NewToolRegistry creates a registry with the given tools (nil tools are skipped).
+func NewToolRegistry(tools ...Tool) (*ToolRegistry, error) {
+	r := &ToolRegistry{
+		index:   make(map[string]int),
+		nowFunc: time.Now,
+	}
+	for _, t := range tools {
+		if t == nil {
+			continue
+		}
+		if err := r.Register(t); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds a tool. Names must be unique. The schemas come from
+// DescribedTool; other tools accept any object and their output is not checked.
+func (r *ToolRegistry) Register(t Tool) error {
+	def := ToolDefinition{
+		Name:        t.Name(),
+		InputSchema: map[string]any{"type": "object"},
+	}
+	if d, ok := t.(DescribedTool); ok {
+		def.Description = d.Description()
+		def.InputSchema = d.InputSchema()
+		def.OutputSchema = d.OutputSchema()
+	}
+	return r.RegisterDefinition(t, def)
+}
+
+// RegisterDefinition adds a tool with explicit schemas (def.Name must match
+// the tool's name).
+func (r *ToolRegistry) RegisterDefinition(t Tool, def ToolDefinition) error {
+	if def.Name != t.Name() {
+		return fmt.Errorf("tool %q registered as %q", t.Name(), def.Name)
+	}
+	if _, dup := r.index[def.Name]; dup {
+		return fmt.Errorf("tool %q already registered", def.Name)
+	}
+	if def.InputSchema == nil {
+		def.InputSchema = map[string]any{"type": "object"}
+	}
+
+	r.index[def.Name] = len(r.tools)
+	r.tools = append(r.tools, registeredTool{tool: t, def: def})
+	return nil
+}
+
+// Only returns a registry with the named tools that are registered here
+// (e.g. to offer an agent a subset). Invocation hooks are shared.
+func (r *ToolRegistry) Only(names ...string) *ToolRegistry {
+	sub := &ToolRegistry{
+		index:   make(map[string]int),
+		nowFunc: time.Now,
+	}
+	if r == nil {
+		return sub
+	}
+
+	sub.nowFunc = r.nowFunc
+	for _, name := range names {
+		if i, ok := r.index[name]; ok {
+			if _, dup := sub.index[name]; !dup {
+				sub.index[name] = len(sub.tools)
+				sub.tools = append(sub.tools, r.tools[i])
+			}
+		}
+	}
+
+	r.mu.RLock()
+	sub.onCall = append([]func(ToolInvocation){}, r.onCall...)
+	r.mu.RUnlock()
+	return sub
+}
+
+// OnInvocation adds a function called after every Call (e.g. an audit log
+// or a test spy). It must not block.
+func (r *ToolRegistry) OnInvocation(fn func(ToolInvocation)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onCall = append(r.onCall, fn)
+}
+
+// Get returns a registered tool by name.
+func (r *ToolRegistry) Get(name string) (Tool, bool) {
+	if r == nil {
+		return nil, false
+	}
+	i, ok := r.index[name]
+	if !ok {
+		return nil, false
+	}
+	return r.tools[i].tool, true
+}
+
+// Len returns the number of registered tools.
+func (r *ToolRegistry) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.tools)
+}
+
+// List returns the definitions of the registered tools, in registration order.
+func (r *ToolRegistry) List() []ToolDefinition {
+	if r == nil {
+		return nil
+	}
+
+	defs := make([]ToolDefinition, 0, len(r.tools))
+	for _, rt := range r.tools {
+		defs = append(defs, rt.def)
+	}
+	return defs
+}
+
+// Specs returns the declarations sent to the model for function calling.
+func (r *ToolRegistry) Specs() []domain.ToolSpec {
+	defs := r.List()
+	if defs == nil {
+		return nil
+	}
+
+	specs := make([]domain.ToolSpec, 0, len(defs))
+	for _, d := range defs {
+		specs = append(specs, domain.ToolSpec{
+			Name:        d.Name,
+			Description: d.Description,
+			Parameters:  d.InputSchema,
+		})
+	}
+	return specs
+}
+
+// Describe renders the tools as a prompt section, for models without
+// native function calling:
+//
+//   - journal_store(problem_summary*, reflection, ...): Saves a journal entry...
+func (r *ToolRegistry) Describe() string {
+	var b strings.Builder
+	for _, d := range r.List() {
+		props, _ := d.InputSchema["properties"].(map[string]any)
+		required := map[string]bool{}
+		for _, name := range schemaStrings(d.InputSchema["required"]) {
+			required[name] = true
+		}
+
+		names := make([]string, 0, len(props))
+		for name := range props {
+			if required[name] {
+				name += "*"
+			}
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintf(&b, "- %s(%s)", d.Name, strings.Join(names, ", "))
+		if d.Description != "" {
+			b.WriteString(": " + d.Description)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Call validates input, runs a registered tool and validates its output.
+// Schema mismatches are returned as *ValidationError.
+func (r *ToolRegistry) Call(ctx context.Context, tctx ToolContext, name string, input map[string]any) (map[string]any, error) {
+	if r == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTool, name)
+	}
+
+	start := r.nowFunc()
+	out, outcome, err := r.call(ctx, tctx, name, input)
+	r.record(ctx, ToolInvocation{
+		Tool:      name,
+		RequestID: tctx.RequestID,
+		UserID:    tctx.UserID,
+		SessionID: tctx.SessionID,
+		StartedAt: start,
+		Duration:  r.nowFunc().Sub(start),
+		Outcome:   outcome,
+		Err:       err,
+	})
+	return out, err
+}
+
+func (r *ToolRegistry) call(ctx context.Context, tctx ToolContext, name string, input map[string]any) (map[string]any, string, error) {
+	i, ok := r.index[name]
+	if !ok {
+		return nil, observability.ToolOutcomeUnknown, fmt.Errorf("%w: %q", ErrUnknownTool, name)
+	}
+	rt := r.tools[i]
+
+	if input == nil {
+		input = map[string]any{}
+	}
+	if issues := ValidateSchema(rt.def.InputSchema, input); len(issues) > 0 {
+		return nil, observability.ToolOutcomeInvalidInput, &ValidationError{Tool: name, Stage: "input", Issues: issues}
+	}
+
+	out, err := rt.tool.Call(ctx, tctx, input)
+	if err != nil {
+		return nil, observability.ToolOutcomeError, err
+	}
+
+	if issues := ValidateSchema(rt.def.OutputSchema, out); len(issues) > 0 {
+		return nil, observability.ToolOutcomeInvalidOutput, &ValidationError{Tool: name, Stage: "output", Issues: issues}
+	}
+	return out, observability.ToolOutcomeOK, nil
+}
+
+func (r *ToolRegistry) record(ctx context.Context, inv ToolInvocation) {
+	observability.RecordToolCall(inv.Tool, inv.Outcome, inv.Duration)
+
+	log := observability.LoggerFromContext(ctx).With(
+		"tool", inv.Tool,
+		"request_id", inv.RequestID,
+		"user_id", inv.UserID,
+		"session_id", inv.SessionID,
+		"outcome", inv.Outcome,
+		"duration_ms", inv.Duration.Milliseconds(),
+	)
+	if inv.Err != nil {
+		log.Warn("tool invocation failed", "error", inv.Err)
+	} else {
+		log.Info("tool invocation")
+	}
+
+	r.mu.RLock()
+	hooks := r.onCall
+	r.mu.RUnlock()
+	for _, fn := range hooks {
+		fn(inv)
+	}
+}
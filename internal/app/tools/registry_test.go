@@ -0,0 +1,142 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/storage/memory"
+	"github.com/PabloGalante/farum-agent/internal/app/tools"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// stubTool returns out (or err) and counts its calls.
+type stubTool struct {
+	name  string
+	out   map[string]any
+	err   error
+	calls int
+}
+
+func (s *stubTool) Name() string { return s.name }
+
+func (s *stubTool) Call(ctx context.Context, tctx tools.ToolContext, input map[string]any) (map[string]any, error) {
+	s.calls++
+	return s.out, s.err
+}
+
+func TestToolRegistryValidatesInput(t *testing.T) {
+	store := memory.NewJournalStore()
+	registry, err := tools.NewToolRegistry(tools.NewJournalTool(store))
+	if err != nil {
+		t.Fatalf("NewToolRegistry: %v", err)
+	}
+
+	tctx := tools.ToolContext{UserID: "u1", SessionID: "s1", RequestID: "req-1"}
+	_, err = registry.Call(context.Background(), tctx, "journal_store", map[string]any{
+		"mood_before": 3,
+		"actions": []any{
+			map[string]any{"description": "Caminar", "status": "maybe"},
+			map[string]any{"status": "done"},
+		},
+	})
+
+	var verr *tools.ValidationError
+	if !errors.As(err, &verr) || verr.Tool != "journal_store" || verr.Stage != "input" {
+		t.Fatalf("expected an input *ValidationError, got %v", err)
+	}
+
+	got := map[string]bool{}
+	for _, is := range verr.Issues {
+		got[is.Path] = true
+	}
+	for _, path := range []string{"$.problem_summary", "$.mood_before", "$.actions[0].status", "$.actions[1].description"} {
+		if !got[path] {
+			t.Fatalf("expected an issue at %s, got %+v", path, verr.Issues)
+		}
+	}
+
+	if entries, _ := store.ListJournalEntriesByUser("u1", 0); len(entries) != 0 {
+		t.Fatalf("expected the tool not to run on invalid input, got %d entries", len(entries))
+	}
+	if observability.ToolCallCount("journal_store", observability.ToolOutcomeInvalidInput) == 0 {
+		t.Fatalf("expected the invalid call to be recorded in metrics")
+	}
+}
+
+func TestToolRegistryValidatesOutput(t *testing.T) {
+	stub := &stubTool{name: "counter", out: map[string]any{"count": "many"}}
+	registry, _ := tools.NewToolRegistry()
+	err := registry.RegisterDefinition(stub, tools.ToolDefinition{
+		Name:         "counter",
+		OutputSchema: map[string]any{"type": "object", "properties": map[string]any{"count": map[string]any{"type": "integer"}}},
+	})
+	if err != nil {
+		t.Fatalf("RegisterDefinition: %v", err)
+	}
+
+	_, err = registry.Call(context.Background(), tools.ToolContext{}, "counter", nil)
+
+	var verr *tools.ValidationError
+	if !errors.As(err, &verr) || verr.Stage != "output" || stub.calls != 1 {
+		t.Fatalf("expected an output *ValidationError after one call, got %v (%d calls)", err, stub.calls)
+	}
+}
+
+func TestToolRegistryAuditsInvocations(t *testing.T) {
+	boom := errors.New("boom")
+	registry, err := tools.NewToolRegistry(
+		&stubTool{name: "ok", out: map[string]any{"status": "ok"}},
+		&stubTool{name: "failing", err: boom},
+	)
+	if err != nil {
+		t.Fatalf("NewToolRegistry: %v", err)
+	}
+
+	var audit []tools.ToolInvocation
+	registry.OnInvocation(func(inv tools.ToolInvocation) { audit = append(audit, inv) })
+
+	tctx := tools.ToolContext{UserID: "u1", SessionID: "s1", RequestID: "req-42"}
+	ctx := context.Background()
+	_, _ = registry.Call(ctx, tctx, "ok", nil)
+	_, _ = registry.Call(ctx, tctx, "failing", nil)
+	_, err = registry.Call(ctx, tctx, "missing", nil)
+	if !errors.Is(err, tools.ErrUnknownTool) {
+		t.Fatalf("expected ErrUnknownTool, got %v", err)
+	}
+
+	if len(audit) != 3 {
+		t.Fatalf("expected 3 audited invocations, got %d", len(audit))
+	}
+	want := []string{observability.ToolOutcomeOK, observability.ToolOutcomeError, observability.ToolOutcomeUnknown}
+	for i, inv := range audit {
+		if inv.RequestID != "req-42" || inv.Outcome != want[i] || inv.Duration < 0 {
+			t.Fatalf("unexpected invocation %d: %+v", i, inv)
+		}
+	}
+	if !errors.Is(audit[1].Err, boom) {
+		t.Fatalf("expected the tool error in the audit record, got %v", audit[1].Err)
+	}
+}
+
+func TestToolRegistryListAndDescribe(t *testing.T) {
+	registry, err := tools.NewToolRegistry(tools.NewJournalTool(memory.NewJournalStore()), &stubTool{name: "plain"})
+	if err != nil {
+		t.Fatalf("NewToolRegistry: %v", err)
+	}
+	if _, err := tools.NewToolRegistry(&stubTool{name: "dup"}, &stubTool{name: "dup"}); err == nil {
+		t.Fatalf("expected an error for duplicate tool names")
+	}
+
+	defs := registry.List()
+	if len(defs) != 2 || defs[0].Name != "journal_store" || defs[0].OutputSchema == nil || defs[1].InputSchema["type"] != "object" {
+		t.Fatalf("unexpected definitions: %+v", defs)
+	}
+
+	desc := registry.Describe()
+	if !strings.Contains(desc, "- journal_store(actions, mood_after, mood_before, problem_summary*, reflection): Saves") ||
+		!strings.Contains(desc, "- plain()") {
+		t.Fatalf("unexpected description:\n%s", desc)
+	}
+}
@@ -0,0 +1,243 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// SchemaIssue is one place where a value does not match its schema.
+type SchemaIssue struct {
+	Path    string `json:"path"` // e.g. "$.actions[0].status"
+	Message string `json:"message"`
+}
+
+// ValidationError is returned by ToolRegistry.Call when the input (or the
+// output) of a tool does not match its JSON schema.
+type ValidationError struct {
+	Tool   string        `json:"tool"`
+	Stage  string        `json:"stage"` // "input" or "output"
+	Issues []SchemaIssue `json:"issues"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Issues))
+	for _, is := range e.Issues {
+		msgs = append(msgs, is.Path+": "+is.Message)
+	}
+	return fmt.Sprintf("%s: invalid %s: %s", e.Tool, e.Stage, strings.Join(msgs, "; "))
+}
+
+// ValidateSchema checks value against a JSON schema and returns every issue
+// found (nil if it matches). value is compared in its JSON form, so Go
+// values such as time.Time or int are accepted where JSON would be.
+//
+// Only the subset of JSON schema used by our tools is supported: type,
+// properties, required, additionalProperties (boolean), items, enum,
+// minLength/maxLength, minimum/maximum and minItems/maxItems.
+func ValidateSchema(schema map[string]any, value any) []SchemaIssue {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	normalized, err := toJSONValue(value)
+	if err != nil {
+		return []SchemaIssue{{Path: "$", Message: fmt.Sprintf("not JSON encodable: %v", err)}}
+	}
+
+	var issues []SchemaIssue
+	validateValue(schema, normalized, "$", &issues)
+	return issues
+}
+
+func toJSONValue(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func validateValue(schema map[string]any, value any, path string, issues *[]SchemaIssue) {
+	add := func(format string, args ...any) {
+		*issues = append(*issues, SchemaIssue{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if hasJSONType(value, t) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			add("expected %s, got %s", strings.Join(types, " or "), jsonTypeOf(value))
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if enumEqual(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			add("must be one of %s", formatEnum(enum))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		n := len([]rune(v))
+		if min, ok := schemaNumber(schema["minLength"]); ok && float64(n) < min {
+			add("must have at least %v characters", min)
+		}
+		if max, ok := schemaNumber(schema["maxLength"]); ok && float64(n) > max {
+			add("must have at most %v characters", max)
+		}
+
+	case float64:
+		if min, ok := schemaNumber(schema["minimum"]); ok && v < min {
+			add("must be >= %v", min)
+		}
+		if max, ok := schemaNumber(schema["maximum"]); ok && v > max {
+			add("must be <= %v", max)
+		}
+
+	case []any:
+		if min, ok := schemaNumber(schema["minItems"]); ok && float64(len(v)) < min {
+			add("must have at least %v items", min)
+		}
+		if max, ok := schemaNumber(schema["maxItems"]); ok && float64(len(v)) > max {
+			add("must have at most %v items", max)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				validateValue(items, item, fmt.Sprintf("%s[%d]", path, i), issues)
+			}
+		}
+
+	case map[string]any:
+		for _, name := range schemaStrings(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				*issues = append(*issues, SchemaIssue{Path: path + "." + name, Message: "is required"})
+			}
+		}
+
+		props, _ := schema["properties"].(map[string]any)
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			propSchema, known := props[k].(map[string]any)
+			if !known {
+				if extra, ok := schema["additionalProperties"].(bool); ok && !extra {
+					*issues = append(*issues, SchemaIssue{Path: path + "." + k, Message: "is not allowed"})
+				}
+				continue
+			}
+			validateValue(propSchema, v[k], path+"."+k, issues)
+		}
+	}
+}
+
+func hasJSONType(value any, t string) bool {
+	switch t {
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	default:
+		return jsonTypeOf(value) == t
+	}
+}
+
+func jsonTypeOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// schemaTypes accepts "type": "string" and "type": ["string", "null"].
+func schemaTypes(raw any) []string {
+	if s, ok := raw.(string); ok {
+		return []string{s}
+	}
+	return schemaStrings(raw)
+}
+
+func schemaStrings(raw any) []string {
+	switch list := raw.(type) {
+	case []string:
+		return list
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func schemaNumber(raw any) (float64, bool) {
+	switch n := raw.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// enumEqual compares the JSON encodings, so 1 and 1.0 or []string and
+// []any match as they would in JSON.
+func enumEqual(want, got any) bool {
+	w, err := json.Marshal(want)
+	if err != nil {
+		return false
+	}
+	g, err := json.Marshal(got)
+	return err == nil && string(w) == string(g)
+}
+
+func formatEnum(enum []any) string {
+	parts := make([]string, 0, len(enum))
+	for _, e := range enum {
+		data, _ := json.Marshal(e)
+		parts = append(parts, string(data))
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -17,3 +17,13 @@ type Tool interface {
 	Name() string
 	Call(ctx context.Context, tctx ToolContext, input map[string]any) (map[string]any, error)
 }
+
+// DescribedTool is a Tool that can be offered to the model for function
+// calling: it explains what it does and the JSON schemas of its input and
+// output. ToolRegistry validates both (a nil OutputSchema is not checked).
+type DescribedTool interface {
+	Tool
+	Description() string
+	InputSchema() map[string]any
+	OutputSchema() map[string]any
+}
@@ -1 +1,192 @@
-package tools
\ No newline at end of file
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/domain"
+)
+
+// WebSearchConfig tunes what WebSearchTool returns to the model.
+type WebSearchConfig struct {
+	// AllowedDomains restricts results to these domains and their
+	// subdomains (e.g. "who.int" also allows "www.who.int"). Empty allows any.
+	AllowedDomains []string
+
+	MaxResults int // results per call (default 3, the model may ask for up to 5)
+	MaxSnippet int // characters per snippet (default 300)
+}
+
+// WebSearchTool lets agents look up resources (helplines, psychoeducation
+// pages) through a domain.SearchBackend, keeping only allowed domains.
+type WebSearchTool struct {
+	backend    domain.SearchBackend
+	allowed    []string
+	maxResults int
+	maxSnippet int
+}
+
+const (
+	maxWebSearchResults = 5
+	maxWebSearchTitle   = 120
+)
+
+// NewWebSearchTool creates a web_search tool on top of backend.
+func NewWebSearchTool(backend domain.SearchBackend, cfg WebSearchConfig) *WebSearchTool {
+	if cfg.MaxResults <= 0 {
+		cfg.MaxResults = 3
+	}
+	if cfg.MaxResults > maxWebSearchResults {
+		cfg.MaxResults = maxWebSearchResults
+	}
+	if cfg.MaxSnippet <= 0 {
+		cfg.MaxSnippet = 300
+	}
+
+	var allowed []string
+	for _, d := range cfg.AllowedDomains {
+		if d = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), "."); d != "" {
+			allowed = append(allowed, d)
+		}
+	}
+
+	return &WebSearchTool{
+		backend:    backend,
+		allowed:    allowed,
+		maxResults: cfg.MaxResults,
+		maxSnippet: cfg.MaxSnippet,
+	}
+}
+
+func (t *WebSearchTool) Name() string {
+	return "web_search"
+}
+
+func (t *WebSearchTool) Description() string {
+	return "Searches reputable sources for resources the user can use: helplines, " +
+		"psychoeducation pages, local services. Returns titles, URLs and short snippets."
+}
+
+func (t *WebSearchTool) InputSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"query":       map[string]any{"type": "string", "minLength": 2, "maxLength": 200},
+			"max_results": map[string]any{"type": "integer", "minimum": 1, "maximum": maxWebSearchResults},
+		},
+		"required": []any{"query"},
+	}
+}
+
+func (t *WebSearchTool) OutputSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"status": map[string]any{"type": "string", "enum": []any{"ok"}},
+			"results": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"title":   map[string]any{"type": "string"},
+						"url":     map[string]any{"type": "string"},
+						"snippet": map[string]any{"type": "string"},
+					},
+					"required": []any{"title", "url"},
+				},
+			},
+		},
+		"required": []any{"status", "results"},
+	}
+}
+
+// Call expects an input with this shape:
+//
+//	{ "query": "línea de prevención del suicidio Argentina", "max_results": 3 }
+func (t *WebSearchTool) Call(
+	ctx context.Context,
+	tctx ToolContext,
+	input map[string]any,
+) (map[string]any, error) {
+
+	query := strings.TrimSpace(getString(input, "query"))
+	if query == "" {
+		return nil, fmt.Errorf("web_search: missing query")
+	}
+
+	limit := t.maxResults
+	switch n := input["max_results"].(type) {
+	case float64: // decoded JSON
+		limit = int(n)
+	case int:
+		limit = n
+	}
+	limit = max(1, min(limit, maxWebSearchResults))
+
+	// Ask for more than needed: the allow-list may drop some of them
+	fetch := limit
+	if len(t.allowed) > 0 {
+		fetch = limit * 4
+	}
+	found, err := t.backend.Search(ctx, query, fetch)
+	if err != nil {
+		return nil, fmt.Errorf("web_search: %s: %w", t.backend.Name(), err)
+	}
+
+	results := make([]any, 0, limit)
+	for _, r := range found {
+		if len(results) == limit {
+			break
+		}
+		if !t.isAllowed(r.URL) {
+			continue
+		}
+		results = append(results, map[string]any{
+			"title":   truncateText(r.Title, maxWebSearchTitle),
+			"url":     r.URL,
+			"snippet": truncateText(r.Snippet, t.maxSnippet),
+		})
+	}
+
+	return map[string]any{
+		"status":  "ok",
+		"results": results,
+	}, nil
+}
+
+// isAllowed reports whether rawURL is an http(s) URL on an allowed domain.
+func (t *WebSearchTool) isAllowed(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return false
+	}
+	if len(t.allowed) == 0 {
+		return true
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, d := range t.allowed {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateText collapses whitespace and cuts s to at most max runes
+// (ellipsis included), on a word boundary when possible.
+func truncateText(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+
+	cut := string(runes[:max-1])
+	if i := strings.LastIndex(cut, " "); i > max/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
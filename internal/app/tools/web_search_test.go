@@ -0,0 +1,91 @@
+package tools_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/search"
+	"github.com/PabloGalante/farum-agent/internal/app/tools"
+)
+
+// newFakeSearXNG answers every query with the same results.
+func newFakeSearXNG(t *testing.T, queries *[]string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*queries = append(*queries, r.URL.Query().Get("q"))
+		fmt.Fprintf(w, `{"results": [
+			{"title": "Foro anónimo", "url": "https://random-forum.example/thread/1", "content": "Opiniones"},
+			{"title": "Salud mental", "url": "https://www.who.int/es/health-topics/mental-health", "content": %q},
+			{"title": "Línea de ayuda", "url": "https://www.argentina.gob.ar/salud/mental", "content": "Atención gratuita"},
+			{"title": "Evil", "url": "javascript:alert(1)", "content": "x"}
+		]}`, strings.Repeat("La salud mental es un estado de bienestar. ", 20))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWebSearchToolAllowListAndTruncation(t *testing.T) {
+	var queries []string
+	srv := newFakeSearXNG(t, &queries)
+
+	backend, err := search.NewSearXNGBackend(search.SearXNGConfig{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewSearXNGBackend: %v", err)
+	}
+	tool := tools.NewWebSearchTool(backend, tools.WebSearchConfig{
+		AllowedDomains: []string{"who.int", "argentina.gob.ar"},
+		MaxSnippet:     80,
+	})
+
+	registry, err := tools.NewToolRegistry(tool)
+	if err != nil {
+		t.Fatalf("NewToolRegistry: %v", err)
+	}
+	out, err := registry.Call(context.Background(), tools.ToolContext{RequestID: "req-1"}, "web_search",
+		map[string]any{"query": "salud mental ayuda", "max_results": float64(5)})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+
+	if len(queries) != 1 || queries[0] != "salud mental ayuda" {
+		t.Fatalf("unexpected queries sent to the backend: %q", queries)
+	}
+
+	results := out["results"].([]any)
+	if len(results) != 2 {
+		t.Fatalf("expected the 2 allowed results, got %+v", results)
+	}
+	first := results[0].(map[string]any)
+	if first["url"] != "https://www.who.int/es/health-topics/mental-health" {
+		t.Fatalf("unexpected first result: %+v", first)
+	}
+	snippet := first["snippet"].(string)
+	if utf8.RuneCountInString(snippet) > 80 || !strings.HasSuffix(snippet, "…") {
+		t.Fatalf("expected a snippet truncated to 80 runes, got %d: %q", utf8.RuneCountInString(snippet), snippet)
+	}
+}
+
+func TestWebSearchToolWithoutAllowList(t *testing.T) {
+	var queries []string
+	srv := newFakeSearXNG(t, &queries)
+
+	backend, _ := search.NewSearXNGBackend(search.SearXNGConfig{BaseURL: srv.URL})
+	tool := tools.NewWebSearchTool(backend, tools.WebSearchConfig{MaxResults: 2})
+
+	out, err := tool.Call(context.Background(), tools.ToolContext{}, map[string]any{"query": "ansiedad"})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+
+	// Any http(s) domain is fine, but never other schemes
+	results := out["results"].([]any)
+	if len(results) != 2 || results[0].(map[string]any)["url"] != "https://random-forum.example/thread/1" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
@@ -0,0 +1,429 @@
+// Package bootstrap wires the adapters (LLM, storage, safety) and the
+// application services from config.Config. It is shared by every binary under
+// cmd/ so they all run the same stack.
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PabloGalante/farum-agent/internal/adapters/embedding"
+	llmadapter "github.com/PabloGalante/farum-agent/internal/adapters/llm"
+	"github.com/PabloGalante/farum-agent/internal/adapters/search"
+	firestorestore "github.com/PabloGalante/farum-agent/internal/adapters/storage/firestore"
+	memstore "github.com/PabloGalante/farum-agent/internal/adapters/storage/memory"
+	"github.com/PabloGalante/farum-agent/internal/app/agentflow"
+	"github.com/PabloGalante/farum-agent/internal/app/contextwindow"
+	"github.com/PabloGalante/farum-agent/internal/app/conversation"
+	journalapp "github.com/PabloGalante/farum-agent/internal/app/journal"
+	"github.com/PabloGalante/farum-agent/internal/app/knowledge"
+	"github.com/PabloGalante/farum-agent/internal/app/memories"
+	"github.com/PabloGalante/farum-agent/internal/app/routing"
+	"github.com/PabloGalante/farum-agent/internal/app/safety"
+	"github.com/PabloGalante/farum-agent/internal/app/tools"
+	"github.com/PabloGalante/farum-agent/internal/config"
+	"github.com/PabloGalante/farum-agent/internal/domain"
+	"github.com/PabloGalante/farum-agent/internal/observability"
+)
+
+// Stores groups every persistence port used by the application.
+type Stores struct {
+	Sessions     domain.SessionStore
+	Messages     domain.MessageStore
+	Journal      domain.JournalStore
+	SafetyEvents domain.SafetyEventStore
+}
+
+// Services groups the application services built over the adapters.
+type Services struct {
+	Conversation *conversation.Service
+	Journal      *journalapp.Service
+}
+
+// NewServices creates the application services over llm and stores: safety,
+// routing, pipelines, context window, tools and long-term memory, all
+// according to config.
+func NewServices(ctx context.Context, cfg *config.Config, llm domain.LLMClient, stores *Stores) (*Services, error) {
+	logger := observability.Logger()
+
+	// JournalTool from JournalStore (only if it exists)
+	var journalTool *tools.JournalTool
+	if stores.Journal != nil {
+		journalTool = tools.NewJournalTool(stores.Journal)
+		logger.Info("[JOURNAL] JournalTool enabled", "backend", cfg.StorageBackend)
+	} else {
+		logger.Info("[JOURNAL] JournalTool disabled (no JournalStore configured)")
+	}
+
+	safetyGate, err := NewSafetyGate(cfg, llm)
+	if err != nil {
+		return nil, fmt.Errorf("initializing safety gate: %w", err)
+	}
+
+	// Optional web search and knowledge base for the Planner
+	webSearch, err := NewWebSearchTool(cfg)
+	if err != nil {
+		return nil, err
+	}
+	kb, err := NewKnowledgeBase(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("initializing knowledge base %s: %w", cfg.KnowledgeDir, err)
+	}
+
+	// Agent pipelines per mode (validated at startup)
+	pipelines, err := NewPipelines(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("loading agent pipelines %s: %w", cfg.PipelinesFile, err)
+	}
+
+	journalSvc := journalapp.NewService(stores.Journal)
+	opts := []conversation.Option{
+		conversation.WithSafetyGate(safetyGate),
+		conversation.WithSafetyEventStore(stores.SafetyEvents),
+		conversation.WithModeRouter(NewModeRouter(cfg, llm)),
+		conversation.WithPipelines(pipelines),
+		conversation.WithContextBuilder(NewContextBuilder(cfg, llm)),
+	}
+	if stores.Journal != nil {
+		// Lets tool-calling models look up and update the user's actions
+		opts = append(opts, conversation.WithTools(tools.NewActionTracker(journalSvc)))
+	}
+	if stores.Journal != nil && cfg.LongTermMemory {
+		opts = append(opts, conversation.WithMemories(NewMemoryService(stores.Journal)))
+		logger.Info("[MEMORY] Long-term memory enabled")
+	}
+	if webSearch != nil {
+		opts = append(opts, conversation.WithTools(webSearch))
+	}
+	if kb != nil {
+		opts = append(opts,
+			conversation.WithKnowledgeBase(kb),
+			conversation.WithTools(tools.NewKnowledgeSearchTool(kb)),
+		)
+	}
+
+	return &Services{
+		Conversation: conversation.NewService(llm, stores.Sessions, stores.Messages, journalTool, opts...),
+		Journal:      journalSvc,
+	}, nil
+}
+
+// NewLLMClient creates the LLMClient according to config.
+// With FARUM_LLM_MODE=replay no real client is created: every reply comes
+// from the cassette. With FARUM_LLM_MODE=record the real client is wrapped.
+func NewLLMClient(ctx context.Context, cfg *config.Config) (domain.LLMClient, error) {
+	logger := observability.Logger()
+
+	switch llmadapter.CassetteMode(cfg.LLMMode) {
+	case llmadapter.CassetteReplay:
+		logger.Info("[LLM] Replaying LLM cassette", "cassette", cfg.LLMCassette)
+		client, err := llmadapter.NewReplayLLM(cfg.LLMCassette)
+		if err != nil {
+			return nil, fmt.Errorf("initializing replay LLM client: %w", err)
+		}
+		return client, nil
+
+	case llmadapter.CassetteRecord:
+		inner, err := newLiveLLMClient(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("[LLM] Recording LLM cassette", "cassette", cfg.LLMCassette)
+		client, err := llmadapter.NewRecordingLLM(inner, cfg.LLMCassette)
+		if err != nil {
+			return nil, fmt.Errorf("initializing recording LLM client: %w", err)
+		}
+		return client, nil
+	}
+
+	return newLiveLLMClient(ctx, cfg)
+}
+
+// newLiveLLMClient creates the real provider(s) behind a Router that retries,
+// fails over (FARUM_LLM_FALLBACKS) and routes per agent (FARUM_LLM_ROUTES).
+func newLiveLLMClient(ctx context.Context, cfg *config.Config) (domain.LLMClient, error) {
+	logger := observability.Logger()
+
+	if cfg.UseMockLLM {
+		logger.Info("[LLM] Using MOCK LLM client")
+		return llmadapter.NewMockLLM(), nil
+	}
+
+	// Primary first, then fallbacks, then providers only used by routes
+	specs := append([]string{cfg.LLMProvider}, cfg.LLMFallbacks...)
+	agents := make([]string, 0, len(cfg.LLMRoutes))
+	for agent := range cfg.LLMRoutes {
+		agents = append(agents, agent)
+	}
+	sort.Strings(agents)
+	for _, agent := range agents {
+		specs = append(specs, cfg.LLMRoutes[agent]...)
+	}
+
+	var providers []llmadapter.RouterProvider
+	seen := map[string]bool{}
+	for _, spec := range specs {
+		if seen[spec] {
+			continue
+		}
+		seen[spec] = true
+
+		client, err := newProviderClient(ctx, cfg, spec)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, llmadapter.RouterProvider{Name: spec, Client: client})
+	}
+
+	maxRetries := cfg.LLMMaxRetries
+	if maxRetries == 0 {
+		maxRetries = -1 // the router treats 0 as "default"
+	}
+
+	router, err := llmadapter.NewRouter(llmadapter.RouterConfig{
+		MaxRetries:       maxRetries,
+		BreakerThreshold: cfg.LLMBreakerThreshold,
+		BreakerCooldown:  cfg.LLMBreakerCooldown,
+		Routes:           cfg.LLMRoutes,
+	}, providers...)
+	if err != nil {
+		return nil, fmt.Errorf("initializing LLM router: %w", err)
+	}
+	return router, nil
+}
+
+// newProviderClient creates one provider client from a "provider[:model]" spec.
+// Without a model, the provider's configured model is used.
+func newProviderClient(ctx context.Context, cfg *config.Config, spec string) (domain.LLMClient, error) {
+	logger := observability.Logger()
+	provider, model, _ := strings.Cut(spec, ":")
+
+	switch provider {
+	case "anthropic":
+		if model == "" {
+			model = cfg.AnthropicModel
+		}
+		logger.Info("[LLM] Using Anthropic LLM client", "model", model)
+
+		client, err := llmadapter.NewAnthropicClient(llmadapter.AnthropicConfig{
+			BaseURL:     cfg.AnthropicBaseURL,
+			APIKey:      cfg.AnthropicAPIKey,
+			ModelName:   model,
+			Temperature: cfg.LLMTemperature,
+			MaxTokens:   cfg.LLMMaxTokens,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("initializing Anthropic LLM client: %w", err)
+		}
+		return client, nil
+
+	case "openai":
+		if model == "" {
+			model = cfg.OpenAIModel
+		}
+		logger.Info("[LLM] Using OpenAI-compatible LLM client",
+			"base_url", cfg.OpenAIBaseURL,
+			"model", model,
+		)
+
+		client, err := llmadapter.NewOpenAIClient(llmadapter.OpenAIConfig{
+			BaseURL:     cfg.OpenAIBaseURL,
+			APIKey:      cfg.OpenAIAPIKey,
+			ModelName:   model,
+			Temperature: cfg.LLMTemperature,
+			MaxTokens:   cfg.LLMMaxTokens,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("initializing OpenAI-compatible LLM client: %w", err)
+		}
+		return client, nil
+
+	case "vertex":
+		if model == "" {
+			model = cfg.ModelName
+		}
+		logger.Info("[LLM] Using Vertex LLM client",
+			"project", cfg.GCPProjectID,
+			"location", cfg.GCPLocation,
+			"model", model,
+		)
+
+		client, err := llmadapter.NewVertexClient(ctx, llmadapter.VertexConfig{
+			ProjectID: cfg.GCPProjectID,
+			Location:  cfg.GCPLocation,
+			ModelName: model,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("initializing Vertex LLM client: %w", err)
+		}
+		return client, nil
+
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q (want vertex, openai or anthropic)", provider)
+	}
+}
+
+// NewStores creates the stores according to config.StorageBackend ("firestore" or memory).
+func NewStores(ctx context.Context, cfg *config.Config) (*Stores, error) {
+	logger := observability.Logger()
+
+	switch cfg.StorageBackend {
+	case "firestore":
+		if cfg.GCPProjectID == "" {
+			return nil, fmt.Errorf("FARUM_GCP_PROJECT is required for Firestore storage backend")
+		}
+
+		logger.Info("[STORE] Using Firestore storage", "project", cfg.GCPProjectID)
+		fsStore, err := firestorestore.NewStore(ctx, cfg.GCPProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("initializing Firestore store: %w", err)
+		}
+
+		// 1 store, implements all the store interfaces
+		return &Stores{
+			Sessions:     fsStore,
+			Messages:     fsStore,
+			Journal:      fsStore,
+			SafetyEvents: fsStore,
+		}, nil
+
+	default:
+		logger.Info("[STORE] Using in-memory storage", "backend", "memory")
+		return &Stores{
+			Sessions:     memstore.NewSessionStore(),
+			Messages:     memstore.NewMessageStore(),
+			Journal:      memstore.NewJournalStore(),
+			SafetyEvents: memstore.NewSafetyEventStore(),
+		}, nil
+	}
+}
+
+// NewSafetyGate creates the safety gate: deterministic rules always,
+// LLM classifier optionally.
+func NewSafetyGate(cfg *config.Config, llm domain.LLMClient) (*safety.Gate, error) {
+	crisisResponses := safety.DefaultCrisisResponses
+	if cfg.SafetyResponsesFile != "" {
+		var err error
+		crisisResponses, err = safety.LoadCrisisResponses(cfg.SafetyResponsesFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	classifiers := []domain.SafetyClassifier{safety.NewRuleClassifier()}
+	if cfg.SafetyLLMClassifier {
+		classifiers = append(classifiers, safety.NewLLMClassifier(llm))
+	}
+	observability.Logger().Info("[SAFETY] Safety gate enabled", "llm_classifier", cfg.SafetyLLMClassifier)
+
+	return safety.NewGate(crisisResponses, classifiers...), nil
+}
+
+// NewModeRouter creates the mode router: heuristics always, LLM classifier
+// optionally.
+func NewModeRouter(cfg *config.Config, llm domain.LLMClient) *routing.ModeRouter {
+	classifiers := []domain.ModeClassifier{routing.NewHeuristicClassifier()}
+	if cfg.ModeLLMClassifier {
+		classifiers = append(classifiers, routing.NewLLMClassifier(llm))
+	}
+	observability.Logger().Info("[ROUTING] Mode router enabled", "llm_classifier", cfg.ModeLLMClassifier)
+
+	return routing.NewModeRouter(routing.RouterConfig{}, classifiers...)
+}
+
+// NewPipelines loads and validates config.PipelinesFile, or returns the
+// built-in pipelines when no file is configured.
+func NewPipelines(cfg *config.Config) (agentflow.PipelineConfig, error) {
+	if cfg.PipelinesFile == "" {
+		return agentflow.DefaultPipelineConfig(), nil
+	}
+
+	pipelines, err := agentflow.LoadPipelineConfig(cfg.PipelinesFile)
+	if err != nil {
+		return agentflow.PipelineConfig{}, err
+	}
+	observability.Logger().Info("[AGENTS] Pipelines loaded", "file", cfg.PipelinesFile)
+	return pipelines, nil
+}
+
+// NewContextBuilder creates the context window of the primary model:
+// config.ContextTokens, or the default budget of the model.
+func NewContextBuilder(cfg *config.Config, llm domain.LLMClient) *contextwindow.Builder {
+	budget := cfg.ContextTokens
+	if budget <= 0 && !cfg.UseMockLLM {
+		model := cfg.ModelName
+		switch cfg.LLMProvider {
+		case "openai":
+			model = cfg.OpenAIModel
+		case "anthropic":
+			model = cfg.AnthropicModel
+		}
+		budget = contextwindow.BudgetForModel(model)
+	}
+
+	builder := contextwindow.NewBuilder(contextwindow.HeuristicTokenizer{}, contextwindow.NewLLMSummarizer(llm), contextwindow.Config{
+		TokenBudget: budget,
+	})
+	observability.Logger().Info("[CONTEXT] Context window", "token_budget", builder.TokenBudget())
+	return builder
+}
+
+// NewWebSearchTool creates the web_search tool from config.SearchBackend,
+// or returns nil when it is disabled.
+func NewWebSearchTool(cfg *config.Config) (*tools.WebSearchTool, error) {
+	logger := observability.Logger()
+
+	var (
+		backend domain.SearchBackend
+		err     error
+	)
+	switch cfg.SearchBackend {
+	case "":
+		return nil, nil
+	case "searxng":
+		backend, err = search.NewSearXNGBackend(search.SearXNGConfig{BaseURL: cfg.SearchURL})
+	case "brave":
+		backend, err = search.NewBraveBackend(search.BraveConfig{BaseURL: cfg.SearchURL, APIKey: cfg.SearchAPIKey})
+	case "static":
+		backend, err = search.LoadStaticBackend(cfg.SearchCorpus)
+	default:
+		err = fmt.Errorf("unknown search backend %q", cfg.SearchBackend)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("initializing web search: %w", err)
+	}
+
+	logger.Info("[TOOLS] web_search enabled",
+		"backend", backend.Name(),
+		"allowed_domains", strings.Join(cfg.SearchAllowedDomains, ","),
+	)
+	return tools.NewWebSearchTool(backend, tools.WebSearchConfig{
+		AllowedDomains: cfg.SearchAllowedDomains,
+		MaxResults:     cfg.SearchMaxResults,
+	}), nil
+}
+
+// NewKnowledgeBase ingests config.KnowledgeDir into an in-memory index,
+// or returns nil when no directory is configured.
+func NewKnowledgeBase(ctx context.Context, cfg *config.Config) (*knowledge.Base, error) {
+	if cfg.KnowledgeDir == "" {
+		return nil, nil
+	}
+
+	kb := knowledge.NewBase(embedding.NewHashingEmbedder(0), memstore.NewVectorIndex())
+	n, err := kb.IngestDir(ctx, cfg.KnowledgeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	observability.Logger().Info("[KNOWLEDGE] Knowledge base enabled", "dir", cfg.KnowledgeDir, "chunks", n)
+	return kb, nil
+}
+
+// NewMemoryService creates the long-term memory service over the journal,
+// with the same local embedder as the knowledge base.
+func NewMemoryService(journal domain.JournalStore) *memories.Service {
+	return memories.NewService(journal, embedding.NewHashingEmbedder(0), memories.Config{})
+}
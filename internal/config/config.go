@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Mode string
@@ -17,12 +20,78 @@ type Config struct {
 
 	Port string
 
+	// AdminAddr is where the internal endpoints (GET /debug/vars) listen,
+	// apart from the public API; "" disables them.
+	AdminAddr string
+
 	GCPProjectID string
 	GCPLocation  string
 	ModelName    string
 
 	StorageBackend string // "memory" o "firestore"
 	UseMockLLM     bool   // true = use mock even on GCP
+
+	// Real LLM provider: "vertex", "openai" (any OpenAI-compatible server) or "anthropic".
+	// Temperature and max tokens apply to the HTTP providers; Vertex keeps its own defaults.
+	LLMProvider    string
+	LLMTemperature float64
+	LLMMaxTokens   int // 0 = provider default
+
+	OpenAIBaseURL string
+	OpenAIAPIKey  string
+	OpenAIModel   string
+
+	AnthropicBaseURL string
+	AnthropicAPIKey  string
+	AnthropicModel   string
+
+	// Provider router: fallbacks and per-agent routes use "provider[:model]"
+	// specs, e.g. FARUM_LLM_FALLBACKS="openai,vertex:gemini-2.5-pro" and
+	// FARUM_LLM_ROUTES="listener=vertex:gemini-2.5-flash-lite;planner=anthropic|vertex".
+	LLMFallbacks        []string
+	LLMRoutes           map[string][]string
+	LLMMaxRetries       int
+	LLMBreakerThreshold int
+	LLMBreakerCooldown  time.Duration
+
+	// LLM record/replay: "" (live), "record" or "replay"
+	LLMMode     string
+	LLMCassette string // cassette file used by record/replay
+
+	// web_search tool: "" (disabled), "searxng", "brave" or "static"
+	SearchBackend        string
+	SearchURL            string   // SearXNG instance or Brave-compatible API base URL
+	SearchAPIKey         string   // Brave subscription token
+	SearchCorpus         string   // JSON file with the documents of the static backend
+	SearchAllowedDomains []string // e.g. "who.int,nimh.nih.gov,argentina.gob.ar"
+	SearchMaxResults     int
+
+	// Long-term memory: recall past journal entries into the conversation context
+	LongTermMemory bool
+
+	// Knowledge base (RAG): directory of markdown documents, "" disables it
+	KnowledgeDir string
+
+	// Mode routing: also ask the LLM for the mode of each message (heuristics always run)
+	ModeLLMClassifier bool
+
+	// Agent pipelines per mode: JSON file, "" uses the built-in ones
+	PipelinesFile string
+
+	// Context window: tokens of history + summary, 0 = per model default
+	ContextTokens int
+
+	// Safety gate
+	SafetyLLMClassifier bool   // also ask the LLM to classify risk (rules always run)
+	SafetyResponsesFile string // JSON file {"es": "...", "en": "..."} with crisis responses
+
+	// HTTP API auth; none configured leaves the API open (user_id trusted)
+	AuthJWTSecret string            // HS256 shared secret
+	AuthJWKSURL   string            // RS256 keys, refreshed periodically
+	AuthJWKSFile  string            // RS256 keys read at startup
+	AuthIssuer    string            // expected "iss" of the tokens, "" skips the check
+	AuthAudience  string            // expected "aud" of the tokens, "" skips the check
+	APIKeys       map[string]string // static API key -> user, from FARUM_API_KEYS="key=user,key=user"
 }
 
 func getEnv(key, def string) string {
@@ -43,6 +112,81 @@ func getBoolEnv(key string, def bool) bool {
 	return false
 }
 
+func getFloatEnv(key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		log.Fatalf("%s must be a number, got %q", key, v)
+	}
+	return f
+}
+
+func getIntEnv(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("%s must be an integer, got %q", key, v)
+	}
+	return n
+}
+
+func getDurationEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("%s must be a duration (e.g. 30s), got %q", key, v)
+	}
+	return d
+}
+
+// splitList splits v by sep, trimming spaces and dropping empty items.
+func splitList(v, sep string) []string {
+	var out []string
+	for _, item := range strings.Split(v, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// parseRoutes parses "agent=spec|spec;agent=spec" into agent -> ordered specs.
+func parseRoutes(v string) map[string][]string {
+	routes := map[string][]string{}
+	for _, rule := range splitList(v, ";") {
+		agent, specs, ok := strings.Cut(rule, "=")
+		agent = strings.TrimSpace(agent)
+		if !ok || agent == "" || len(splitList(specs, "|")) == 0 {
+			log.Fatalf("FARUM_LLM_ROUTES: invalid rule %q (want agent=provider[:model]|...)", rule)
+		}
+		routes[agent] = splitList(specs, "|")
+	}
+	return routes
+}
+
+// parseAPIKeys parses "key=user,key=user" into key -> user.
+func parseAPIKeys(v string) map[string]string {
+	keys := map[string]string{}
+	for _, pair := range splitList(v, ",") {
+		key, user, ok := strings.Cut(pair, "=")
+		key, user = strings.TrimSpace(key), strings.TrimSpace(user)
+		if !ok || key == "" || user == "" {
+			log.Fatal("FARUM_API_KEYS: invalid entry (want key=user)")
+		}
+		keys[key] = user
+	}
+	return keys
+}
+
 // Load reads all env vars and builds the config
 func Load() *Config {
 	modeStr := getEnv("FARUM_MODE", "local")
@@ -57,7 +201,8 @@ func Load() *Config {
 	cfg := &Config{
 		Mode: mode,
 
-		Port: getEnv("FARUM_PORT", "8080"),
+		Port:      getEnv("FARUM_PORT", "8080"),
+		AdminAddr: getEnv("FARUM_ADMIN_ADDR", "127.0.0.1:9090"),
 
 		GCPProjectID: getEnv("FARUM_GCP_PROJECT", ""),
 		GCPLocation:  getEnv("FARUM_GCP_LOCATION", "us-central1"),
@@ -65,12 +210,78 @@ func Load() *Config {
 
 		StorageBackend: getEnv("FARUM_STORAGE_BACKEND", "memory"),
 		UseMockLLM:     getBoolEnv("FARUM_USE_MOCK_LLM", mode == ModeLocal),
+
+		LLMProvider:    getEnv("FARUM_LLM_PROVIDER", "vertex"),
+		LLMTemperature: getFloatEnv("FARUM_LLM_TEMPERATURE", 0.7),
+		LLMMaxTokens:   getIntEnv("FARUM_LLM_MAX_TOKENS", 0),
+
+		OpenAIBaseURL: getEnv("FARUM_OPENAI_BASE_URL", "https://api.openai.com/v1"),
+		OpenAIAPIKey:  getEnv("FARUM_OPENAI_API_KEY", ""),
+		OpenAIModel:   getEnv("FARUM_OPENAI_MODEL", "gpt-4o-mini"),
+
+		AnthropicBaseURL: getEnv("FARUM_ANTHROPIC_BASE_URL", "https://api.anthropic.com"),
+		AnthropicAPIKey:  getEnv("FARUM_ANTHROPIC_API_KEY", ""),
+		AnthropicModel:   getEnv("FARUM_ANTHROPIC_MODEL", "claude-sonnet-4-5"),
+
+		LLMFallbacks:        splitList(getEnv("FARUM_LLM_FALLBACKS", ""), ","),
+		LLMRoutes:           parseRoutes(getEnv("FARUM_LLM_ROUTES", "")),
+		LLMMaxRetries:       getIntEnv("FARUM_LLM_MAX_RETRIES", 2),
+		LLMBreakerThreshold: getIntEnv("FARUM_LLM_BREAKER_THRESHOLD", 5),
+		LLMBreakerCooldown:  getDurationEnv("FARUM_LLM_BREAKER_COOLDOWN", 30*time.Second),
+
+		LLMMode:     getEnv("FARUM_LLM_MODE", ""),
+		LLMCassette: getEnv("FARUM_LLM_CASSETTE", "testdata/cassettes/farum.json"),
+
+		SearchBackend:        getEnv("FARUM_SEARCH_BACKEND", ""),
+		SearchURL:            getEnv("FARUM_SEARCH_URL", ""),
+		SearchAPIKey:         getEnv("FARUM_SEARCH_API_KEY", ""),
+		SearchCorpus:         getEnv("FARUM_SEARCH_CORPUS", ""),
+		SearchAllowedDomains: splitList(getEnv("FARUM_SEARCH_ALLOWED_DOMAINS", ""), ","),
+		SearchMaxResults:     getIntEnv("FARUM_SEARCH_MAX_RESULTS", 3),
+
+		LongTermMemory: getBoolEnv("FARUM_LONG_TERM_MEMORY", true),
+
+		KnowledgeDir: getEnv("FARUM_KNOWLEDGE_DIR", ""),
+
+		ModeLLMClassifier: getBoolEnv("FARUM_MODE_LLM_CLASSIFIER", false),
+		PipelinesFile:     getEnv("FARUM_PIPELINES_FILE", ""),
+
+		ContextTokens: getIntEnv("FARUM_CONTEXT_TOKENS", 0),
+
+		SafetyLLMClassifier: getBoolEnv("FARUM_SAFETY_LLM_CLASSIFIER", false),
+		SafetyResponsesFile: getEnv("FARUM_SAFETY_RESPONSES_FILE", ""),
+
+		AuthJWTSecret: getEnv("FARUM_AUTH_JWT_SECRET", ""),
+		AuthJWKSURL:   getEnv("FARUM_AUTH_JWKS_URL", ""),
+		AuthJWKSFile:  getEnv("FARUM_AUTH_JWKS_FILE", ""),
+		AuthIssuer:    getEnv("FARUM_AUTH_ISSUER", ""),
+		AuthAudience:  getEnv("FARUM_AUTH_AUDIENCE", ""),
+		APIKeys:       parseAPIKeys(getEnv("FARUM_API_KEYS", "")),
+	}
+
+	if cfg.AdminAddr == "off" {
+		cfg.AdminAddr = ""
 	}
 
 	// Minimal validation in GCP mode
 	if cfg.Mode == ModeGCP && cfg.GCPProjectID == "" {
 		log.Fatal("FARUM_GCP_PROJECT must be set in gcp mode")
 	}
+	switch cfg.LLMProvider {
+	case "vertex", "openai", "anthropic":
+	default:
+		log.Fatalf("FARUM_LLM_PROVIDER must be vertex, openai or anthropic, got %q", cfg.LLMProvider)
+	}
+	switch cfg.SearchBackend {
+	case "", "searxng", "brave", "static":
+	default:
+		log.Fatalf("FARUM_SEARCH_BACKEND must be searxng, brave or static, got %q", cfg.SearchBackend)
+	}
+	switch cfg.LLMMode {
+	case "", "record", "replay":
+	default:
+		log.Fatalf("FARUM_LLM_MODE must be record or replay, got %q", cfg.LLMMode)
+	}
 
 	return cfg
 }
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrForbidden is returned when the authenticated user does not own what
+// they ask for.
+var ErrForbidden = errors.New("forbidden")
+
+type subjectKey struct{}
+
+// WithSubject stores the authenticated user in the context.
+func WithSubject(ctx context.Context, userID UserID) context.Context {
+	return context.WithValue(ctx, subjectKey{}, userID)
+}
+
+// SubjectFromContext returns the user stored by WithSubject; ok is false
+// when the caller was not authenticated (auth disabled, CLIs, tests).
+func SubjectFromContext(ctx context.Context) (UserID, bool) {
+	userID, ok := ctx.Value(subjectKey{}).(UserID)
+	return userID, ok && userID != ""
+}
+
+// CheckOwner returns ErrForbidden when ctx carries an authenticated user
+// other than owner. Unauthenticated callers are trusted.
+func CheckOwner(ctx context.Context, owner UserID) error {
+	if subject, ok := SubjectFromContext(ctx); ok && subject != owner {
+		return fmt.Errorf("%w: %s cannot access the data of %s", ErrForbidden, subject, owner)
+	}
+	return nil
+}
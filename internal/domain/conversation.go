@@ -1,5 +1,14 @@
 package domain
 
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// ErrSessionNotFound is returned by a SessionStore when the session does not exist.
+var ErrSessionNotFound = errors.New("session not found")
+
 // Message represents a any message in a timeline (user or agent)
 type Message struct {
 	ID        MessageID
@@ -15,6 +24,41 @@ type Message struct {
 	ContentType string // e.g., "text", "reflection", "task_list"
 }
 
+// TagTrace marks an agent output that is part of the trace of a turn, not
+// the reply the user got.
+const TagTrace = "trace"
+
+// AgentTag is the Message.Tags entry naming the agent that wrote a message.
+func AgentTag(agent string) string {
+	return "agent:" + agent
+}
+
+// Agent returns the agent that wrote m, or "" (user and welcome messages).
+func (m *Message) Agent() string {
+	for _, t := range m.Tags {
+		if name, ok := strings.CutPrefix(t, "agent:"); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// IsTrace reports whether m is an intermediate agent output.
+func (m *Message) IsTrace() bool {
+	return slices.Contains(m.Tags, TagTrace)
+}
+
+// WithoutTrace returns the messages the user sees, in the same order.
+func WithoutTrace(msgs []*Message) []*Message {
+	out := make([]*Message, 0, len(msgs))
+	for _, m := range msgs {
+		if !m.IsTrace() {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
 // Session represent a concrete "relationship" between a user and the agent (could last days)
 type Session struct {
 	ID        SessionID
@@ -25,4 +69,47 @@ type Session struct {
 	// Basic session's config
 	PreferredMode InteractionMode
 	Title         string
+
+	// Rolling summary of the turns that no longer fit in the context window,
+	// up to and including the message SummaryThrough.
+	Summary        string
+	SummaryThrough MessageID
+
+	Status   SessionStatus
+	ClosedAt Timestamp // zero while the session is active
+}
+
+// SessionStatus is where a session is in its lifecycle.
+type SessionStatus string
+
+const (
+	// SessionActive sessions take new messages.
+	SessionActive SessionStatus = "active"
+	// SessionClosed sessions are finished: journaled and summarized. They
+	// can be resumed.
+	SessionClosed SessionStatus = "closed"
+	// SessionArchived sessions are closed and left out of the session list.
+	SessionArchived SessionStatus = "archived"
+)
+
+// SessionStatuses lists every status.
+var SessionStatuses = []SessionStatus{SessionActive, SessionClosed, SessionArchived}
+
+// Valid reports whether s is a known status.
+func (s SessionStatus) Valid() bool {
+	return slices.Contains(SessionStatuses, s)
+}
+
+// CurrentStatus returns the status of s. Sessions stored before statuses
+// existed have none and are active.
+func (s *Session) CurrentStatus() SessionStatus {
+	if s.Status == "" {
+		return SessionActive
+	}
+	return s.Status
+}
+
+// IsActive reports whether the session takes new messages.
+func (s *Session) IsActive() bool {
+	return s.CurrentStatus() == SessionActive
 }
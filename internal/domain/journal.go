@@ -11,16 +11,17 @@ type ActionStatus string
 const (
 	ActionStatusPending ActionStatus = "pending"
 	ActionStatusDone    ActionStatus = "done"
+	ActionStatusSkipped ActionStatus = "skipped"
 )
 
 // JournalAction represents a concrete step within an action plan
 type JournalAction struct {
-	ID          string        `json:"id"`
-	Description string        `json:"description"`
-	Status      ActionStatus  `json:"status"`
-	Notes       string        `json:"notes,omitempty"`
-	CreatedAt   time.Time     `json:"created_at"`
-	UpdatedAt   time.Time     `json:"updated_at"`
+	ID          string       `json:"id"`
+	Description string       `json:"description"`
+	Status      ActionStatus `json:"status"`
+	Notes       string       `json:"notes,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
 }
 
 // JournalEntry represents the “long-term” summary of a session or set of sessions
@@ -49,5 +50,9 @@ type JournalEntry struct {
 // JournalStore defines the minimum operations to persist the journal
 type JournalStore interface {
 	AppendJournalEntry(entry *JournalEntry) error
+	GetJournalEntry(id JournalEntryID) (*JournalEntry, error)
 	ListJournalEntriesByUser(userID UserID, limit int) ([]*JournalEntry, error)
+
+	// UpdateJournalEntry replaces an existing entry (e.g. after changing the status of an action).
+	UpdateJournalEntry(entry *JournalEntry) error
 }
@@ -0,0 +1,47 @@
+package domain
+
+import "context"
+
+// KnowledgeChunk is a piece of a knowledge base document (a section or part of one).
+type KnowledgeChunk struct {
+	ID      string // "<source>#<n>", stable across ingestions of the same file
+	Source  string // path of the document, relative to the knowledge base root
+	Title   string // document title
+	Heading string // heading of the section the chunk belongs to
+	Text    string
+}
+
+// KnowledgeHit is a chunk returned by a search, with its similarity score.
+type KnowledgeHit struct {
+	Chunk KnowledgeChunk
+	Score float64
+}
+
+// Citation points to the knowledge base chunk an answer drew from.
+type Citation struct {
+	ChunkID string
+	Source  string
+	Title   string
+}
+
+// CitationTag is the Message.Tags entry recording a citation.
+func CitationTag(c Citation) string {
+	return "cite:" + c.ChunkID
+}
+
+// Embedder turns texts into vectors; similar texts get close vectors.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// VectorIndex stores chunk vectors and finds the nearest ones.
+type VectorIndex interface {
+	Upsert(chunks []KnowledgeChunk, vectors [][]float32) error
+	Search(vector []float32, k int) ([]KnowledgeHit, error)
+	Len() int
+}
+
+// KnowledgeRetriever finds the chunks most relevant to a query.
+type KnowledgeRetriever interface {
+	Search(ctx context.Context, query string, k int) ([]KnowledgeHit, error)
+}
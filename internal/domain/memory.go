@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// MemoryKind says where a long-term memory comes from.
+type MemoryKind string
+
+const (
+	MemoryJournal    MemoryKind = "journal"     // summary of a past session
+	MemoryOpenAction MemoryKind = "open_action" // a pending step of a past plan
+	MemoryMoodTrend  MemoryKind = "mood_trend"  // how the user's mood evolved
+)
+
+// Memory is something Farum remembers about the user from previous sessions.
+type Memory struct {
+	Kind      MemoryKind
+	Text      string    // ready to show to the model
+	SourceID  string    // journal entry (or action) it comes from
+	CreatedAt time.Time // when the remembered thing happened
+	Score     float64   // relevance for the current message (0-1)
+}
@@ -7,24 +7,66 @@ type LLMClient interface {
 	GenerateReply(ctx context.Context, prompt string, convCtx ConversationContext) (string, error)
 }
 
+// StreamingLLMClient is an optional capability of an LLMClient:
+// it delivers the reply in chunks while it is being generated.
+type StreamingLLMClient interface {
+	LLMClient
+
+	// GenerateReplyStream calls onChunk for every text chunk and returns the full reply.
+	// If onChunk returns an error, generation stops and that error is returned.
+	GenerateReplyStream(ctx context.Context, prompt string, convCtx ConversationContext, onChunk func(chunk string) error) (string, error)
+}
+
+// Tokenizer counts the tokens of a text for a model.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
 // ConversationContext gives the LLM minimal context about the conversation.
 type ConversationContext struct {
 	SessionID SessionID
 	UserID    UserID
 	Mode      InteractionMode
-	History   []*Message // for the MVP, last N interactions
+	History   []*Message // the recent messages that fit in the context window
+
+	// Summary of the earlier messages of the session, left out of History
+	Summary string
+
+	// Memories recalled from previous sessions, most important first,
+	// already cut to their token budget
+	Memories []Memory
 }
 
 // SessionStore defines session's persistence
 type SessionStore interface {
 	CreateSession(session *Session) error
 	UpdateSession(session *Session) error
-	GetSession(id SessionID) (*Session, error)
-	ListSessionsByUser(userID UserID, limit int) ([]*Session, error)
+
+	// TouchSession writes only UpdatedAt, Summary and SummaryThrough, so a
+	// turn that ends after the session was closed does not reopen it.
+	TouchSession(session *Session) error
+
+	GetSession(id SessionID) (*Session, error) // ErrSessionNotFound if missing
+
+	// ListSessionsByUser returns the sessions of the user selected by q,
+	// newest first.
+	ListSessionsByUser(userID UserID, q SessionQuery) ([]*Session, error)
+}
+
+// SessionQuery selects a page of the sessions of a user.
+type SessionQuery struct {
+	Statuses []SessionStatus // empty: any status
+	Limit    int             // <= 0: no limit
+	Offset   int
 }
 
 // MessageStore defines message's persistence
 type MessageStore interface {
 	AppendMessage(msg *Message) error
 	GetMessagesBySession(sessionID SessionID, limit int) ([]*Message, error)
+
+	// GetMessagesAfter returns the messages of the session created after the
+	// message with ID after, oldest first; all of them if after is "" or
+	// unknown.
+	GetMessagesAfter(sessionID SessionID, after MessageID) ([]*Message, error)
 }
@@ -0,0 +1,39 @@
+package domain
+
+import "context"
+
+// InteractionModes lists the modes in a stable order (ties are broken in this order).
+var InteractionModes = []InteractionMode{ModeCheckIn, ModeDeepDive, ModeActionPlan}
+
+// Valid reports whether m is one of InteractionModes.
+func (m InteractionMode) Valid() bool {
+	for _, known := range InteractionModes {
+		if m == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ModeScores is how likely each interaction mode is for a message (0..1).
+type ModeScores map[InteractionMode]float64
+
+// ModeClassifier scores the interaction modes for a message, given the
+// recent history in convCtx.
+type ModeClassifier interface {
+	Classify(ctx context.Context, text string, convCtx ConversationContext) (ModeScores, error)
+}
+
+// ModeDecision is the mode the router picked for a message.
+type ModeDecision struct {
+	Mode     InteractionMode
+	Previous InteractionMode // mode of the session before this message
+	Scores   ModeScores      // merged scores, preferred mode bias included
+	Source   string          // "heuristics", "llm" or a combination
+	Reason   string          // e.g. "switched", "kept (hysteresis)"
+}
+
+// Changed reports whether the message switched the session to another mode.
+func (d ModeDecision) Changed() bool {
+	return d.Previous != "" && d.Mode != d.Previous
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// RiskLevel is the risk detected in a user message by the safety gate.
+type RiskLevel string
+
+const (
+	RiskNone   RiskLevel = "none"
+	RiskLow    RiskLevel = "low"
+	RiskMedium RiskLevel = "medium"
+	RiskHigh   RiskLevel = "high"
+)
+
+// Severity returns a comparable value for the level (none=0 ... high=3).
+func (r RiskLevel) Severity() int {
+	switch r {
+	case RiskLow:
+		return 1
+	case RiskMedium:
+		return 2
+	case RiskHigh:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// SafetyAssessment is the result of classifying a message.
+type SafetyAssessment struct {
+	Level      RiskLevel
+	Categories []string // e.g. "suicide", "self_harm", "harm_others", "distress"
+	Matches    []string // rules or reasons that triggered the assessment
+	Language   string   // detected language ("es", "en", "pt"), may be empty
+	Source     string   // "rules", "llm" or a combination
+}
+
+// SafetyClassifier detects risk (self-harm, suicide, harm to others) in a message.
+type SafetyClassifier interface {
+	Classify(ctx context.Context, text string, convCtx ConversationContext) (SafetyAssessment, error)
+}
+
+// SafetyEventID identifies a safety event
+type SafetyEventID string
+
+// SafetyEvent records a risky message so it can be reviewed later.
+type SafetyEvent struct {
+	ID        SafetyEventID `json:"id"`
+	SessionID SessionID     `json:"session_id"`
+	UserID    UserID        `json:"user_id"`
+	MessageID MessageID     `json:"message_id"`
+	CreatedAt time.Time     `json:"created_at"`
+
+	Level      RiskLevel `json:"level"`
+	Categories []string  `json:"categories"`
+	Matches    []string  `json:"matches"`
+	Language   string    `json:"language"`
+	Source     string    `json:"source"`
+
+	// ShortCircuited is true when the agents were skipped and the crisis response was sent.
+	ShortCircuited bool `json:"short_circuited"`
+}
+
+// SafetyEventStore persists safety events for review.
+type SafetyEventStore interface {
+	AppendSafetyEvent(ev *SafetyEvent) error
+	ListSafetyEvents(limit int) ([]*SafetyEvent, error)
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+// SearchResult is one hit returned by a SearchBackend.
+type SearchResult struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet"`
+}
+
+// SearchBackend finds web pages (or documents of a local corpus) for a query.
+type SearchBackend interface {
+	Name() string
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
+}
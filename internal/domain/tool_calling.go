@@ -0,0 +1,74 @@
+package domain
+
+import "context"
+
+// ToolSpec declares a tool the model may call.
+type ToolSpec struct {
+	Name        string
+	Description string
+	Parameters  map[string]any // JSON schema of the input object
+}
+
+// ToolCall is a request from the model to run a tool.
+type ToolCall struct {
+	ID   string // provider call ID, echoed back in the ToolResult
+	Name string
+	Args map[string]any
+}
+
+// ToolResult is the outcome of a ToolCall, sent back to the model.
+type ToolResult struct {
+	CallID  string
+	Name    string
+	Content string // usually JSON
+	IsError bool
+}
+
+// ToolExchange is one round of tool use: what the model asked for and what it got back.
+type ToolExchange struct {
+	Text    string // text the model produced alongside the calls, if any
+	Calls   []ToolCall
+	Results []ToolResult
+}
+
+// ToolTurn is one model answer: final text, or tool calls to run first.
+type ToolTurn struct {
+	Text  string
+	Calls []ToolCall
+}
+
+// ToolCallingLLMClient is an optional capability of an LLMClient:
+// the model can answer with tool calls instead of (or besides) text.
+type ToolCallingLLMClient interface {
+	LLMClient
+
+	// GenerateWithTools sends the prompt, the declared tools and the previous
+	// exchanges of this turn. When the returned ToolTurn has no Calls, Text
+	// is the final answer.
+	GenerateWithTools(
+		ctx context.Context,
+		prompt string,
+		convCtx ConversationContext,
+		tools []ToolSpec,
+		exchanges []ToolExchange,
+	) (ToolTurn, error)
+}
+
+// ToolSupport is an optional capability of a ToolCallingLLMClient that wraps
+// other clients (a router, a recorder): whether a call can use tools depends
+// on what is behind it, e.g. the providers routed to the calling agent.
+type ToolSupport interface {
+	SupportsTools(ctx context.Context) bool
+}
+
+// SupportsTools reports whether a GenerateWithTools call made with ctx can be
+// served by client.
+func SupportsTools(ctx context.Context, client LLMClient) bool {
+	if _, ok := client.(ToolCallingLLMClient); !ok {
+		return false
+	}
+	if s, ok := client.(ToolSupport); ok {
+		return s.SupportsTools(ctx)
+	}
+	return true
+}
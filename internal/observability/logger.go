@@ -10,6 +10,7 @@ type ctxKey string
 
 const (
 	ctxKeyRequestID ctxKey = "request_id"
+	ctxKeyAgent     ctxKey = "agent"
 )
 
 // basic global logger, JSON to stdout.
@@ -19,6 +20,11 @@ func Logger() *slog.Logger {
 	return logger
 }
 
+// SetLogger replaces the global logger (e.g. CLIs that keep stdout for their output).
+func SetLogger(l *slog.Logger) {
+	logger = l
+}
+
 // WithFields returns a logger with additional fields.
 func WithFields(kv ...any) *slog.Logger {
 	return logger.With(kv...)
@@ -29,6 +35,24 @@ func WithRequestID(ctx context.Context, requestID string) context.Context {
 	return context.WithValue(ctx, ctxKeyRequestID, requestID)
 }
 
+// RequestIDFromContext returns the request_id stored by WithRequestID, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	reqID, _ := ctx.Value(ctxKeyRequestID).(string)
+	return reqID
+}
+
+// WithAgent stores the name of the agent making the calls in the context
+// (used to route and label LLM calls).
+func WithAgent(ctx context.Context, agent string) context.Context {
+	return context.WithValue(ctx, ctxKeyAgent, agent)
+}
+
+// AgentFromContext returns the agent stored by WithAgent, or "".
+func AgentFromContext(ctx context.Context) string {
+	agent, _ := ctx.Value(ctxKeyAgent).(string)
+	return agent
+}
+
 // LoggerFromContext adds request_id if present.
 func LoggerFromContext(ctx context.Context) *slog.Logger {
 	reqID, _ := ctx.Value(ctxKeyRequestID).(string)
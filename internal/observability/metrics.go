@@ -1 +1,67 @@
-package observability
\ No newline at end of file
+package observability
+
+import (
+	"expvar"
+	"time"
+)
+
+// llmMetrics holds the LLM call counters, published with expvar
+// (GET /debug/vars) under "llm".
+var llmMetrics = expvar.NewMap("llm")
+
+// LLM call outcomes recorded by RecordLLMCall.
+const (
+	LLMOutcomeOK          = "ok"
+	LLMOutcomeError       = "error"
+	LLMOutcomeRetry       = "retry"
+	LLMOutcomeCircuitOpen = "circuit_open"
+)
+
+// RecordLLMCall counts one LLM call attempt by provider, outcome and agent
+// (if known), and accumulates the latency of the calls that succeeded.
+func RecordLLMCall(provider, agent, outcome string, latency time.Duration) {
+	llmMetrics.Add("calls."+provider+"."+outcome, 1)
+	if agent != "" {
+		llmMetrics.Add("agent."+agent+"."+provider+"."+outcome, 1)
+	}
+	if outcome == LLMOutcomeOK {
+		llmMetrics.Add("latency_ms."+provider, latency.Milliseconds())
+	}
+}
+
+// LLMCallCount returns how many calls were recorded for provider and outcome.
+func LLMCallCount(provider, outcome string) int64 {
+	v, ok := llmMetrics.Get("calls." + provider + "." + outcome).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return v.Value()
+}
+
+// toolMetrics holds the tool call counters, published under "tools".
+var toolMetrics = expvar.NewMap("tools")
+
+// Tool call outcomes recorded by RecordToolCall.
+const (
+	ToolOutcomeOK            = "ok"
+	ToolOutcomeError         = "error"
+	ToolOutcomeInvalidInput  = "invalid_input"
+	ToolOutcomeInvalidOutput = "invalid_output"
+	ToolOutcomeUnknown       = "unknown_tool"
+)
+
+// RecordToolCall counts one tool invocation by tool and outcome and
+// accumulates its latency.
+func RecordToolCall(tool, outcome string, latency time.Duration) {
+	toolMetrics.Add("calls."+tool+"."+outcome, 1)
+	toolMetrics.Add("latency_ms."+tool, latency.Milliseconds())
+}
+
+// ToolCallCount returns how many calls were recorded for tool and outcome.
+func ToolCallCount(tool, outcome string) int64 {
+	v, ok := toolMetrics.Get("calls." + tool + "." + outcome).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return v.Value()
+}